@@ -0,0 +1,46 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// Keys of the connection secret of an AccessKey. They match the keys of
+// Secrets of type kubernetes.io/ssh-auth.
+const (
+	// ConnectionPrivateKeyKey is the private key of a key pair generated by
+	// the provider. It is only set when the spec has no public key.
+	ConnectionPrivateKeyKey = "ssh-privatekey"
+
+	// ConnectionPublicKeyKey is the public key granted access to the
+	// repository.
+	ConnectionPublicKeyKey = "ssh-publickey"
+)
+
+// Keys of the metadata of an AccessKey which are written next to the public
+// key to the Secret of writePublicKeySecretToRef.
+const (
+	// PublicKeyLabelKey is the label of the access key.
+	PublicKeyLabelKey = "label"
+
+	// PublicKeyPermissionKey is the permission of the access key.
+	PublicKeyPermissionKey = "permission"
+
+	// PublicKeyRepositoryKey is the repository as PROJECT/repo-slug.
+	PublicKeyRepositoryKey = "repository"
+
+	// PublicKeyCloneURLKey is the URL to clone the repository over SSH, once
+	// it is observed.
+	PublicKeyCloneURLKey = "clone-url"
+)
@@ -0,0 +1,32 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+var _ admission.Defaulter = &AccessKey{}
+
+// Default sets the permission of the access key to REPO_READ if unset
+func (a *AccessKey) Default() {
+	if a.Spec.ForProvider.PublicKey.Permission == "" {
+		a.Spec.ForProvider.PublicKey.Permission = bitbucket.PermissionRepoRead
+	}
+}
@@ -19,7 +19,8 @@ package v1alpha1
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clients/bitbucket"
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 )
 
@@ -35,6 +36,14 @@ type AccessKeyParameters struct {
 	// +immutable
 	RepoName string `json:"repoName"`
 
+	// The repoSlug is the slug of the git repository used in the URLs of
+	// the Bitbucket API. Defaults to the repoName in lower case with
+	// spaces and other special characters replaced by hyphens. Set it for
+	// repositories which were renamed after they were created.
+	// +optional
+	// +immutable
+	RepoSlug string `json:"repoSlug,omitempty"`
+
 	PublicKey PublicKey `json:"publicKey"`
 }
 
@@ -53,8 +62,11 @@ type PublicKey struct {
 	// +kubebuilder:validation:Pattern=((ssh|ecdsa)-[a-z0-9-]+ .*|)
 	Key string `json:"key,omitempty"`
 
+	// Permission of the key. Defaults to REPO_READ.
+	// +optional
 	// +kubebuilder:validation:Enum=REPO_READ;REPO_WRITE
-	Permission string `json:"permission"`
+	// +kubebuilder:default=REPO_READ
+	Permission string `json:"permission,omitempty"`
 }
 
 // AccessKeyObservation are the observable fields of an AccessKey.
@@ -63,12 +75,48 @@ type AccessKeyObservation struct {
 	ID int `json:"id,omitempty"`
 	// +kubebuilder:validation:Optional
 	Key *PublicKey `json:"publicKey,omitempty"`
+
+	// Repository the access key grants access to.
+	// +optional
+	Repository *RepositoryObservation `json:"repository,omitempty"`
+}
+
+// RepositoryObservation are the observable fields of the repository of an
+// AccessKey.
+type RepositoryObservation struct {
+	// ID of the repository in Bitbucket.
+	ID int `json:"id"`
+
+	// Slug of the repository, which is used in its URLs.
+	// +optional
+	Slug string `json:"slug,omitempty"`
+
+	// HTTPCloneURL is the URL to clone the repository over HTTP(S).
+	// +optional
+	HTTPCloneURL string `json:"httpCloneURL,omitempty"`
+
+	// SSHCloneURL is the URL to clone the repository over SSH.
+	// +optional
+	SSHCloneURL string `json:"sshCloneURL,omitempty"`
 }
 
 // An AccessKeySpec defines the desired state of an AccessKey.
 type AccessKeySpec struct {
 	xpv1.ResourceSpec `json:",inline"`
 	ForProvider       AccessKeyParameters `json:"forProvider"`
+
+	// WritePublicKeySecretToRef specifies the namespace and name of a
+	// Secret to which the public key and the metadata of the access key are
+	// written. The connection secret then only holds the private key, so
+	// that the two can be shared with different audiences.
+	// +optional
+	WritePublicKeySecretToRef *xpv1.SecretReference `json:"writePublicKeySecretToRef,omitempty"`
+
+	// ManagementPolicies are the actions the provider may perform on the
+	// external resource, all of them by default. Use ["Observe"] to import
+	// and watch an existing resource without ever changing it.
+	// +optional
+	ManagementPolicies apisv1alpha1.ManagementPolicies `json:"managementPolicies,omitempty"`
 }
 
 // An AccessKeyStatus represents the observed state of an AccessKey.
@@ -81,10 +129,11 @@ type AccessKeyStatus struct {
 
 // An AccessKey is an SSH key with read or write access to a bitbucket git repo.
 // +kubebuilder:subresource:status
-// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.forProvider.projectKey"
-// +kubebuilder:printcolumn:name="REPO-NAME",type="string",JSONPath=".spec.forProvider.repoName"
-// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.forProvider.projectKey"
+// +kubebuilder:printcolumn:name="REPO",type="string",JSONPath=".spec.forProvider.repoName"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:resource:scope=Cluster
 type AccessKey struct {
@@ -99,11 +148,20 @@ type AccessKey struct {
 // TODO: Move
 func (a AccessKey) Repo() bitbucket.Repo {
 	return bitbucket.Repo{
-		ProjectKey: a.Spec.ForProvider.ProjectKey,
-		Repo:       a.Spec.ForProvider.RepoName,
+		ProjectKey: bitbucket.ProjectKey(a.Spec.ForProvider.ProjectKey),
+		Repo:       a.RepoSlug(),
 	}
 }
 
+// RepoSlug returns the slug of the repository, derived from its name unless
+// it is set explicitly
+func (a AccessKey) RepoSlug() string {
+	if a.Spec.ForProvider.RepoSlug != "" {
+		return a.Spec.ForProvider.RepoSlug
+	}
+	return bitbucket.RepoSlug(a.Spec.ForProvider.RepoName)
+}
+
 // AccessKey defined the bitbucket server api
 // TODO: move
 func (a AccessKey) AccessKey() bitbucket.AccessKey {
@@ -114,6 +172,12 @@ func (a AccessKey) AccessKey() bitbucket.AccessKey {
 	}
 }
 
+// GetManagementPolicies returns the actions the provider may perform on the
+// external resource
+func (a AccessKey) GetManagementPolicies() apisv1alpha1.ManagementPolicies {
+	return a.Spec.ManagementPolicies
+}
+
 // +kubebuilder:object:root=true
 
 // AccessKeyList contains a list of AccessKey
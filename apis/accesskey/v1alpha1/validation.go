@@ -0,0 +1,79 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const errNotAccessKey = "object is not an AccessKey"
+
+var _ admission.Validator = &AccessKey{}
+
+// ValidateCreate rejects a public key secret which is the connection secret.
+func (a *AccessKey) ValidateCreate() error {
+	if errs := a.validateSecrets(); len(errs) > 0 {
+		return kerrors.NewInvalid(schema.GroupKind{Group: Group, Kind: AccessKeyKind}, a.GetName(), errs)
+	}
+	return nil
+}
+
+// validateSecrets rejects a public key secret which is the connection
+// secret, which would get the private key, too.
+func (a *AccessKey) validateSecrets() field.ErrorList {
+	pub, conn := a.Spec.WritePublicKeySecretToRef, a.Spec.WriteConnectionSecretToReference
+	if pub == nil || conn == nil || pub.Namespace != conn.Namespace || pub.Name != conn.Name {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(field.NewPath("spec", "writePublicKeySecretToRef"), *pub, "must not be the connection secret")}
+}
+
+// ValidateUpdate rejects changes of the immutable fields of the access key.
+// The key may still be set once, as it is generated or late initialized
+// when left empty.
+func (a *AccessKey) ValidateUpdate(old runtime.Object) error {
+	o, ok := old.(*AccessKey)
+	if !ok {
+		return errors.New(errNotAccessKey)
+	}
+
+	fp := field.NewPath("spec", "forProvider")
+	var errs field.ErrorList
+	errs = append(errs, apivalidation.ValidateImmutableField(a.Spec.ForProvider.ProjectKey, o.Spec.ForProvider.ProjectKey, fp.Child("projectKey"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(a.Spec.ForProvider.RepoName, o.Spec.ForProvider.RepoName, fp.Child("repoName"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(a.Spec.ForProvider.RepoSlug, o.Spec.ForProvider.RepoSlug, fp.Child("repoSlug"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(a.Spec.ForProvider.PublicKey.Label, o.Spec.ForProvider.PublicKey.Label, fp.Child("publicKey", "label"))...)
+	if o.Spec.ForProvider.PublicKey.Key != "" {
+		errs = append(errs, apivalidation.ValidateImmutableField(a.Spec.ForProvider.PublicKey.Key, o.Spec.ForProvider.PublicKey.Key, fp.Child("publicKey", "key"))...)
+	}
+	errs = append(errs, a.validateSecrets()...)
+	if len(errs) == 0 {
+		return nil
+	}
+	return kerrors.NewInvalid(schema.GroupKind{Group: Group, Kind: AccessKeyKind}, a.GetName(), errs)
+}
+
+// ValidateDelete implements admission.Validator
+func (a *AccessKey) ValidateDelete() error {
+	return nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func accessKey(projectKey, key, permission string) *AccessKey {
+	a := &AccessKey{}
+	a.SetName("cool-key")
+	a.Spec.ForProvider = AccessKeyParameters{
+		ProjectKey: projectKey,
+		RepoName:   "repo",
+		PublicKey: PublicKey{
+			Label:      "label",
+			Key:        key,
+			Permission: permission,
+		},
+	}
+	return a
+}
+
+func TestValidateUpdate(t *testing.T) {
+	gk := schema.GroupKind{Group: Group, Kind: AccessKeyKind}
+	fp := field.NewPath("spec", "forProvider")
+
+	cases := map[string]struct {
+		old  *AccessKey
+		new  *AccessKey
+		want error
+	}{
+		"PermissionChanged": {
+			old: accessKey("PROJ", "ssh-ed25519 AAA", "REPO_READ"),
+			new: accessKey("PROJ", "ssh-ed25519 AAA", "REPO_WRITE"),
+		},
+		"KeySet": {
+			old: accessKey("PROJ", "", "REPO_READ"),
+			new: accessKey("PROJ", "ssh-ed25519 AAA", "REPO_READ"),
+		},
+		"KeyChanged": {
+			old: accessKey("PROJ", "ssh-ed25519 AAA", "REPO_READ"),
+			new: accessKey("PROJ", "ssh-ed25519 BBB", "REPO_READ"),
+			want: kerrors.NewInvalid(gk, "cool-key", field.ErrorList{
+				field.Invalid(fp.Child("publicKey", "key"), "ssh-ed25519 BBB", "field is immutable"),
+			}),
+		},
+		"ProjectKeyChanged": {
+			old: accessKey("PROJ", "ssh-ed25519 AAA", "REPO_READ"),
+			new: accessKey("OTHER", "ssh-ed25519 AAA", "REPO_READ"),
+			want: kerrors.NewInvalid(gk, "cool-key", field.ErrorList{
+				field.Invalid(fp.Child("projectKey"), "OTHER", "field is immutable"),
+			}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.new.ValidateUpdate(tc.old)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ValidateUpdate(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateCreate(t *testing.T) {
+	gk := schema.GroupKind{Group: Group, Kind: AccessKeyKind}
+	secrets := func(conn, pub *xpv1.SecretReference) *AccessKey {
+		a := accessKey("PROJ", "", "REPO_READ")
+		a.Spec.WriteConnectionSecretToReference = conn
+		a.Spec.WritePublicKeySecretToRef = pub
+		return a
+	}
+
+	cases := map[string]struct {
+		new  *AccessKey
+		want error
+	}{
+		"NoPublicKeySecret": {
+			new: secrets(&xpv1.SecretReference{Namespace: "ns", Name: "key"}, nil),
+		},
+		"SeparateSecrets": {
+			new: secrets(&xpv1.SecretReference{Namespace: "ns", Name: "key"}, &xpv1.SecretReference{Namespace: "ns", Name: "key-pub"}),
+		},
+		"SameSecret": {
+			new: secrets(&xpv1.SecretReference{Namespace: "ns", Name: "key"}, &xpv1.SecretReference{Namespace: "ns", Name: "key"}),
+			want: kerrors.NewInvalid(gk, "cool-key", field.ErrorList{
+				field.Invalid(field.NewPath("spec", "writePublicKeySecretToRef"), xpv1.SecretReference{Namespace: "ns", Name: "key"}, "must not be the connection secret"),
+			}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.new.ValidateCreate()
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ValidateCreate(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
@@ -22,6 +22,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/apis/common/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -92,6 +94,11 @@ func (in *AccessKeyObservation) DeepCopyInto(out *AccessKeyObservation) {
 		*out = new(PublicKey)
 		**out = **in
 	}
+	if in.Repository != nil {
+		in, out := &in.Repository, &out.Repository
+		*out = new(RepositoryObservation)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessKeyObservation.
@@ -125,6 +132,16 @@ func (in *AccessKeySpec) DeepCopyInto(out *AccessKeySpec) {
 	*out = *in
 	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
 	out.ForProvider = in.ForProvider
+	if in.WritePublicKeySecretToRef != nil {
+		in, out := &in.WritePublicKeySecretToRef, &out.WritePublicKeySecretToRef
+		*out = new(v1.SecretReference)
+		**out = **in
+	}
+	if in.ManagementPolicies != nil {
+		in, out := &in.ManagementPolicies, &out.ManagementPolicies
+		*out = make(apisv1alpha1.ManagementPolicies, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessKeySpec.
@@ -168,3 +185,18 @@ func (in *PublicKey) DeepCopy() *PublicKey {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryObservation) DeepCopyInto(out *RepositoryObservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryObservation.
+func (in *RepositoryObservation) DeepCopy() *RepositoryObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryObservation)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -21,6 +21,13 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	accesskeyv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/accesskey/v1alpha1"
+	defaultpermissionv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/defaultpermission/v1alpha1"
+	inventoryv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/inventory/v1alpha1"
+	licenseinfov1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/licenseinfo/v1alpha1"
+	loggerv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/logger/v1alpha1"
+	mergestrategyv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/mergestrategy/v1alpha1"
+	projecthookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/projecthook/v1alpha1"
+	serverinfov1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/serverinfo/v1alpha1"
 	bitbucketv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
 	webhookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
 )
@@ -30,6 +37,13 @@ func init() {
 	AddToSchemes = append(AddToSchemes,
 		bitbucketv1alpha1.SchemeBuilder.AddToScheme,
 		accesskeyv1alpha1.SchemeBuilder.AddToScheme,
+		defaultpermissionv1alpha1.SchemeBuilder.AddToScheme,
+		inventoryv1alpha1.SchemeBuilder.AddToScheme,
+		licenseinfov1alpha1.SchemeBuilder.AddToScheme,
+		loggerv1alpha1.SchemeBuilder.AddToScheme,
+		mergestrategyv1alpha1.SchemeBuilder.AddToScheme,
+		projecthookv1alpha1.SchemeBuilder.AddToScheme,
+		serverinfov1alpha1.SchemeBuilder.AddToScheme,
 		webhookv1alpha1.SchemeBuilder.AddToScheme,
 	)
 }
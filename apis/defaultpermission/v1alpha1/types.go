@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// DefaultPermissionParameters are the configurable fields of a
+// DefaultPermission.
+type DefaultPermissionParameters struct {
+	// The project key is the short name for the project. Typically the key
+	// for a project called "Foo Bar" would be "FB".
+	// +immutable
+	ProjectKey string `json:"projectKey"`
+
+	// Permission all licensed users get on the project. Defaults to
+	// PROJECT_READ.
+	// +optional
+	// +kubebuilder:validation:Enum=PROJECT_READ;PROJECT_WRITE
+	// +kubebuilder:default=PROJECT_READ
+	Permission string `json:"permission,omitempty"`
+}
+
+// DefaultPermissionObservation are the observable fields of a
+// DefaultPermission.
+type DefaultPermissionObservation struct {
+	// Permission all licensed users have on the project, empty if none.
+	// +optional
+	Permission string `json:"permission,omitempty"`
+}
+
+// A DefaultPermissionSpec defines the desired state of a DefaultPermission.
+type DefaultPermissionSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       DefaultPermissionParameters `json:"forProvider"`
+
+	// ManagementPolicies are the actions the provider may perform on the
+	// external resource, all of them by default. Use ["Observe"] to import
+	// and watch an existing resource without ever changing it.
+	// +optional
+	ManagementPolicies apisv1alpha1.ManagementPolicies `json:"managementPolicies,omitempty"`
+}
+
+// A DefaultPermissionStatus represents the observed state of a
+// DefaultPermission.
+type DefaultPermissionStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          DefaultPermissionObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A DefaultPermission grants all licensed users read or write access to a
+// project. Deleting it revokes the access again.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.forProvider.projectKey"
+// +kubebuilder:printcolumn:name="PERMISSION",type="string",JSONPath=".status.atProvider.permission"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster
+type DefaultPermission struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DefaultPermissionSpec   `json:"spec"`
+	Status DefaultPermissionStatus `json:"status,omitempty"`
+}
+
+// ProjectKey returns the key of the project as Bitbucket stores it
+func (a DefaultPermission) ProjectKey() string {
+	return bitbucket.ProjectKey(a.Spec.ForProvider.ProjectKey)
+}
+
+// Permission returns the permission to grant, which defaults to
+// PermissionProjectRead
+func (a DefaultPermission) Permission() string {
+	if a.Spec.ForProvider.Permission != "" {
+		return a.Spec.ForProvider.Permission
+	}
+	return bitbucket.PermissionProjectRead
+}
+
+// GetManagementPolicies returns the actions the provider may perform on the
+// external resource
+func (a DefaultPermission) GetManagementPolicies() apisv1alpha1.ManagementPolicies {
+	return a.Spec.ManagementPolicies
+}
+
+// +kubebuilder:object:root=true
+
+// DefaultPermissionList contains a list of DefaultPermission
+type DefaultPermissionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DefaultPermission `json:"items"`
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const errNotDefaultPermission = "object is not a DefaultPermission"
+
+var _ admission.Validator = &DefaultPermission{}
+
+// ValidateCreate implements admission.Validator
+func (a *DefaultPermission) ValidateCreate() error {
+	return nil
+}
+
+// ValidateUpdate rejects changes of the project of the default permission
+func (a *DefaultPermission) ValidateUpdate(old runtime.Object) error {
+	o, ok := old.(*DefaultPermission)
+	if !ok {
+		return errors.New(errNotDefaultPermission)
+	}
+
+	fp := field.NewPath("spec", "forProvider")
+	errs := apivalidation.ValidateImmutableField(a.Spec.ForProvider.ProjectKey, o.Spec.ForProvider.ProjectKey, fp.Child("projectKey"))
+	if len(errs) == 0 {
+		return nil
+	}
+	return kerrors.NewInvalid(schema.GroupKind{Group: Group, Kind: DefaultPermissionKind}, a.GetName(), errs)
+}
+
+// ValidateDelete implements admission.Validator
+func (a *DefaultPermission) ValidateDelete() error {
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func defaultPermission(projectKey, permission string) *DefaultPermission {
+	a := &DefaultPermission{}
+	a.SetName("open-read")
+	a.Spec.ForProvider = DefaultPermissionParameters{
+		ProjectKey: projectKey,
+		Permission: permission,
+	}
+	return a
+}
+
+func TestValidateUpdate(t *testing.T) {
+	gk := schema.GroupKind{Group: Group, Kind: DefaultPermissionKind}
+	fp := field.NewPath("spec", "forProvider")
+
+	cases := map[string]struct {
+		old  *DefaultPermission
+		new  *DefaultPermission
+		want error
+	}{
+		"PermissionChanged": {
+			old: defaultPermission("PROJ", "PROJECT_READ"),
+			new: defaultPermission("PROJ", "PROJECT_WRITE"),
+		},
+		"ProjectKeyChanged": {
+			old: defaultPermission("PROJ", "PROJECT_READ"),
+			new: defaultPermission("OTHER", "PROJECT_READ"),
+			want: kerrors.NewInvalid(gk, "open-read", field.ErrorList{
+				field.Invalid(fp.Child("projectKey"), "OTHER", "field is immutable"),
+			}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.new.ValidateUpdate(tc.old)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ValidateUpdate(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
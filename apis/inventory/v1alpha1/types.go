@@ -0,0 +1,194 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// InventoryParameters are the configurable fields of an Inventory.
+type InventoryParameters struct {
+	// ProjectKeys are the keys of the projects to list. Defaults to all
+	// projects the credentials of the ProviderConfig can see.
+	// +optional
+	ProjectKeys []string `json:"projectKeys,omitempty"`
+
+	// CountHooksAndKeys counts the webhooks and access keys of every
+	// repository, which takes two more requests per repository.
+	// +optional
+	CountHooksAndKeys bool `json:"countHooksAndKeys,omitempty"`
+
+	// RefreshInterval is how often the projects and repositories are listed
+	// again, e.g. 30m. Defaults to 1h.
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+
+	// Orphans reports the webhooks and access keys of every repository
+	// which carry the markers of the provider but are not the external
+	// resource of any Webhook or AccessKey, e.g. duplicates left behind by
+	// creates whose external name was not recorded. It takes two more
+	// requests per repository. Orphans are only reported, never deleted.
+	// +optional
+	Orphans *OrphanDetection `json:"orphans,omitempty"`
+}
+
+// OrphanDetection are the markers of the webhooks and access keys created
+// through the provider.
+type OrphanDetection struct {
+	// WebhookNamePrefix is the prefix of the names of the webhooks created
+	// through the provider, e.g. crossplane-. Defaults to all webhooks.
+	// +optional
+	WebhookNamePrefix string `json:"webhookNamePrefix,omitempty"`
+
+	// AccessKeyLabelPrefix is the prefix of the labels of the access keys
+	// created through the provider, e.g. crossplane-. Defaults to all
+	// access keys.
+	// +optional
+	AccessKeyLabelPrefix string `json:"accessKeyLabelPrefix,omitempty"`
+}
+
+// InventoryObservation are the observable fields of an Inventory.
+type InventoryObservation struct {
+	// Projects and their repositories, sorted by key. At most 1000
+	// repositories are listed, see Truncated.
+	// +optional
+	Projects []ProjectObservation `json:"projects,omitempty"`
+
+	// Truncated is true if the projects and repositories after the first
+	// 1000 repositories are left out, which keeps the inventory within the
+	// size limit of objects. The counts always cover all of them.
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+
+	// ProjectCount is the number of projects.
+	// +optional
+	ProjectCount int `json:"projectCount,omitempty"`
+
+	// RepositoryCount is the number of repositories of all projects.
+	// +optional
+	RepositoryCount int `json:"repositoryCount,omitempty"`
+
+	// OrphanCount is the number of orphans of all repositories, if
+	// detected.
+	// +optional
+	OrphanCount *int `json:"orphanCount,omitempty"`
+
+	// ListedAt is when the projects and repositories were listed.
+	// +optional
+	ListedAt *metav1.Time `json:"listedAt,omitempty"`
+}
+
+// ProjectObservation is a project of an Inventory.
+type ProjectObservation struct {
+	// Key of the project, e.g. PRJ.
+	Key string `json:"key"`
+
+	// Name of the project.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Repositories of the project, sorted by slug.
+	// +optional
+	Repositories []RepositoryObservation `json:"repositories,omitempty"`
+}
+
+// RepositoryObservation is a repository of an Inventory.
+type RepositoryObservation struct {
+	// ID of the repository in Bitbucket.
+	ID int `json:"id"`
+
+	// Slug of the repository, which is used in its URLs.
+	Slug string `json:"slug"`
+
+	// Name of the repository.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Webhooks is the number of webhooks of the repository, if counted.
+	// +optional
+	Webhooks *int `json:"webhooks,omitempty"`
+
+	// AccessKeys is the number of access keys of the repository, if
+	// counted.
+	// +optional
+	AccessKeys *int `json:"accessKeys,omitempty"`
+
+	// Orphans are the webhooks and access keys of the repository which
+	// carry the markers of the provider but are not the external resource
+	// of any managed resource, sorted by kind and ID.
+	// +optional
+	Orphans []OrphanObservation `json:"orphans,omitempty"`
+}
+
+// OrphanObservation is a webhook or access key without a managed resource.
+type OrphanObservation struct {
+	// Kind of the managed resource the orphan would have, either Webhook or
+	// AccessKey.
+	Kind string `json:"kind"`
+
+	// ID of the webhook or access key in Bitbucket.
+	ID int `json:"id"`
+
+	// Name of the webhook or label of the access key.
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// An InventorySpec defines the desired state of an Inventory.
+type InventorySpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	// +optional
+	ForProvider InventoryParameters `json:"forProvider,omitempty"`
+}
+
+// An InventoryStatus represents the observed state of an Inventory.
+type InventoryStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          InventoryObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An Inventory lists the projects and repositories of the Bitbucket server of
+// its ProviderConfig into its status. It only observes the server and never
+// changes it.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="PROJECTS",type="integer",JSONPath=".status.atProvider.projectCount"
+// +kubebuilder:printcolumn:name="REPOS",type="integer",JSONPath=".status.atProvider.repositoryCount"
+// +kubebuilder:printcolumn:name="ORPHANS",type="integer",JSONPath=".status.atProvider.orphanCount",priority=1
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster
+type Inventory struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   InventorySpec   `json:"spec"`
+	Status InventoryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// InventoryList contains a list of Inventory
+type InventoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Inventory `json:"items"`
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// LicenseInfoParameters are the configurable fields of a LicenseInfo. It has
+// none, the license of the server of the ProviderConfig is observed.
+type LicenseInfoParameters struct{}
+
+// LicenseInfoObservation are the observable fields of a LicenseInfo.
+type LicenseInfoObservation struct {
+	// CurrentUsers is the number of licensed users, i.e. the seats used.
+	// +optional
+	CurrentUsers int `json:"currentUsers,omitempty"`
+
+	// MaximumUsers is the number of users the license allows, i.e. the
+	// seats in total. Zero if unlimitedUsers.
+	// +optional
+	MaximumUsers int `json:"maximumUsers,omitempty"`
+
+	// UnlimitedUsers is true if the license allows any number of users.
+	// +optional
+	UnlimitedUsers bool `json:"unlimitedUsers,omitempty"`
+
+	// ExpiryDate is when the license expires, unset if it never does.
+	// +optional
+	ExpiryDate *metav1.Time `json:"expiryDate,omitempty"`
+
+	// MaintenanceExpiryDate is when the maintenance of the license expires,
+	// unset if it never does.
+	// +optional
+	MaintenanceExpiryDate *metav1.Time `json:"maintenanceExpiryDate,omitempty"`
+}
+
+// A LicenseInfoSpec defines the desired state of a LicenseInfo.
+type LicenseInfoSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	// +optional
+	ForProvider LicenseInfoParameters `json:"forProvider,omitempty"`
+}
+
+// A LicenseInfoStatus represents the observed state of a LicenseInfo.
+type LicenseInfoStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          LicenseInfoObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A LicenseInfo observes the license of the Bitbucket server of its
+// ProviderConfig into its status. It only observes the server and never
+// changes it. The credentials of the ProviderConfig need the admin
+// permission.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="USERS",type="integer",JSONPath=".status.atProvider.currentUsers"
+// +kubebuilder:printcolumn:name="MAXIMUM",type="integer",JSONPath=".status.atProvider.maximumUsers"
+// +kubebuilder:printcolumn:name="EXPIRES",type="string",JSONPath=".status.atProvider.expiryDate"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster
+type LicenseInfo struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LicenseInfoSpec   `json:"spec"`
+	Status LicenseInfoStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// LicenseInfoList contains a list of LicenseInfo
+type LicenseInfoList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LicenseInfo `json:"items"`
+}
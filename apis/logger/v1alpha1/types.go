@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Level is the level of a logger
+// +kubebuilder:validation:Enum=TRACE;DEBUG;INFO;WARN;ERROR
+type Level string
+
+// LoggerParameters are the configurable fields of a Logger.
+type LoggerParameters struct {
+	// LoggerName is the name of the logger, e.g. com.atlassian.bitbucket.
+	// +immutable
+	LoggerName string `json:"loggerName"`
+
+	// Level to set the logger to.
+	Level Level `json:"level"`
+
+	// ResetLevel is the level the logger is set back to when the Logger is
+	// deleted. Defaults to the level the logger had before the Logger first
+	// changed it.
+	// +optional
+	ResetLevel Level `json:"resetLevel,omitempty"`
+}
+
+// LoggerObservation are the observable fields of a Logger.
+type LoggerObservation struct {
+	// Level of the logger.
+	// +optional
+	Level Level `json:"level,omitempty"`
+}
+
+// A LoggerSpec defines the desired state of a Logger.
+type LoggerSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       LoggerParameters `json:"forProvider"`
+
+	// ManagementPolicies are the actions the provider may perform on the
+	// external resource, all of them by default. Use ["Observe"] to import
+	// and watch an existing resource without ever changing it.
+	// +optional
+	ManagementPolicies apisv1alpha1.ManagementPolicies `json:"managementPolicies,omitempty"`
+}
+
+// A LoggerStatus represents the observed state of a Logger.
+type LoggerStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          LoggerObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Logger sets the level of a logger of the Bitbucket server, e.g. to debug
+// an incident. Deleting it sets the level back. The credentials of the
+// ProviderConfig need the system admin permission.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="LOGGER",type="string",JSONPath=".spec.forProvider.loggerName"
+// +kubebuilder:printcolumn:name="LEVEL",type="string",JSONPath=".status.atProvider.level"
+// +kubebuilder:printcolumn:name="RESET",type="string",JSONPath=".spec.forProvider.resetLevel"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster
+type Logger struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LoggerSpec   `json:"spec"`
+	Status LoggerStatus `json:"status,omitempty"`
+}
+
+// GetManagementPolicies returns the actions the provider may perform on the
+// external resource
+func (a Logger) GetManagementPolicies() apisv1alpha1.ManagementPolicies {
+	return a.Spec.ManagementPolicies
+}
+
+// +kubebuilder:object:root=true
+
+// LoggerList contains a list of Logger
+type LoggerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Logger `json:"items"`
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func logger(name string, level Level) *Logger {
+	a := &Logger{}
+	a.SetName("debug-hooks")
+	a.Spec.ForProvider = LoggerParameters{
+		LoggerName: name,
+		Level:      level,
+	}
+	return a
+}
+
+func TestValidateUpdate(t *testing.T) {
+	gk := schema.GroupKind{Group: Group, Kind: LoggerKind}
+	fp := field.NewPath("spec", "forProvider")
+
+	cases := map[string]struct {
+		old  *Logger
+		new  *Logger
+		want error
+	}{
+		"LevelChanged": {
+			old: logger("com.atlassian.bitbucket.hook", "DEBUG"),
+			new: logger("com.atlassian.bitbucket.hook", "TRACE"),
+		},
+		"LoggerNameChanged": {
+			old: logger("com.atlassian.bitbucket.hook", "DEBUG"),
+			new: logger("com.atlassian.bitbucket", "DEBUG"),
+			want: kerrors.NewInvalid(gk, "debug-hooks", field.ErrorList{
+				field.Invalid(fp.Child("loggerName"), "com.atlassian.bitbucket", "field is immutable"),
+			}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.new.ValidateUpdate(tc.old)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ValidateUpdate(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// MergeStrategyParameters are the configurable fields of a MergeStrategy.
+type MergeStrategyParameters struct {
+	// The project key is the short name for the project. Typically the key
+	// for a project called "Foo Bar" would be "FB".
+	// +immutable
+	ProjectKey string `json:"projectKey"`
+
+	// The repoName is the name of the git repository. Leave it empty to
+	// set the strategies of the project, which its repositories inherit
+	// unless they set their own.
+	// +optional
+	// +immutable
+	RepoName string `json:"repoName,omitempty"`
+
+	// The repoSlug is the slug of the git repository used in the URLs of
+	// the Bitbucket API. Defaults to the repoName in lower case with
+	// spaces and other special characters replaced by hyphens. Set it for
+	// repositories which were renamed after they were created.
+	// +optional
+	// +immutable
+	RepoSlug string `json:"repoSlug,omitempty"`
+
+	// Strategies pull requests may be merged with.
+	// +kubebuilder:validation:MinItems=1
+	Strategies []Strategy `json:"strategies"`
+
+	// DefaultStrategy is the strategy selected when merging a pull
+	// request. It must be one of the strategies. Defaults to the first
+	// of them.
+	// +optional
+	DefaultStrategy Strategy `json:"defaultStrategy,omitempty"`
+}
+
+// Strategy is a way to merge a pull request
+// +kubebuilder:validation:Enum="no-ff";"ff";"ff-only";"rebase-no-ff";"rebase-ff-only";"squash";"squash-ff-only"
+type Strategy string
+
+// MergeStrategyObservation are the observable fields of a MergeStrategy.
+type MergeStrategyObservation struct {
+	// Strategies pull requests may be merged with.
+	// +optional
+	Strategies []Strategy `json:"strategies,omitempty"`
+
+	// DefaultStrategy is the strategy selected when merging a pull
+	// request.
+	// +optional
+	DefaultStrategy Strategy `json:"defaultStrategy,omitempty"`
+
+	// Scope the observed strategies are set at, REPOSITORY, PROJECT or
+	// DEFAULT for the defaults of the server. The strategies are
+	// inherited if it is not the scope of the MergeStrategy.
+	// +optional
+	Scope string `json:"scope,omitempty"`
+}
+
+// A MergeStrategySpec defines the desired state of a MergeStrategy.
+type MergeStrategySpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       MergeStrategyParameters `json:"forProvider"`
+
+	// ManagementPolicies are the actions the provider may perform on the
+	// external resource, all of them by default. Use ["Observe"] to import
+	// and watch an existing resource without ever changing it.
+	// +optional
+	ManagementPolicies apisv1alpha1.ManagementPolicies `json:"managementPolicies,omitempty"`
+}
+
+// A MergeStrategyStatus represents the observed state of a MergeStrategy.
+type MergeStrategyStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          MergeStrategyObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A MergeStrategy sets the strategies the pull requests of a project or
+// repository may be merged with. Deleting it restores the inherited
+// strategies.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.forProvider.projectKey"
+// +kubebuilder:printcolumn:name="REPO",type="string",JSONPath=".spec.forProvider.repoName"
+// +kubebuilder:printcolumn:name="DEFAULT",type="string",JSONPath=".status.atProvider.defaultStrategy"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster
+type MergeStrategy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MergeStrategySpec   `json:"spec"`
+	Status MergeStrategyStatus `json:"status,omitempty"`
+}
+
+// Repo returns the repository of the strategies, with an empty slug for the
+// strategies of the project
+func (a MergeStrategy) Repo() bitbucket.Repo {
+	return bitbucket.Repo{
+		ProjectKey: bitbucket.ProjectKey(a.Spec.ForProvider.ProjectKey),
+		Repo:       a.RepoSlug(),
+	}
+}
+
+// RepoSlug returns the slug of the repository, derived from its name unless
+// it is set explicitly
+func (a MergeStrategy) RepoSlug() string {
+	if a.Spec.ForProvider.RepoSlug != "" {
+		return a.Spec.ForProvider.RepoSlug
+	}
+	return bitbucket.RepoSlug(a.Spec.ForProvider.RepoName)
+}
+
+// Scope returns the scope the strategies are set at, MergeConfigRepository
+// or MergeConfigProject
+func (a MergeStrategy) Scope() string {
+	if a.RepoSlug() == "" {
+		return bitbucket.MergeConfigProject
+	}
+	return bitbucket.MergeConfigRepository
+}
+
+// MergeConfig returns the merge configuration of the bitbucket client, with
+// the default strategy defaulted to the first strategy
+func (a MergeStrategy) MergeConfig() bitbucket.MergeConfig {
+	strategies := make([]string, 0, len(a.Spec.ForProvider.Strategies))
+	for _, s := range a.Spec.ForProvider.Strategies {
+		strategies = append(strategies, string(s))
+	}
+	def := string(a.Spec.ForProvider.DefaultStrategy)
+	if def == "" && len(strategies) > 0 {
+		def = strategies[0]
+	}
+	return bitbucket.MergeConfig{Strategies: strategies, DefaultStrategy: def}
+}
+
+// GetManagementPolicies returns the actions the provider may perform on the
+// external resource
+func (a MergeStrategy) GetManagementPolicies() apisv1alpha1.ManagementPolicies {
+	return a.Spec.ManagementPolicies
+}
+
+// +kubebuilder:object:root=true
+
+// MergeStrategyList contains a list of MergeStrategy
+type MergeStrategyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MergeStrategy `json:"items"`
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const errNotMergeStrategy = "object is not a MergeStrategy"
+
+var _ admission.Validator = &MergeStrategy{}
+
+// ValidateCreate checks that the default strategy is enabled
+func (a *MergeStrategy) ValidateCreate() error {
+	errs := a.validateDefault()
+	if len(errs) == 0 {
+		return nil
+	}
+	return kerrors.NewInvalid(schema.GroupKind{Group: Group, Kind: MergeStrategyKind}, a.GetName(), errs)
+}
+
+// ValidateUpdate rejects changes of the project and repository of the
+// strategies and checks that the default strategy is enabled
+func (a *MergeStrategy) ValidateUpdate(old runtime.Object) error {
+	o, ok := old.(*MergeStrategy)
+	if !ok {
+		return errors.New(errNotMergeStrategy)
+	}
+
+	fp := field.NewPath("spec", "forProvider")
+	var errs field.ErrorList
+	errs = append(errs, apivalidation.ValidateImmutableField(a.Spec.ForProvider.ProjectKey, o.Spec.ForProvider.ProjectKey, fp.Child("projectKey"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(a.Spec.ForProvider.RepoName, o.Spec.ForProvider.RepoName, fp.Child("repoName"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(a.Spec.ForProvider.RepoSlug, o.Spec.ForProvider.RepoSlug, fp.Child("repoSlug"))...)
+	errs = append(errs, a.validateDefault()...)
+	if len(errs) == 0 {
+		return nil
+	}
+	return kerrors.NewInvalid(schema.GroupKind{Group: Group, Kind: MergeStrategyKind}, a.GetName(), errs)
+}
+
+// ValidateDelete implements admission.Validator
+func (a *MergeStrategy) ValidateDelete() error {
+	return nil
+}
+
+// validateDefault checks that the default strategy, if set, is one of the
+// strategies.
+func (a *MergeStrategy) validateDefault() field.ErrorList {
+	def := a.Spec.ForProvider.DefaultStrategy
+	if def == "" {
+		return nil
+	}
+	for _, s := range a.Spec.ForProvider.Strategies {
+		if s == def {
+			return nil
+		}
+	}
+	return field.ErrorList{field.Invalid(field.NewPath("spec", "forProvider", "defaultStrategy"), def, "must be one of strategies")}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func mergeStrategy(repoName string, def Strategy, strategies ...Strategy) *MergeStrategy {
+	a := &MergeStrategy{}
+	a.SetName("squash-only")
+	a.Spec.ForProvider = MergeStrategyParameters{
+		ProjectKey:      "PROJ",
+		RepoName:        repoName,
+		Strategies:      strategies,
+		DefaultStrategy: def,
+	}
+	return a
+}
+
+func TestValidateCreate(t *testing.T) {
+	gk := schema.GroupKind{Group: Group, Kind: MergeStrategyKind}
+	fp := field.NewPath("spec", "forProvider")
+
+	cases := map[string]struct {
+		obj  *MergeStrategy
+		want error
+	}{
+		"NoDefault": {
+			obj: mergeStrategy("repo", "", "squash"),
+		},
+		"DefaultEnabled": {
+			obj: mergeStrategy("repo", "no-ff", "squash", "no-ff"),
+		},
+		"DefaultNotEnabled": {
+			obj: mergeStrategy("repo", "no-ff", "squash"),
+			want: kerrors.NewInvalid(gk, "squash-only", field.ErrorList{
+				field.Invalid(fp.Child("defaultStrategy"), Strategy("no-ff"), "must be one of strategies"),
+			}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.obj.ValidateCreate()
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ValidateCreate(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	gk := schema.GroupKind{Group: Group, Kind: MergeStrategyKind}
+	fp := field.NewPath("spec", "forProvider")
+
+	cases := map[string]struct {
+		old  *MergeStrategy
+		new  *MergeStrategy
+		want error
+	}{
+		"StrategiesChanged": {
+			old: mergeStrategy("repo", "", "squash"),
+			new: mergeStrategy("repo", "ff", "squash", "ff"),
+		},
+		"RepoNameChanged": {
+			old: mergeStrategy("repo", "", "squash"),
+			new: mergeStrategy("", "", "squash"),
+			want: kerrors.NewInvalid(gk, "squash-only", field.ErrorList{
+				field.Invalid(fp.Child("repoName"), "", "field is immutable"),
+			}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.new.ValidateUpdate(tc.old)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ValidateUpdate(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
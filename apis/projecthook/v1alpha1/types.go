@@ -0,0 +1,140 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ProjectHookParameters are the configurable fields of a ProjectHook.
+type ProjectHookParameters struct {
+	// The project key is the short name for the project. Typically the key
+	// for a project called "Foo Bar" would be "FB".
+	// +immutable
+	ProjectKey string `json:"projectKey"`
+
+	// HookKey is the key of the repository hook, e.g.
+	// com.atlassian.bitbucket.server.bitbucket-bundled-hooks:force-push-hook.
+	// +immutable
+	HookKey string `json:"hookKey"`
+
+	// Settings of the hook, whose fields depend on the hook. They replace
+	// the settings of the hook in Bitbucket as a whole. The settings in
+	// Bitbucket are left alone when unset.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Settings *runtime.RawExtension `json:"settings,omitempty"`
+}
+
+// ProjectHookObservation are the observable fields of a ProjectHook.
+type ProjectHookObservation struct {
+	// Name of the hook as shown in the project settings.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Type of the hook, PRE_RECEIVE or POST_RECEIVE.
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Version of the plugin of the hook.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Enabled is true if the hook is enabled for the project.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Configured is true if the hook has settings for the project.
+	// +optional
+	Configured bool `json:"configured,omitempty"`
+}
+
+// A ProjectHookSpec defines the desired state of a ProjectHook.
+type ProjectHookSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ProjectHookParameters `json:"forProvider"`
+
+	// ManagementPolicies are the actions the provider may perform on the
+	// external resource, all of them by default. Use ["Observe"] to import
+	// and watch an existing resource without ever changing it.
+	// +optional
+	ManagementPolicies apisv1alpha1.ManagementPolicies `json:"managementPolicies,omitempty"`
+}
+
+// A ProjectHookStatus represents the observed state of a ProjectHook.
+type ProjectHookStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ProjectHookObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ProjectHook enables a pre-receive or post-receive repository hook for
+// all repositories of a project which don't configure the hook themselves.
+// Deleting it disables the hook again.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.forProvider.projectKey"
+// +kubebuilder:printcolumn:name="HOOK",type="string",JSONPath=".status.atProvider.name"
+// +kubebuilder:printcolumn:name="TYPE",type="string",JSONPath=".status.atProvider.type"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster
+type ProjectHook struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProjectHookSpec   `json:"spec"`
+	Status ProjectHookStatus `json:"status,omitempty"`
+}
+
+// ProjectKey returns the key of the project as Bitbucket stores it
+func (a ProjectHook) ProjectKey() string {
+	return bitbucket.ProjectKey(a.Spec.ForProvider.ProjectKey)
+}
+
+// Settings returns the desired settings of the hook, nil if they are unset
+func (a ProjectHook) Settings() (bitbucket.HookSettings, error) {
+	if a.Spec.ForProvider.Settings == nil || len(a.Spec.ForProvider.Settings.Raw) == 0 {
+		return nil, nil
+	}
+	settings := bitbucket.HookSettings{}
+	err := json.Unmarshal(a.Spec.ForProvider.Settings.Raw, &settings)
+	return settings, err
+}
+
+// GetManagementPolicies returns the actions the provider may perform on the
+// external resource
+func (a ProjectHook) GetManagementPolicies() apisv1alpha1.ManagementPolicies {
+	return a.Spec.ManagementPolicies
+}
+
+// +kubebuilder:object:root=true
+
+// ProjectHookList contains a list of ProjectHook
+type ProjectHookList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProjectHook `json:"items"`
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+func projectHook(projectKey, hookKey string) *ProjectHook {
+	a := &ProjectHook{}
+	a.SetName("no-force-push")
+	a.Spec.ForProvider = ProjectHookParameters{
+		ProjectKey: projectKey,
+		HookKey:    hookKey,
+	}
+	return a
+}
+
+func TestValidateUpdate(t *testing.T) {
+	gk := schema.GroupKind{Group: Group, Kind: ProjectHookKind}
+	fp := field.NewPath("spec", "forProvider")
+	forcePush := "com.atlassian.bitbucket.server.bitbucket-bundled-hooks:force-push-hook"
+
+	cases := map[string]struct {
+		old  *ProjectHook
+		new  *ProjectHook
+		want error
+	}{
+		"SettingsChanged": {
+			old: projectHook("PROJ", forcePush),
+			new: func() *ProjectHook {
+				a := projectHook("PROJ", forcePush)
+				a.Spec.ForProvider.Settings = &runtime.RawExtension{Raw: []byte(`{"references":"refs/heads/master"}`)}
+				return a
+			}(),
+		},
+		"ProjectKeyChanged": {
+			old: projectHook("PROJ", forcePush),
+			new: projectHook("OTHER", forcePush),
+			want: kerrors.NewInvalid(gk, "no-force-push", field.ErrorList{
+				field.Invalid(fp.Child("projectKey"), "OTHER", "field is immutable"),
+			}),
+		},
+		"HookKeyChanged": {
+			old: projectHook("PROJ", forcePush),
+			new: projectHook("PROJ", "other:hook"),
+			want: kerrors.NewInvalid(gk, "no-force-push", field.ErrorList{
+				field.Invalid(fp.Child("hookKey"), "other:hook", "field is immutable"),
+			}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.new.ValidateUpdate(tc.old)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ValidateUpdate(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSettings(t *testing.T) {
+	a := projectHook("PROJ", "key")
+	if got, err := a.Settings(); got != nil || err != nil {
+		t.Errorf("Settings(): want nil without settings, got %v, %v", got, err)
+	}
+
+	a.Spec.ForProvider.Settings = &runtime.RawExtension{Raw: []byte(`{"references":"refs/heads/master","exemptUsers":[]}`)}
+	want := bitbucket.HookSettings{"references": "refs/heads/master", "exemptUsers": []interface{}{}}
+	got, err := a.Settings()
+	if err != nil {
+		t.Fatalf("Settings(): %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Settings(): -want, +got\n%s", diff)
+	}
+}
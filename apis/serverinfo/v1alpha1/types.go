@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ServerInfoParameters are the configurable fields of a ServerInfo. It has
+// none, the server of the ProviderConfig is observed.
+type ServerInfoParameters struct{}
+
+// ServerInfoObservation are the observable fields of a ServerInfo.
+type ServerInfoObservation struct {
+	// Version of the server, e.g. 7.21.0.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// BuildNumber of the version, e.g. 7021000. It increases with each
+	// version, so it can be compared as a number.
+	// +optional
+	BuildNumber string `json:"buildNumber,omitempty"`
+
+	// DisplayName of the product, e.g. Bitbucket.
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// A ServerInfoSpec defines the desired state of a ServerInfo.
+type ServerInfoSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	// +optional
+	ForProvider ServerInfoParameters `json:"forProvider,omitempty"`
+}
+
+// A ServerInfoStatus represents the observed state of a ServerInfo.
+type ServerInfoStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ServerInfoObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ServerInfo observes the version of the Bitbucket server of its
+// ProviderConfig into its status, e.g. for compositions which only create
+// resources the version supports. It only observes the server and never
+// changes it.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="VERSION",type="string",JSONPath=".status.atProvider.version"
+// +kubebuilder:printcolumn:name="BUILD",type="string",JSONPath=".status.atProvider.buildNumber"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster
+type ServerInfo struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ServerInfoSpec   `json:"spec"`
+	Status ServerInfoStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ServerInfoList contains a list of ServerInfo
+type ServerInfoList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ServerInfo `json:"items"`
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// A ManagementAction is an operation the provider can perform on an
+// external resource.
+// +kubebuilder:validation:Enum=Observe;Create;Update;Delete;LateInitialize;*
+type ManagementAction string
+
+// Management actions, named like the management policies of Crossplane
+const (
+	// ManagementActionObserve observes the external resource
+	ManagementActionObserve ManagementAction = "Observe"
+	// ManagementActionCreate creates the external resource when it does not
+	// exist
+	ManagementActionCreate ManagementAction = "Create"
+	// ManagementActionUpdate updates the external resource when it drifted
+	ManagementActionUpdate ManagementAction = "Update"
+	// ManagementActionDelete deletes the external resource with the managed
+	// resource
+	ManagementActionDelete ManagementAction = "Delete"
+	// ManagementActionLateInitialize fills unset spec fields from the
+	// external resource
+	ManagementActionLateInitialize ManagementAction = "LateInitialize"
+	// ManagementActionAll allows all actions
+	ManagementActionAll ManagementAction = "*"
+)
+
+// ManagementPolicies are the actions the provider is allowed to perform on
+// an external resource. For example ["Observe"] only imports and watches an
+// existing resource and ["Observe", "Create", "Update", "LateInitialize"]
+// leaves it in place when the managed resource is deleted.
+type ManagementPolicies []ManagementAction
+
+// Allows returns whether the action is allowed. All actions are allowed
+// when no policies are set.
+func (p ManagementPolicies) Allows(action ManagementAction) bool {
+	if len(p) == 0 {
+		return true
+	}
+	for _, a := range p {
+		if a == action || a == ManagementActionAll {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFullControl returns whether all actions are allowed
+func (p ManagementPolicies) IsFullControl() bool {
+	for _, a := range []ManagementAction{ManagementActionObserve, ManagementActionCreate, ManagementActionUpdate, ManagementActionDelete, ManagementActionLateInitialize} {
+		if !p.Allows(a) {
+			return false
+		}
+	}
+	return true
+}
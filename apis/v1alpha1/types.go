@@ -32,6 +32,26 @@ type ProviderConfigSpec struct {
 
 	// TLS Configuration parameters
 	TLSConfig *TLSConfig `json:"tlsConfig,omitempty"`
+
+	// Authentication configures how the token is sent to the server.
+	// Defaults to sending it as bearer token.
+	// +optional
+	Authentication *Authentication `json:"authentication,omitempty"`
+
+	// RequestTimeout bounds every single request to the Bitbucket API, so
+	// one slow endpoint can't use up the whole reconcile deadline. The
+	// deadline of the reconcile still applies. No timeout when unset.
+	// +optional
+	RequestTimeout *metav1.Duration `json:"requestTimeout,omitempty"`
+
+	// PageConcurrency is the number of pages of a list which are requested
+	// at once, e.g. of the repositories of a project. Lists with many pages
+	// are read faster at the cost of a few more requests past their last
+	// page. Defaults to 1, which requests one page after another.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=16
+	// +optional
+	PageConcurrency int `json:"pageConcurrency,omitempty"`
 }
 
 // TLSConfig enables configuration of tls options
@@ -40,6 +60,35 @@ type TLSConfig struct {
 	InsecureSkipVerify *bool `json:"insecureSkipVerify,omitempty"`
 }
 
+// Schemes to send the token to the server
+const (
+	// AuthSchemeBearer sends the token as bearer token
+	AuthSchemeBearer = "Bearer"
+	// AuthSchemeBasic sends the token as password of basic auth
+	AuthSchemeBasic = "Basic"
+	// AuthSchemeHeader sends the token in a custom header
+	AuthSchemeHeader = "Header"
+)
+
+// Authentication configures how the token is sent to the server
+type Authentication struct {
+	// Scheme used to send the token. Bearer sends it in the Authorization
+	// header as bearer token, Basic as the password of HTTP basic auth and
+	// Header as is in the header given by headerName.
+	// +kubebuilder:validation:Enum=Bearer;Basic;Header
+	// +kubebuilder:default=Bearer
+	// +optional
+	Scheme string `json:"scheme,omitempty"`
+
+	// Username for the Basic scheme
+	// +optional
+	Username string `json:"username,omitempty"`
+
+	// HeaderName is the header carrying the token for the Header scheme
+	// +optional
+	HeaderName string `json:"headerName,omitempty"`
+}
+
 // ProviderCredentials required to authenticate.
 type ProviderCredentials struct {
 	// Source of the provider credentials.
@@ -0,0 +1,37 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// EventRepoRefsChanged is triggered by pushes to the repository
+const EventRepoRefsChanged Event = "repo:refs_changed"
+
+var _ admission.Defaulter = &Webhook{}
+
+// Default sets the name of the webhook to the name of the object and its
+// events to repo:refs_changed if unset
+func (a *Webhook) Default() {
+	if a.Spec.ForProvider.Webhook.Name == "" {
+		a.Spec.ForProvider.Webhook.Name = a.GetName()
+	}
+	if len(a.Spec.ForProvider.Webhook.Events) == 0 {
+		a.Spec.ForProvider.Webhook.Events = []Event{EventRepoRefsChanged}
+	}
+}
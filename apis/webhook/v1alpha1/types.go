@@ -17,9 +17,13 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"strings"
+	"text/template"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clients/bitbucket"
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 )
 
@@ -42,20 +46,48 @@ type WebhookParameters struct {
 	// +immutable
 	RepoName string `json:"repoName"`
 
+	// The repoSlug is the slug of the git repository used in the URLs of
+	// the Bitbucket API. Defaults to the repoName in lower case with
+	// spaces and other special characters replaced by hyphens. Set it for
+	// repositories which were renamed after they were created.
+	// +optional
+	// +immutable
+	RepoSlug string `json:"repoSlug,omitempty"`
+
 	Webhook BitbucketWebhook `json:"webhook"`
 }
 
 // BitbucketWebhook provide a way to configure Bitbucket Server to make requests
 // to your server (or another external service) whenever certain events occur in Bitbucket
 type BitbucketWebhook struct {
-	Name string `json:"name"`
+	// Name of the webhook. Defaults to the name of the Webhook resource.
+	// +optional
+	Name string `json:"name,omitempty"`
 
 	// +optional
 	Configuration *BitbucketWebhookConfiguration `json:"configuration,omitempty"`
 
-	Events []Event `json:"events"`
+	// Events that trigger the webhook. Defaults to repo:refs_changed.
+	// +optional
+	// +kubebuilder:default={"repo:refs_changed"}
+	Events []Event `json:"events,omitempty"`
+
+	// URL the webhook sends requests to. Exactly one of url, urlTemplate
+	// and urlSecretRef must be set.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// URLTemplate is a Go template of the URL the webhook sends requests
+	// to, rendered with .ProjectKey, .RepoName and .RepoSlug of the
+	// repository, e.g. https://ci.example.com/hooks/{{ .ProjectKey }}/{{ .RepoSlug }}
+	// +optional
+	URLTemplate string `json:"urlTemplate,omitempty"`
 
-	URL string `json:"url"`
+	// URLSecretRef selects the key of a secret holding the URL the webhook
+	// sends requests to, e.g. from the connection details of the receiving
+	// side.
+	// +optional
+	URLSecretRef *xpv1.SecretKeySelector `json:"urlSecretRef,omitempty"`
 
 	// active bool
 }
@@ -71,19 +103,84 @@ type BitbucketWebhookConfiguration struct {
 	// Webhook secret. Leave empty to get a secret in the connection details
 	// +kubebuilder:validation:Optional
 	Secret string `json:"secret"`
+
+	// Extra options of the configuration object of the webhook, sent
+	// verbatim next to the secret, e.g. options of newer Bitbucket versions
+	// which the provider doesn't model yet. Only the options set here are
+	// compared with Bitbucket.
+	// +optional
+	Extra map[string]string `json:"extra,omitempty"`
 	// TODO: ref as an option
 	// TODO: Generate as an option, output connection secret
 }
 
+// WebhookInitParameters are fields of a Webhook that are only set when the
+// webhook is created. Later changes in Bitbucket are not reverted.
+type WebhookInitParameters struct {
+	// Configuration of the webhook, e.g. an initial secret that may be
+	// rotated in Bitbucket afterwards. Ignored if the configuration is set
+	// in forProvider.
+	// +optional
+	Configuration *BitbucketWebhookConfiguration `json:"configuration,omitempty"`
+}
+
 // WebhookObservation are the observable fields of an Webhook.
 type WebhookObservation struct {
+	// ID of the webhook in Bitbucket.
 	ID int `json:"id,omitempty"`
+
+	// Active is whether the webhook sends events.
+	// +optional
+	Active *bool `json:"active,omitempty"`
+
+	// CreatedAt is when the webhook was created.
+	// +optional
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+
+	// UpdatedAt is when the webhook was last updated.
+	// +optional
+	UpdatedAt *metav1.Time `json:"updatedAt,omitempty"`
+
+	// Repository of the webhook, recorded when the webhook is first
+	// observed.
+	// +optional
+	Repository *RepositoryObservation `json:"repository,omitempty"`
+}
+
+// RepositoryObservation are the observable fields of the repository of a
+// Webhook.
+type RepositoryObservation struct {
+	// ID of the repository in Bitbucket.
+	ID int `json:"id"`
+
+	// Slug of the repository, which is used in its URLs.
+	// +optional
+	Slug string `json:"slug,omitempty"`
+
+	// HTTPCloneURL is the URL to clone the repository over HTTP(S).
+	// +optional
+	HTTPCloneURL string `json:"httpCloneURL,omitempty"`
+
+	// SSHCloneURL is the URL to clone the repository over SSH.
+	// +optional
+	SSHCloneURL string `json:"sshCloneURL,omitempty"`
 }
 
 // An WebhookSpec defines the desired state of an Webhook.
 type WebhookSpec struct {
 	xpv1.ResourceSpec `json:",inline"`
 	ForProvider       WebhookParameters `json:"forProvider"`
+
+	// InitProvider are fields that are set when the webhook is created but
+	// are not kept in sync with forProvider afterwards.
+	// +optional
+	InitProvider WebhookInitParameters `json:"initProvider,omitempty"`
+
+	// ManagementPolicies are the actions the provider may perform on the
+	// external resource, all of them by default. Use ["Observe"] to import
+	// and watch an existing resource without ever changing it.
+	// +optional
+	ManagementPolicies apisv1alpha1.ManagementPolicies `json:"managementPolicies,omitempty"`
 }
 
 // An WebhookStatus represents the observed state of an Webhook.
@@ -96,10 +193,11 @@ type WebhookStatus struct {
 
 // An Webhook is an SSH key with read or write access to a bitbucket git repo.
 // +kubebuilder:subresource:status
-// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.forProvider.projectKey"
-// +kubebuilder:printcolumn:name="REPO-NAME",type="string",JSONPath=".spec.forProvider.repoName"
-// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.forProvider.projectKey"
+// +kubebuilder:printcolumn:name="REPO",type="string",JSONPath=".spec.forProvider.repoName"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:resource:scope=Cluster
 type Webhook struct {
@@ -114,12 +212,22 @@ type Webhook struct {
 // TODO: Move
 func (a Webhook) Repo() bitbucket.Repo {
 	return bitbucket.Repo{
-		ProjectKey: a.Spec.ForProvider.ProjectKey,
-		Repo:       a.Spec.ForProvider.RepoName,
+		ProjectKey: bitbucket.ProjectKey(a.Spec.ForProvider.ProjectKey),
+		Repo:       a.RepoSlug(),
 	}
 }
 
-// Webhook returns the bitbucket rest client of the object
+// RepoSlug returns the slug of the repository, derived from its name unless
+// it is set explicitly
+func (a Webhook) RepoSlug() string {
+	if a.Spec.ForProvider.RepoSlug != "" {
+		return a.Spec.ForProvider.RepoSlug
+	}
+	return bitbucket.RepoSlug(a.Spec.ForProvider.RepoName)
+}
+
+// Webhook returns the bitbucket rest client of the object, with the URL as
+// set in the spec. Use WebhookURL to render the URL template.
 // TODO: Move
 func (a Webhook) Webhook() bitbucket.Webhook {
 	events := make([]string, 0, len(a.Spec.ForProvider.Webhook.Events))
@@ -135,13 +243,66 @@ func (a Webhook) Webhook() bitbucket.Webhook {
 	return bitbucket.Webhook{
 		// ID: get from CR? meta.GetExternalName?
 
-		Name:          a.Spec.ForProvider.Webhook.Name,
-		Configuration: *configuration,
+		Name:          a.WebhookName(),
+		Configuration: bitbucket.WebhookConfiguration(*configuration),
 		Events:        events,
 		URL:           a.Spec.ForProvider.Webhook.URL,
 	}
 }
 
+// WebhookName returns the name of the webhook, which defaults to the name of
+// the object
+func (a Webhook) WebhookName() string {
+	if a.Spec.ForProvider.Webhook.Name != "" {
+		return a.Spec.ForProvider.Webhook.Name
+	}
+	return a.GetName()
+}
+
+// urlTemplateData are the fields available to the URL template of a webhook.
+type urlTemplateData struct {
+	ProjectKey string
+	RepoName   string
+	RepoSlug   string
+}
+
+// WebhookURL returns the URL of the webhook, rendered from its URL template
+// if one is set. The URL of a secret has to be read by the caller.
+func (a Webhook) WebhookURL() (string, error) {
+	if a.Spec.ForProvider.Webhook.URLTemplate == "" {
+		return a.Spec.ForProvider.Webhook.URL, nil
+	}
+	t, err := template.New("url").Option("missingkey=error").Parse(a.Spec.ForProvider.Webhook.URLTemplate)
+	if err != nil {
+		return "", err
+	}
+	var url strings.Builder
+	err = t.Execute(&url, urlTemplateData{
+		ProjectKey: a.Spec.ForProvider.ProjectKey,
+		RepoName:   a.Spec.ForProvider.RepoName,
+		RepoSlug:   a.RepoSlug(),
+	})
+	return url.String(), err
+}
+
+// InitSecret returns the secret of the webhook that is only set at creation,
+// or an empty string if the secret is kept in sync
+func (a Webhook) InitSecret() string {
+	if c := a.Spec.ForProvider.Webhook.Configuration; c != nil && c.Secret != "" {
+		return ""
+	}
+	if c := a.Spec.InitProvider.Configuration; c != nil {
+		return c.Secret
+	}
+	return ""
+}
+
+// GetManagementPolicies returns the actions the provider may perform on the
+// external resource
+func (a Webhook) GetManagementPolicies() apisv1alpha1.ManagementPolicies {
+	return a.Spec.ManagementPolicies
+}
+
 // +kubebuilder:object:root=true
 
 // WebhookList contains a list of Webhook
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const errNotWebhook = "object is not a Webhook"
+
+var _ admission.Validator = &Webhook{}
+
+// ValidateCreate checks the URL of the webhook
+func (a *Webhook) ValidateCreate() error {
+	errs := a.validateURL()
+	if len(errs) == 0 {
+		return nil
+	}
+	return kerrors.NewInvalid(schema.GroupKind{Group: Group, Kind: WebhookKind}, a.GetName(), errs)
+}
+
+// ValidateUpdate rejects changes of the immutable fields of the webhook and
+// checks its URL if it changed. The URL is not checked while the webhook is
+// deleted, so that webhooks created before the check can still be deleted.
+func (a *Webhook) ValidateUpdate(old runtime.Object) error {
+	o, ok := old.(*Webhook)
+	if !ok {
+		return errors.New(errNotWebhook)
+	}
+
+	fp := field.NewPath("spec", "forProvider")
+	var errs field.ErrorList
+	errs = append(errs, apivalidation.ValidateImmutableField(a.Spec.ForProvider.ProjectKey, o.Spec.ForProvider.ProjectKey, fp.Child("projectKey"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(a.Spec.ForProvider.RepoName, o.Spec.ForProvider.RepoName, fp.Child("repoName"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(a.Spec.ForProvider.RepoSlug, o.Spec.ForProvider.RepoSlug, fp.Child("repoSlug"))...)
+	if a.GetDeletionTimestamp() == nil && urlChanged(a.Spec.ForProvider.Webhook, o.Spec.ForProvider.Webhook) {
+		errs = append(errs, a.validateURL()...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return kerrors.NewInvalid(schema.GroupKind{Group: Group, Kind: WebhookKind}, a.GetName(), errs)
+}
+
+// urlChanged reports whether any of the fields the URL of the webhook is
+// read from differ.
+func urlChanged(a, b BitbucketWebhook) bool {
+	return a.URL != b.URL || a.URLTemplate != b.URLTemplate || !reflect.DeepEqual(a.URLSecretRef, b.URLSecretRef)
+}
+
+// ValidateDelete implements admission.Validator
+func (a *Webhook) ValidateDelete() error {
+	return nil
+}
+
+// validateURL checks that exactly one of the URL, a URL template that can
+// be rendered and a secret of the URL is set.
+func (a *Webhook) validateURL() field.ErrorList {
+	wp := field.NewPath("spec", "forProvider", "webhook")
+	w := a.Spec.ForProvider.Webhook
+	set := 0
+	for _, ok := range []bool{w.URL != "", w.URLTemplate != "", w.URLSecretRef != nil} {
+		if ok {
+			set++
+		}
+	}
+	switch {
+	case set == 0:
+		return field.ErrorList{field.Required(wp.Child("url"), "one of url, urlTemplate and urlSecretRef must be set")}
+	case set > 1:
+		return field.ErrorList{field.Forbidden(wp, "only one of url, urlTemplate and urlSecretRef may be set")}
+	}
+	if _, err := a.WebhookURL(); err != nil {
+		return field.ErrorList{field.Invalid(wp.Child("urlTemplate"), w.URLTemplate, err.Error())}
+	}
+	return nil
+}
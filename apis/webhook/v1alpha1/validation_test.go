@@ -0,0 +1,190 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func webhook(url, urlTemplate string) *Webhook {
+	a := &Webhook{}
+	a.SetName("cool-hook")
+	a.Spec.ForProvider = WebhookParameters{
+		ProjectKey: "PROJ",
+		RepoName:   "My Repo",
+		Webhook: BitbucketWebhook{
+			URL:         url,
+			URLTemplate: urlTemplate,
+		},
+	}
+	return a
+}
+
+func withURLSecretRef(a *Webhook) *Webhook {
+	a.Spec.ForProvider.Webhook.URLSecretRef = &xpv1.SecretKeySelector{
+		SecretReference: xpv1.SecretReference{Name: "receiver", Namespace: "ci"},
+		Key:             "url",
+	}
+	return a
+}
+
+func TestWebhookURL(t *testing.T) {
+	cases := map[string]struct {
+		hook    *Webhook
+		want    string
+		wantErr bool
+	}{
+		"URL": {
+			hook: webhook("https://example.com", ""),
+			want: "https://example.com",
+		},
+		"Template": {
+			hook: webhook("", "https://ci.example.com/{{ .ProjectKey }}/{{ .RepoSlug }}?name={{ .RepoName | urlquery }}"),
+			want: "https://ci.example.com/PROJ/my-repo?name=My+Repo",
+		},
+		"UnknownField": {
+			hook:    webhook("", "https://ci.example.com/{{ .Repo }}"),
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.hook.WebhookURL()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("WebhookURL(): want error %v, got %v", tc.wantErr, err)
+			}
+			if got != tc.want && !tc.wantErr {
+				t.Errorf("WebhookURL(): want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateCreate(t *testing.T) {
+	gk := schema.GroupKind{Group: Group, Kind: WebhookKind}
+	wp := field.NewPath("spec", "forProvider", "webhook")
+
+	cases := map[string]struct {
+		hook *Webhook
+		want error
+	}{
+		"URL": {
+			hook: webhook("https://example.com", ""),
+		},
+		"Template": {
+			hook: webhook("", "https://ci.example.com/{{ .RepoSlug }}"),
+		},
+		"NoURL": {
+			hook: webhook("", ""),
+			want: kerrors.NewInvalid(gk, "cool-hook", field.ErrorList{
+				field.Required(wp.Child("url"), "one of url, urlTemplate and urlSecretRef must be set"),
+			}),
+		},
+		"Secret": {
+			hook: withURLSecretRef(webhook("", "")),
+		},
+		"URLAndSecret": {
+			hook: withURLSecretRef(webhook("https://example.com", "")),
+			want: kerrors.NewInvalid(gk, "cool-hook", field.ErrorList{
+				field.Forbidden(wp, "only one of url, urlTemplate and urlSecretRef may be set"),
+			}),
+		},
+		"Both": {
+			hook: webhook("https://example.com", "https://ci.example.com/{{ .RepoSlug }}"),
+			want: kerrors.NewInvalid(gk, "cool-hook", field.ErrorList{
+				field.Forbidden(wp, "only one of url, urlTemplate and urlSecretRef may be set"),
+			}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.hook.ValidateCreate()
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ValidateCreate(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
+
+func deleted(a *Webhook) *Webhook {
+	now := metav1.NewTime(time.Unix(1, 0))
+	a.SetDeletionTimestamp(&now)
+	return a
+}
+
+func TestValidateUpdate(t *testing.T) {
+	gk := schema.GroupKind{Group: Group, Kind: WebhookKind}
+	wp := field.NewPath("spec", "forProvider", "webhook")
+
+	cases := map[string]struct {
+		old  *Webhook
+		hook *Webhook
+		want error
+	}{
+		"URLChanged": {
+			old:  webhook("https://example.com", ""),
+			hook: webhook("https://example.org", ""),
+		},
+		"InvalidURL": {
+			old:  webhook("https://example.com", ""),
+			hook: webhook("https://example.com", "https://ci.example.com/{{ .RepoSlug }}"),
+			want: kerrors.NewInvalid(gk, "cool-hook", field.ErrorList{
+				field.Forbidden(wp, "only one of url, urlTemplate and urlSecretRef may be set"),
+			}),
+		},
+		"LegacyUnchanged": {
+			old:  webhook("", ""),
+			hook: webhook("", ""),
+		},
+		"LegacyDeleted": {
+			old:  webhook("", "https://ci.example.com/{{ .Repo }}"),
+			hook: deleted(webhook("", "https://ci.example.com/{{ .Repo }}")),
+		},
+		"ImmutableField": {
+			old: webhook("https://example.com", ""),
+			hook: func() *Webhook {
+				a := webhook("https://example.com", "")
+				a.Spec.ForProvider.ProjectKey = "OTHER"
+				return a
+			}(),
+			want: kerrors.NewInvalid(gk, "cool-hook", field.ErrorList{
+				field.Invalid(field.NewPath("spec", "forProvider", "projectKey"), "OTHER", "field is immutable"),
+			}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.hook.ValidateUpdate(tc.old)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ValidateUpdate(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
@@ -22,6 +22,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane/crossplane-runtime/apis/common/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -31,13 +33,18 @@ func (in *BitbucketWebhook) DeepCopyInto(out *BitbucketWebhook) {
 	if in.Configuration != nil {
 		in, out := &in.Configuration, &out.Configuration
 		*out = new(BitbucketWebhookConfiguration)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Events != nil {
 		in, out := &in.Events, &out.Events
 		*out = make([]Event, len(*in))
 		copy(*out, *in)
 	}
+	if in.URLSecretRef != nil {
+		in, out := &in.URLSecretRef, &out.URLSecretRef
+		*out = new(v1.SecretKeySelector)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BitbucketWebhook.
@@ -53,6 +60,13 @@ func (in *BitbucketWebhook) DeepCopy() *BitbucketWebhook {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BitbucketWebhookConfiguration) DeepCopyInto(out *BitbucketWebhookConfiguration) {
 	*out = *in
+	if in.Extra != nil {
+		in, out := &in.Extra, &out.Extra
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BitbucketWebhookConfiguration.
@@ -65,6 +79,21 @@ func (in *BitbucketWebhookConfiguration) DeepCopy() *BitbucketWebhookConfigurati
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryObservation) DeepCopyInto(out *RepositoryObservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryObservation.
+func (in *RepositoryObservation) DeepCopy() *RepositoryObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Webhook) DeepCopyInto(out *Webhook) {
 	*out = *in
@@ -92,6 +121,26 @@ func (in *Webhook) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookInitParameters) DeepCopyInto(out *WebhookInitParameters) {
+	*out = *in
+	if in.Configuration != nil {
+		in, out := &in.Configuration, &out.Configuration
+		*out = new(BitbucketWebhookConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookInitParameters.
+func (in *WebhookInitParameters) DeepCopy() *WebhookInitParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookInitParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebhookList) DeepCopyInto(out *WebhookList) {
 	*out = *in
@@ -127,6 +176,24 @@ func (in *WebhookList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebhookObservation) DeepCopyInto(out *WebhookObservation) {
 	*out = *in
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CreatedAt != nil {
+		in, out := &in.CreatedAt, &out.CreatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.UpdatedAt != nil {
+		in, out := &in.UpdatedAt, &out.UpdatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Repository != nil {
+		in, out := &in.Repository, &out.Repository
+		*out = new(RepositoryObservation)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookObservation.
@@ -160,6 +227,12 @@ func (in *WebhookSpec) DeepCopyInto(out *WebhookSpec) {
 	*out = *in
 	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
 	in.ForProvider.DeepCopyInto(&out.ForProvider)
+	in.InitProvider.DeepCopyInto(&out.InitProvider)
+	if in.ManagementPolicies != nil {
+		in, out := &in.ManagementPolicies, &out.ManagementPolicies
+		*out = make(apisv1alpha1.ManagementPolicies, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookSpec.
@@ -176,7 +249,7 @@ func (in *WebhookSpec) DeepCopy() *WebhookSpec {
 func (in *WebhookStatus) DeepCopyInto(out *WebhookStatus) {
 	*out = *in
 	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
-	out.AtProvider = in.AtProvider
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookStatus.
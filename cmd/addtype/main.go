@@ -0,0 +1,48 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/scaffold"
+)
+
+func main() {
+	var (
+		app       = kingpin.New(filepath.Base(os.Args[0]), "Scaffolds the API types, client, fake, controller, tests and example of a new kind of managed resources.").DefaultEnvars()
+		kind      = app.Flag("kind", "Name of the kind in PascalCase, e.g. BranchRestriction.").Required().String()
+		group     = app.Flag("group", "API group of the kind, e.g. branchrestriction. Defaults to the kind in lower case.").String()
+		templates = app.Flag("templates", "Directory of the templates.").Default(scaffold.DefaultTemplates).ExistingDir()
+		root      = app.Flag("root", "Root directory of the repository.").Default(".").ExistingDir()
+	)
+	kingpin.MustParse(app.Parse(os.Args[1:]))
+
+	k := scaffold.Kind{Name: *kind, Group: *group}
+	paths, err := scaffold.Generate(*templates, *root, k)
+	kingpin.FatalIfError(err, "Cannot scaffold %s", *kind)
+	for _, p := range paths {
+		fmt.Println("Created", p)
+	}
+	steps, err := scaffold.NextSteps(*templates, k)
+	kingpin.FatalIfError(err, "Cannot print next steps")
+	fmt.Print("\n", steps)
+}
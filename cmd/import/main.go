@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis"
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/config"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/importer"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+)
+
+func main() {
+	var (
+		app         = kingpin.New(filepath.Base(os.Args[0]), "Prints managed resources for the webhooks and access keys of an existing Bitbucket server as YAML, with external names set to adopt them.").DefaultEnvars()
+		debug       = app.Flag("debug", "Run with debug logging.").Short('d').Bool()
+		pcName      = app.Flag("provider-config", "Name of the ProviderConfig to connect with, which the managed resources refer to.").Default("default").String()
+		observeOnly = app.Flag("observe-only", "Only observe the imported resources by setting their management policies to Observe.").Bool()
+		projects    = app.Arg("project", "Keys of the projects to import. Defaults to all projects the credentials can see.").Strings()
+	)
+	kingpin.MustParse(app.Parse(os.Args[1:]))
+
+	zl := zap.New(zap.UseDevMode(*debug), zap.WriteTo(os.Stderr))
+	log := logging.NewLogrLogger(zl.WithName("import"))
+
+	cfg, err := ctrl.GetConfig()
+	kingpin.FatalIfError(err, "Cannot get API server rest config")
+	s := runtime.NewScheme()
+	kingpin.FatalIfError(clientgoscheme.AddToScheme(s), "Cannot add Kubernetes APIs to scheme")
+	kingpin.FatalIfError(apis.AddToScheme(s), "Cannot add APIs to scheme")
+	kube, err := client.New(cfg, client.Options{Scheme: s})
+	kingpin.FatalIfError(err, "Cannot create client")
+
+	ctx := context.Background()
+	pc := &v1alpha1.ProviderConfig{}
+	kingpin.FatalIfError(kube.Get(ctx, types.NamespacedName{Name: *pcName}, pc), "Cannot get ProviderConfig")
+	cc, err := config.ClientConfig(ctx, kube, pc)
+	kingpin.FatalIfError(err, "Cannot configure Bitbucket client")
+
+	o := []importer.Option{importer.WithProviderConfig(*pcName)}
+	if *observeOnly {
+		o = append(o, importer.WithObserveOnly())
+	}
+	mgs, err := importer.NewImporter(clients.NewClient(cc), log, o...).Import(ctx, *projects...)
+	kingpin.FatalIfError(err, "Cannot import")
+	kingpin.FatalIfError(importer.Write(os.Stdout, mgs), "Cannot write managed resources")
+}
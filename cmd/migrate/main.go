@@ -0,0 +1,68 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/migration"
+)
+
+func main() {
+	var (
+		app   = kingpin.New(filepath.Base(os.Args[0]), "Rewrites the stored objects of the provider in the storage version of their CustomResourceDefinitions.").DefaultEnvars()
+		debug = app.Flag("debug", "Run with debug logging.").Short('d').Bool()
+		crds  = app.Arg("crd", "Names of the CustomResourceDefinitions to migrate. Defaults to all of the provider.").Strings()
+		names = app.Flag("external-names", "Rewrite the external names of AccessKeys which are bare IDs to PROJECT/repo/ID and check the external names of all resources, instead of migrating API versions.").Bool()
+	)
+	kingpin.MustParse(app.Parse(os.Args[1:]))
+
+	zl := zap.New(zap.UseDevMode(*debug))
+	log := logging.NewLogrLogger(zl.WithName("migrate"))
+
+	cfg, err := ctrl.GetConfig()
+	kingpin.FatalIfError(err, "Cannot get API server rest config")
+	s := runtime.NewScheme()
+	kingpin.FatalIfError(apis.AddToScheme(s), "Cannot add APIs to scheme")
+	c, err := client.New(cfg, client.Options{Scheme: s})
+	kingpin.FatalIfError(err, "Cannot create client")
+
+	m := migration.NewMigrator(c, log)
+	ctx := context.Background()
+	if *names {
+		kingpin.FatalIfError(m.MigrateExternalNames(ctx), "Cannot migrate external names")
+		return
+	}
+	if len(*crds) == 0 {
+		kingpin.FatalIfError(m.MigrateAll(ctx), "Cannot migrate")
+		return
+	}
+	for _, crd := range *crds {
+		kingpin.FatalIfError(m.Migrate(ctx, crd), "Cannot migrate")
+	}
+}
@@ -17,28 +17,74 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
 	"gopkg.in/alecthomas/kingpin.v2"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
 
 	"github.com/crossplane-contrib/provider-bitbucket-server/apis"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clock"
 	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/config"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/configcache"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/listcache"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/preflight"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/receiver"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/setup"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/throttle"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/features"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/generate"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/metrics"
 )
 
 func main() {
 	var (
-		app            = kingpin.New(filepath.Base(os.Args[0]), "Template support for Crossplane.").DefaultEnvars()
-		debug          = app.Flag("debug", "Run with debug logging.").Short('d').Bool()
-		syncPeriod     = app.Flag("sync", "Controller manager sync period such as 300ms, 1.5h, or 2h45m").Short('s').Default("1h").Duration()
-		leaderElection = app.Flag("leader-election", "Use leader election for the controller manager.").Short('l').Default("false").OverrideDefaultFromEnvar("LEADER_ELECTION").Bool()
+		app              = kingpin.New(filepath.Base(os.Args[0]), "Template support for Crossplane.").DefaultEnvars()
+		debug            = app.Flag("debug", "Run with debug logging.").Short('d').Bool()
+		kubeconfig       = app.Flag("kubeconfig", "Path to the kubeconfig of the cluster to reconcile, e.g. to run the provider on a workstation against a remote cluster. Defaults to $KUBECONFIG, the service account of the pod in-cluster or ~/.kube/config.").Default("").String()
+		namespace        = app.Flag("namespace", "Namespace the provider runs in, the default of --leader-election-namespace. Detected in-cluster, set it to use leader election out of cluster.").Envar("POD_NAMESPACE").Default("").String()
+		debugHTTP        = app.Flag("debug-http", "Log all requests to and responses of the Bitbucket API. Credentials and secrets are redacted.").Bool()
+		warnFields       = app.Flag("warn-unknown-fields", "Log the fields of responses of the Bitbucket API which the provider doesn't know, which hint at a change of the API.").Bool()
+		deliveryMetrics  = app.Flag("webhook-delivery-metrics", "Export the recent deliveries of every webhook as metrics, which takes one more request per poll of a webhook.").Bool()
+		syncPeriod       = app.Flag("sync-period", "Controller manager sync period such as 300ms, 1.5h, or 2h45m. Managed resources are not reconciled by the resync, see --poll.").Short('s').Default("1h").Duration()
+		syncDeprecated   = app.Flag("sync", "Deprecated: use --sync-period.").Hidden().Duration()
+		leaderElection   = app.Flag("leader-election", "Use leader election for the controller manager.").Short('l').Default("false").OverrideDefaultFromEnvar("LEADER_ELECTION").Bool()
+		leaderElectionNS = app.Flag("leader-election-namespace", "Namespace of the leader election lock. Defaults to the namespace the provider runs in.").Default("").String()
+		leaderElectionID = app.Flag("leader-election-id", "Name of the leader election lock.").Default("crossplane-leader-election-provider-bitbucket-server").String()
+		pollInterval     = app.Flag("poll", "Poll interval controls how often an individual resource should be checked for drift.").Default("1m").Duration()
+		pollJitter       = app.Flag("poll-jitter", "Longest random delay added to each poll, and to the first reconcile of existing resources after a start, so that they are not all observed at once. No delay when 0.").Default("30s").Duration()
+		timeout          = app.Flag("timeout", "Timeout of a single reconcile of a managed resource. Increase it for slow Bitbucket instances.").Default("1m").Duration()
+		maxReconcileRate = app.Flag("max-reconcile-rate", "The global maximum rate per second at which resources may be checked for drift from the desired state.").Default("1").Int()
+		maxConcurrency   = app.Flag("max-concurrent-reconciles", "The maximum number of resources each controller reconciles concurrently.").Default("1").Int()
+		enableMgmtPolicy = app.Flag("enable-management-policies", "Restrict the operations on external resources to spec.managementPolicies. When disabled, managed resources with other than the default policies fail to reconcile.").Default("false").Bool()
+		healthProbeAddr  = app.Flag("health-probe-bind-address", "Address of the /healthz and /readyz endpoints, e.g. :8081. The endpoints are disabled when empty.").Default("").String()
+		readinessPC      = app.Flag("readiness-provider-config", "Name of a ProviderConfig whose Bitbucket server must be reachable with its credentials for the provider to be ready.").Default("").String()
+		webhookCertDir   = app.Flag("webhook-tls-cert-dir", "Directory of the tls.crt and tls.key of the admission webhook server. The webhooks are disabled when empty.").Default("").String()
+		receiverAddr     = app.Flag("event-receiver-address", "Address of the receiver of the events of Bitbucket webhooks, e.g. :8090, which reconciles the managed resources of a repository when it changes. The receiver is disabled when empty.").Default("").String()
+		receiverSecret   = app.Flag("event-receiver-secret", "Secret the Bitbucket webhooks sign their events with. Events without a valid signature are rejected. Required by the receiver unless --event-receiver-insecure is set.").Envar("EVENT_RECEIVER_SECRET").Default("").String()
+		receiverInsecure = app.Flag("event-receiver-insecure", "Accept events of Bitbucket webhooks without verifying their signature when no --event-receiver-secret is set.").Default("false").Bool()
+		listCacheTTL     = app.Flag("list-cache-ttl", "Observe the webhooks and access keys of a repository with one list request, which is cached this long, instead of one request per resource, e.g. 30s. Every resource is observed with its own request when 0.").Default("0").Duration()
+		preflightTTL     = app.Flag("preflight-permissions-ttl", "Check that the credentials have the admin permission on the repository of a webhook or access key when connecting, and skip the check for this long after it passed, e.g. 10m. Not checked when 0.").Default("0").Duration()
+		configCacheTTL   = app.Flag("config-cache-ttl", "Keep the client configuration and credentials of a ProviderConfig this long between reconciles. Changes of the ProviderConfig or its Secret take effect right away. Read on every reconcile when 0.").Default("5m").Duration()
 	)
 	kingpin.MustParse(app.Parse(os.Args[1:]))
+	if *syncDeprecated != 0 {
+		syncPeriod = syncDeprecated
+	}
+	if *leaderElectionNS == "" {
+		leaderElectionNS = namespace
+	}
 
 	zl := zap.New(zap.UseDevMode(*debug))
 	log := logging.NewLogrLogger(zl.WithName("provider-bitbucket-server"))
@@ -49,20 +95,101 @@ func main() {
 		ctrl.SetLogger(zl)
 	}
 
-	log.Debug("Starting", "sync-period", syncPeriod.String())
+	log.Debug("Starting", "sync-period", syncPeriod.String(), "poll-interval", pollInterval.String(), "poll-jitter", pollJitter.String(), "max-reconcile-rate", *maxReconcileRate, "max-concurrent-reconciles", *maxConcurrency)
 
-	cfg, err := ctrl.GetConfig()
+	cfg, err := restConfig(*kubeconfig)
 	kingpin.FatalIfError(err, "Cannot get API server rest config")
+	log.Debug("Connecting to API server", "host", cfg.Host, "namespace", *namespace)
 
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
-		LeaderElection:   *leaderElection,
-		LeaderElectionID: "crossplane-leader-election-provider-bitbucket-server",
-		SyncPeriod:       syncPeriod,
+		LeaderElection:          *leaderElection,
+		LeaderElectionID:        *leaderElectionID,
+		LeaderElectionNamespace: *leaderElectionNS,
+		// Step down on shutdown so another replica takes over right away
+		// instead of waiting for the lease to expire.
+		LeaderElectionReleaseOnCancel: true,
+		SyncPeriod:                    syncPeriod,
+		CertDir:                       *webhookCertDir,
+		HealthProbeBindAddress:        *healthProbeAddr,
 	})
 	kingpin.FatalIfError(err, "Cannot create controller manager")
 
-	rl := ratelimiter.NewDefaultProviderRateLimiter(ratelimiter.DefaultProviderRPS)
+	kingpin.FatalIfError(mgr.AddHealthzCheck("ping", healthz.Ping), "Cannot add health check")
+	kingpin.FatalIfError(mgr.AddReadyzCheck("ping", healthz.Ping), "Cannot add readiness check")
+	if *readinessPC != "" {
+		// The cache of the manager is not started when the first probes
+		// arrive, so the check reads from the API server.
+		kube, err := client.New(cfg, client.Options{Scheme: mgr.GetScheme()})
+		kingpin.FatalIfError(err, "Cannot create client")
+		kingpin.FatalIfError(mgr.AddReadyzCheck("bitbucket", config.NewReadinessCheck(kube, *readinessPC)), "Cannot add readiness check")
+	}
+
+	ff := &features.Flags{}
+	if *enableMgmtPolicy {
+		ff.Enable(features.EnableManagementPolicies)
+		log.Debug("Feature enabled", "flag", features.EnableManagementPolicies)
+	}
+
+	o := setup.Options{
+		Logger:                  log,
+		GlobalRateLimiter:       metrics.NewRateLimiter(ratelimiter.NewDefaultProviderRateLimiter(*maxReconcileRate)),
+		MaxConcurrentReconciles: *maxConcurrency,
+		PollInterval:            *pollInterval,
+		PollJitter:              *pollJitter,
+		Timeout:                 *timeout,
+		DebugHTTP:               *debugHTTP,
+		WarnUnknownFields:       *warnFields,
+		WebhookDeliveries:       *deliveryMetrics,
+		Throttle:                throttle.NewGate(clock.System),
+		Passwords:               generate.RandomPasswords,
+		Keys:                    generate.ED25519Keys,
+		Features:                ff,
+	}
+	if *listCacheTTL > 0 {
+		o.ListCache = listcache.New(*listCacheTTL, clock.System)
+	}
+	if *configCacheTTL > 0 {
+		o.ConfigCache = configcache.New(*configCacheTTL, clock.System)
+	}
+	if *preflightTTL > 0 {
+		o.Preflight = preflight.New(*preflightTTL, clock.System)
+	}
+	if *receiverAddr != "" {
+		if *receiverSecret == "" && !*receiverInsecure {
+			kingpin.Fatalf("--event-receiver-address requires --event-receiver-secret, or --event-receiver-insecure to accept unsigned events")
+		}
+		ro := []receiver.Option{receiver.WithSecret(*receiverSecret)}
+		if *receiverInsecure {
+			ro = append(ro, receiver.WithInsecure())
+		}
+		o.Receiver = receiver.New(mgr.GetClient(), log, ro...)
+		kingpin.FatalIfError(mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			return o.Receiver.Start(ctx, *receiverAddr)
+		})), "Cannot add event receiver")
+	}
 	kingpin.FatalIfError(apis.AddToScheme(mgr.GetScheme()), "Cannot add Template APIs to scheme")
-	kingpin.FatalIfError(controller.Setup(mgr, log, rl), "Cannot setup Template controllers")
+	kingpin.FatalIfError(controller.Setup(mgr, o), "Cannot setup Template controllers")
+	if *webhookCertDir != "" {
+		kingpin.FatalIfError(controller.SetupWebhooks(mgr), "Cannot setup webhooks")
+	}
 	kingpin.FatalIfError(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
 }
+
+// restConfig returns the configuration of the API server of the kubeconfig
+// at the path, or the configuration the controller-runtime detects when the
+// path is empty.
+func restConfig(path string) (*rest.Config, error) {
+	if path == "" {
+		return ctrl.GetConfig()
+	}
+	cfg, err := clientcmd.BuildConfigFromFlags("", path)
+	if err != nil {
+		return nil, err
+	}
+	// The same limits the controller-runtime sets for the other sources.
+	if cfg.QPS == 0 {
+		cfg.QPS = 20
+		cfg.Burst = 30
+	}
+	return cfg, nil
+}
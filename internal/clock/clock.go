@@ -0,0 +1,42 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clock provides the time to the controllers as an interface, so
+// that tests can control it.
+package clock
+
+import "time"
+
+// A Clock tells the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// A ClockFn is a function that satisfies the Clock interface
+type ClockFn func() time.Time
+
+// Now returns the result of calling the function
+func (fn ClockFn) Now() time.Time {
+	return fn()
+}
+
+// System is the clock of the operating system
+var System Clock = ClockFn(time.Now)
+
+// Fixed returns a clock which always tells the time t
+func Fixed(t time.Time) Clock {
+	return ClockFn(func() time.Time { return t })
+}
@@ -17,72 +17,115 @@ limitations under the License.
 package accesskey
 
 import (
-	"bytes"
 	"context"
-	"crypto/ed25519"
-	"crypto/rand"
-	"encoding/pem"
-	"fmt"
-	"strconv"
+	"strings"
+	"time"
 
-	"github.com/mikesmitty/edkey"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
-	"golang.org/x/crypto/ssh"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
-	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
-	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane-contrib/provider-bitbucket-server/apis/accesskey/v1alpha1"
 	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
-	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clients"
-	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/apierror"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/conditions"
 	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/config"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/configcache"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/deletionprotection"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/dependents"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/drift"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/externalname"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/filter"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/lateinit"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/listcache"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/managementpolicy"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/moved"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/orphan"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/preflight"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/setup"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/features"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/generate"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/metrics"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
 )
 
 const (
 	errNotAccessKey = "managed resource is not a AccessKey custom resource"
 	errTrackPCUsage = "cannot track ProviderConfig usage"
 	errGetPC        = "cannot get ProviderConfig"
-	errGetCreds     = "cannot get credentials"
 
 	errGetFailed    = "cannot get access key from bitbucket API"
 	errDeleteFailed = "cannot delete access key from bitbucket API"
 	errCreateFailed = "cannot create access key with bitbucket API"
 	errUpdateFailed = "cannot update access permission key with bitbucket API"
+	errListFailed   = "cannot list access keys with bitbucket API"
+
+	errCleanupGeneratedKey = "cannot tell which access key a create with a generated key left behind: delete it in Bitbucket, then remove the " + meta.AnnotationKeyExternalCreatePending + " annotation"
+
+	errExternalName = "%q is neither an ID nor PROJECT/repo/ID of an access key of the repository"
 )
 
 // Setup adds a controller that reconciles AccessKey managed resources.
-func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter) error {
+func Setup(mgr ctrl.Manager, o setup.Options) error {
 	name := managed.ControllerName(v1alpha1.AccessKeyGroupKind)
-
-	o := controller.Options{
-		RateLimiter: ratelimiter.NewDefaultManagedRateLimiter(rl),
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	c := &connector{
+		kube:         mgr.GetClient(),
+		recorder:     recorder,
+		usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+		newServiceFn: clients.NewAccessKeyClient,
+		newReposFn:   clients.NewRepositoryClient,
+		httpLog:      o.HTTPLogger(name),
+		clientLog:    o.ClientLogger(name),
+		warnFields:   o.WarnUnknownFields,
+		configs:      o.ConfigCache,
+		cache:        o.ListCache,
+		keys:         o.Keys,
+
+		preflight:       o.Preflight,
+		newPermissionFn: clients.NewPermissionClient,
 	}
+	var conn managed.ExternalConnecter = deletionprotection.NewConnecter(conditions.NewConnecter(c))
+	conn = managementpolicy.NewConnecter(conn, o.Features.Enabled(features.EnableManagementPolicies))
 
-	r := managed.NewReconciler(mgr,
+	errs := apierror.NewTracker()
+	r := managed.NewReconciler(errs.Manager(mgr),
 		resource.ManagedKind(v1alpha1.AccessKeyGroupVersionKind),
-		managed.WithExternalConnecter(&connector{
-			kube:         mgr.GetClient(),
-			usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
-			newServiceFn: clients.NewAccessKeyClient}),
-		managed.WithLogger(l.WithValues("controller", name)),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
-
-	return ctrl.NewControllerManagedBy(mgr).
+		managed.WithExternalConnecter(errs.NewConnecter(conn)),
+		managed.WithConnectionPublishers(&publisher{
+			ConnectionPublisher: managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme()),
+			secret:              resource.NewAPIPatchingApplicator(mgr.GetClient()),
+		}),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(o.Timeout),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(recorder))
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		Named(name).
-		WithOptions(o).
-		For(&v1alpha1.AccessKey{}).
-		Complete(r)
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.AccessKey{}, builder.WithPredicates(filter.Changes()))
+	b, err := dependents.Watch(mgr, b, o, &v1alpha1.AccessKey{}, func() resource.ManagedList { return &v1alpha1.AccessKeyList{} }, nil)
+	if err != nil {
+		return err
+	}
+	if o.Receiver != nil {
+		b = b.Watches(o.Receiver.Register(&v1alpha1.AccessKeyList{}), &handler.EnqueueRequestForObject{})
+	}
+	return b.Complete(o.Reconciler(mgr, v1alpha1.AccessKeyGroupVersionKind, orphan.NewReconciler(mgr, resource.ManagedKind(v1alpha1.AccessKeyGroupVersionKind), c, recorder, r)))
 }
 
 // A connector is expected to produce an ExternalClient when its Connect method
@@ -90,7 +133,20 @@ func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter) error {
 type connector struct {
 	kube         client.Client
 	usage        resource.Tracker
+	recorder     event.Recorder
 	newServiceFn func(clients.Config) bitbucket.KeyClientAPI
+	newReposFn   func(clients.Config) bitbucket.RepositoryClientAPI
+	httpLog      logging.Logger
+	clientLog    logging.Logger
+	warnFields   bool
+	configs      *configcache.Cache
+	cache        *listcache.Cache
+	keys         generate.Keys
+
+	// preflight checks the permissions of the credentials on the
+	// repository before the access key is observed or changed
+	preflight       *preflight.Checker
+	newPermissionFn func(clients.Config) bitbucket.PermissionClientAPI
 }
 
 // Connect typically produces an ExternalClient by:
@@ -113,19 +169,19 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 		return nil, errors.Wrap(err, errGetPC)
 	}
 
-	cd := pc.Spec.Credentials
-	data, err := resource.CommonCredentialExtractor(ctx, cd.Source, c.kube, cd.CommonCredentialSelectors)
+	cfg, err := c.configs.ClientConfig(ctx, c.kube, pc, config.ClientConfig)
 	if err != nil {
-		return nil, errors.Wrap(err, errGetCreds)
+		return nil, err
 	}
+	cfg.HTTPLogger = c.httpLog
+	cfg.Logger = c.clientLog
+	cfg.WarnUnknownFields = c.warnFields
+	if err := c.preflight.RepoAdmin(ctx, c.newPermissionFn(cfg), pc.GetName(), cr.Repo()); err != nil {
+		return nil, err
+	}
+	svc := c.cache.AccessKeys(c.newServiceFn(cfg), pc.GetName())
 
-	svc := c.newServiceFn(clients.Config{
-		BaseURL:   pc.Spec.BaseURL,
-		Token:     string(data),
-		TLSConfig: config.NewTLSConfig(*pc),
-	})
-
-	return &external{service: svc, keygen: keygen}, nil
+	return &external{service: svc, repos: c.newReposFn(cfg), recorder: c.recorder, keys: c.keys}, nil
 }
 
 // An ExternalClient observes, then either creates, updates, or deletes an
@@ -134,7 +190,10 @@ type external struct {
 	// A 'client' used to connect to the external resource API. In practice this
 	// would be something like an AWS SDK client.
 	service bitbucket.KeyClientAPI
-	keygen  func() (string, []byte, error)
+	// repos tells whether the repository of a missing access key was moved
+	repos    bitbucket.RepositoryClientAPI
+	recorder event.Recorder
+	keys     generate.Keys
 }
 
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
@@ -147,27 +206,33 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, nil
 	}
 
-	externalName := meta.GetExternalName(cr)
-	id, err := strconv.Atoi(externalName)
+	id, ok, err := externalID(cr)
 	if err != nil {
-		return managed.ExternalObservation{}, nil // nolint // This is ok as it does not exists
+		return managed.ExternalObservation{}, err
+	}
+	if !ok {
+		return managed.ExternalObservation{}, nil
 	}
 
 	key, err := c.service.GetAccessKey(ctx, cr.Repo(), id)
 	if err != nil {
 		if errors.Is(err, bitbucket.ErrNotFound) {
-			return managed.ExternalObservation{}, nil
+			return managed.ExternalObservation{}, c.checkRepository(ctx, cr)
 		}
 		return managed.ExternalObservation{}, errors.Wrap(err, errGetFailed)
 	}
 
-	cr.Status.SetConditions(xpv1.Available())
+	observe(cr, key)
 
-	cr.Status.AtProvider.ID = key.ID
-	cr.Status.AtProvider.Key = &v1alpha1.PublicKey{
-		Key:        key.Key,
-		Label:      key.Label,
-		Permission: key.Permission,
+	resourceLateInitialized := lateinit.String(&cr.Spec.ForProvider.PublicKey.Key, key.Key)
+
+	// Only the permission of an access key can be updated.
+	ignoreImmutable := cmpopts.IgnoreFields(bitbucket.AccessKey{}, "ID", "Key", "Label", "Repository")
+	diff := cmp.Diff(cr.AccessKey(), key, ignoreImmutable)
+	if diff != "" {
+		fields := drift.Fields(cr.AccessKey(), key, ignoreImmutable)
+		metrics.RecordDrift(v1alpha1.AccessKeyKind, fields)
+		c.recorder.Event(cr, drift.Event(fields))
 	}
 
 	return managed.ExternalObservation{
@@ -179,7 +244,11 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		// Return false when the external resource exists, but it not up to date
 		// with the desired managed resource state. This lets the managed
 		// resource reconciler know that it needs to call Update.
-		ResourceUpToDate: key.Permission == cr.Spec.ForProvider.PublicKey.Permission,
+		ResourceUpToDate: diff == "",
+
+		ResourceLateInitialized: resourceLateInitialized,
+
+		Diff: diff,
 
 		// Return any details that may be required to connect to the external
 		// resource. These will be stored as the connection secret.
@@ -193,41 +262,55 @@ func (c *external) create(ctx context.Context, cr *v1alpha1.AccessKey) error {
 		return err
 	}
 
-	meta.SetExternalName(cr, fmt.Sprint(key.ID))
-	cr.Status.SetConditions(xpv1.Available())
+	meta.SetExternalName(cr, externalname.RepoName(cr.Repo().ProjectKey, cr.RepoSlug(), key.ID))
+	observe(cr, key)
+	return nil
+}
+
+// observe records the access key as observed in the status of the access
+// key. The repository is kept when the server omits it.
+func observe(cr *v1alpha1.AccessKey, key bitbucket.AccessKey) {
 	cr.Status.AtProvider.ID = key.ID
 	cr.Status.AtProvider.Key = &v1alpha1.PublicKey{
 		Key:        key.Key,
 		Label:      key.Label,
 		Permission: key.Permission,
 	}
-	return nil
-}
-
-func keygen() (string, []byte, error) {
-	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
-	if err != nil {
-		return "", nil, err
+	if r := key.Repository; r != nil {
+		cr.Status.AtProvider.Repository = &v1alpha1.RepositoryObservation{
+			ID:           r.ID,
+			Slug:         r.Slug,
+			HTTPCloneURL: r.CloneURLs["http"],
+			SSHCloneURL:  r.CloneURLs["ssh"],
+		}
 	}
-	privateBytes := edkey.MarshalED25519PrivateKey(privateKey)
+}
 
-	// generate encoded private key pem
-	privateKeyPEM := &pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: privateBytes}
-	if err != nil {
-		return "", nil, err
-	}
-	var private bytes.Buffer
-	if err := pem.Encode(&private, privateKeyPEM); err != nil {
-		return "", nil, err
+// checkRepository returns an error if the repository the access key was
+// created in was renamed, moved or deleted, instead of creating the access key
+// again. Access keys being deleted are not checked, so that they can be
+// deleted.
+func (c *external) checkRepository(ctx context.Context, cr *v1alpha1.AccessKey) error {
+	r := cr.Status.AtProvider.Repository
+	if r == nil || meta.WasDeleted(cr) {
+		return nil
 	}
+	return moved.Check(ctx, c.repos, cr.Repo(), r.ID)
+}
 
-	// generate ssh authorized public key
-	sshPublicKey, err := ssh.NewPublicKey(publicKey)
-	if err != nil {
-		return "", nil, err
+// externalID returns the ID of the access key from its external name, which
+// is either the bare ID of access keys created by earlier versions of the
+// provider or PROJECT/repo/ID, where repo is the name or the slug of the
+// repository. It returns false if the access key is not created yet, and an
+// error for any other external name which is no ID, which would otherwise
+// create a duplicate.
+func externalID(cr *v1alpha1.AccessKey) (int, bool, error) {
+	name := meta.GetExternalName(cr)
+	id, ok, err := externalname.RepoID(name, cr.Spec.ForProvider.ProjectKey, cr.Spec.ForProvider.RepoName, cr.RepoSlug())
+	if err != nil || (!ok && !externalname.Pending(cr)) {
+		return 0, false, errors.Wrapf(externalname.ErrInvalid, errExternalName, name)
 	}
-	authorizedKey := ssh.MarshalAuthorizedKey(sshPublicKey)
-	return string(authorizedKey), private.Bytes(), nil
+	return id, ok, nil
 }
 
 func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
@@ -236,23 +319,21 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalCreation{}, errors.New(errNotAccessKey)
 	}
 
-	cr.Status.SetConditions(xpv1.Creating())
 	conndetails := managed.ConnectionDetails{}
 
 	if cr.Spec.ForProvider.PublicKey.Key == "" {
 		var err error
 		var privateKey []byte
-		cr.Spec.ForProvider.PublicKey.Key, privateKey, err = c.keygen()
+		cr.Spec.ForProvider.PublicKey.Key, privateKey, err = c.keys.SSHKey()
 		if err != nil {
 			return managed.ExternalCreation{}, err
 		}
-		conndetails["ssh-privatekey"] = privateKey
+		conndetails[v1alpha1.ConnectionPrivateKeyKey] = privateKey
 	}
 	if err := c.create(ctx, cr); err != nil {
 		return managed.ExternalCreation{}, errors.Wrap(err, errCreateFailed)
 	}
-
-	cr.Status.SetConditions(xpv1.Available())
+	conndetails[v1alpha1.ConnectionPublicKeyKey] = []byte(cr.Spec.ForProvider.PublicKey.Key)
 
 	return managed.ExternalCreation{
 		// Optionally return any details that may be required to connect to the
@@ -268,7 +349,13 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalUpdate{}, errors.New(errNotAccessKey)
 	}
 
-	id, _ := strconv.Atoi(meta.GetExternalName(cr))
+	id, ok, err := externalID(cr)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	if !ok {
+		return managed.ExternalUpdate{}, errors.Wrapf(externalname.ErrInvalid, errExternalName, meta.GetExternalName(cr))
+	}
 	if err := c.service.UpdateAccessKeyPermission(ctx, cr.Repo(), id, cr.Spec.ForProvider.PublicKey.Permission); err != nil {
 		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateFailed)
 	}
@@ -286,12 +373,65 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 		return errors.New(errNotAccessKey)
 	}
 
-	id, _ := strconv.Atoi(meta.GetExternalName(cr)) // TODO err
-	if err := c.service.DeleteAccessKey(ctx, cr.Repo(), id); err != nil {
+	// Observe reports access keys without an ID as missing, so there is
+	// nothing to delete.
+	id, ok, err := externalID(cr)
+	if err != nil || !ok {
+		return err
+	}
+	if err := c.service.DeleteAccessKey(ctx, cr.Repo(), id); err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
 		return errors.Wrap(err, errDeleteFailed)
 	}
 
-	cr.Status.SetConditions(xpv1.Deleting())
-
 	return nil
 }
+
+// Cleanup deletes the access key with the public key of the managed resource,
+// which a create whose external name was not recorded left behind. Access
+// keys don't tell when they were created, so a generated key, which is only
+// recorded once the create succeeded, cannot be told apart from others.
+func (c *connector) Cleanup(ctx context.Context, mg resource.Managed, _ time.Time) (int, error) {
+	cr, ok := mg.(*v1alpha1.AccessKey)
+	if !ok {
+		return 0, errors.New(errNotAccessKey)
+	}
+	if cr.Spec.ForProvider.PublicKey.Key == "" {
+		return 0, errors.New(errCleanupGeneratedKey)
+	}
+	ec, err := c.Connect(ctx, cr)
+	if err != nil {
+		return 0, err
+	}
+	return ec.(*external).cleanup(ctx, cr)
+}
+
+func (c *external) cleanup(ctx context.Context, cr *v1alpha1.AccessKey) (int, error) {
+	keys, err := c.service.ListAccessKeys(ctx, cr.Repo(), bitbucket.AccessKeyFilter{})
+	if errors.Is(err, bitbucket.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, errListFailed)
+	}
+	n := 0
+	for _, k := range keys {
+		if !sameKey(k.Key, cr.Spec.ForProvider.PublicKey.Key) {
+			continue
+		}
+		if err := c.service.DeleteAccessKey(ctx, cr.Repo(), k.ID); err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+			return n, errors.Wrap(err, errDeleteFailed)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// sameKey returns true if the public keys have the same type and key data,
+// regardless of their comments.
+func sameKey(a, b string) bool {
+	fa, fb := strings.Fields(a), strings.Fields(b)
+	if len(fa) < 2 || len(fb) < 2 {
+		return strings.TrimSpace(a) == strings.TrimSpace(b)
+	}
+	return fa[0] == fb[0] && fa[1] == fb[1]
+}
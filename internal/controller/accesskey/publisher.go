@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accesskey
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/accesskey/v1alpha1"
+)
+
+const errApplyPublicKeySecret = "cannot create or update public key secret"
+
+// A publisher publishes the connection details of an AccessKey. If the
+// AccessKey has a writePublicKeySecretToRef, the public key goes to that
+// Secret along with the metadata of the key, and only the private key goes
+// to the connection secret.
+type publisher struct {
+	managed.ConnectionPublisher
+	secret resource.Applicator
+}
+
+// PublishConnection details for the supplied AccessKey.
+func (p *publisher) PublishConnection(ctx context.Context, mg resource.Managed, c managed.ConnectionDetails) error {
+	cr, ok := mg.(*v1alpha1.AccessKey)
+	if !ok || cr.Spec.WritePublicKeySecretToRef == nil {
+		return p.ConnectionPublisher.PublishConnection(ctx, mg, c)
+	}
+
+	private := managed.ConnectionDetails{}
+	for k, v := range c {
+		if k != v1alpha1.ConnectionPublicKeyKey {
+			private[k] = v
+		}
+	}
+	if err := p.ConnectionPublisher.PublishConnection(ctx, mg, private); err != nil {
+		return err
+	}
+	return errors.Wrap(p.secret.Apply(ctx, publicKeySecret(cr), resource.MustBeControllableBy(cr.GetUID())), errApplyPublicKeySecret)
+}
+
+// publicKeySecret returns the Secret of the public key of the supplied
+// AccessKey, which is garbage collected along with it.
+func publicKeySecret(cr *v1alpha1.AccessKey) *corev1.Secret {
+	ref, repo := cr.Spec.WritePublicKeySecretToRef, cr.Repo()
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       ref.Namespace,
+			Name:            ref.Name,
+			OwnerReferences: []metav1.OwnerReference{meta.AsController(meta.TypedReferenceTo(cr, v1alpha1.AccessKeyGroupVersionKind))},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			v1alpha1.PublicKeyLabelKey:      []byte(cr.Spec.ForProvider.PublicKey.Label),
+			v1alpha1.PublicKeyPermissionKey: []byte(cr.Spec.ForProvider.PublicKey.Permission),
+			v1alpha1.PublicKeyRepositoryKey: []byte(repo.ProjectKey + "/" + repo.Repo),
+		},
+	}
+	if k := cr.Spec.ForProvider.PublicKey.Key; k != "" {
+		s.Data[v1alpha1.ConnectionPublicKeyKey] = []byte(k)
+	}
+	if r := cr.Status.AtProvider.Repository; r != nil && r.SSHCloneURL != "" {
+		s.Data[v1alpha1.PublicKeyCloneURLKey] = []byte(r.SSHCloneURL)
+	}
+	return s
+}
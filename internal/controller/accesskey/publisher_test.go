@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accesskey
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/accesskey/v1alpha1"
+)
+
+func TestPublishConnection(t *testing.T) {
+	errBoom := errors.New("boom")
+	details := managed.ConnectionDetails{
+		v1alpha1.ConnectionPrivateKeyKey: []byte("private"),
+		v1alpha1.ConnectionPublicKeyKey:  []byte("ssh-ed25519 AAA"),
+	}
+	key := func(pub *xpv1.SecretReference) *v1alpha1.AccessKey {
+		cr := &v1alpha1.AccessKey{}
+		cr.SetName("cool-key")
+		cr.SetUID("uid")
+		cr.Spec.ForProvider = v1alpha1.AccessKeyParameters{
+			ProjectKey: "PROJ",
+			RepoName:   "Cool Repo",
+			PublicKey:  v1alpha1.PublicKey{Label: "label", Key: "ssh-ed25519 AAA", Permission: "REPO_READ"},
+		}
+		cr.Spec.WritePublicKeySecretToRef = pub
+		cr.Status.AtProvider.Repository = &v1alpha1.RepositoryObservation{ID: 1, SSHCloneURL: "ssh://git@bitbucket/proj/cool-repo.git"}
+		return cr
+	}
+	ref := &xpv1.SecretReference{Namespace: "ns", Name: "key-pub"}
+
+	type want struct {
+		err     error
+		private managed.ConnectionDetails
+		public  *corev1.Secret
+	}
+
+	cases := map[string]struct {
+		mg        *v1alpha1.AccessKey
+		publish   error
+		applyFail error
+		want      want
+	}{
+		"ConnectionSecretOnly": {
+			mg:   key(nil),
+			want: want{private: details},
+		},
+		"SplitSecrets": {
+			mg: key(ref),
+			want: want{
+				private: managed.ConnectionDetails{v1alpha1.ConnectionPrivateKeyKey: []byte("private")},
+				public: &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:       "ns",
+						Name:            "key-pub",
+						OwnerReferences: []metav1.OwnerReference{meta.AsController(meta.TypedReferenceTo(key(ref), v1alpha1.AccessKeyGroupVersionKind))},
+					},
+					Type: corev1.SecretTypeOpaque,
+					Data: map[string][]byte{
+						v1alpha1.ConnectionPublicKeyKey: []byte("ssh-ed25519 AAA"),
+						v1alpha1.PublicKeyLabelKey:      []byte("label"),
+						v1alpha1.PublicKeyPermissionKey: []byte("REPO_READ"),
+						v1alpha1.PublicKeyRepositoryKey: []byte("PROJ/cool-repo"),
+						v1alpha1.PublicKeyCloneURLKey:   []byte("ssh://git@bitbucket/proj/cool-repo.git"),
+					},
+				},
+			},
+		},
+		"PublishFailed": {
+			mg:      key(ref),
+			publish: errBoom,
+			want: want{
+				err:     errBoom,
+				private: managed.ConnectionDetails{v1alpha1.ConnectionPrivateKeyKey: []byte("private")},
+			},
+		},
+		"ApplyFailed": {
+			mg:        key(ref),
+			applyFail: errBoom,
+			want: want{
+				err:     errors.Wrap(errBoom, errApplyPublicKeySecret),
+				private: managed.ConnectionDetails{v1alpha1.ConnectionPrivateKeyKey: []byte("private")},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var private managed.ConnectionDetails
+			var public *corev1.Secret
+			p := &publisher{
+				ConnectionPublisher: managed.ConnectionPublisherFns{
+					PublishConnectionFn: func(_ context.Context, _ resource.Managed, c managed.ConnectionDetails) error {
+						private = c
+						return tc.publish
+					},
+				},
+				secret: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
+					if tc.applyFail != nil {
+						return tc.applyFail
+					}
+					public = o.(*corev1.Secret)
+					return nil
+				}),
+			}
+			err := p.PublishConnection(context.Background(), tc.mg, details)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("PublishConnection(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.private, private); diff != "" {
+				t.Errorf("PublishConnection(...): -want connection details, +got connection details:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.public, public); diff != "" {
+				t.Errorf("PublishConnection(...): -want public key secret, +got public key secret:\n%s", diff)
+			}
+		})
+	}
+}
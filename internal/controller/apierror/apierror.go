@@ -0,0 +1,238 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package apierror sets the reason of the Synced condition of managed
+// resources to the kind of error returned by the Bitbucket API, e.g.
+// Unauthorized or RateLimited instead of ReconcileError.
+package apierror
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/moved"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/preflight"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+// Reasons of the Synced condition of managed resources whose reconcile
+// failed because of an error returned by the Bitbucket API.
+const (
+	ReasonUnauthorized            xpv1.ConditionReason = "Unauthorized"
+	ReasonInsufficientPermissions xpv1.ConditionReason = "InsufficientPermissions"
+	ReasonForbidden               xpv1.ConditionReason = "Forbidden"
+	ReasonRepoNotFound            xpv1.ConditionReason = "RepoNotFound"
+	ReasonRepoMoved               xpv1.ConditionReason = "RepoMoved"
+	ReasonRepoGone                xpv1.ConditionReason = "RepoGone"
+	ReasonConflict                xpv1.ConditionReason = "Conflict"
+	ReasonRateLimited             xpv1.ConditionReason = "RateLimited"
+	ReasonServerError             xpv1.ConditionReason = "ServerError"
+	ReasonUnreachable             xpv1.ConditionReason = "ProviderUnreachable"
+)
+
+var reasons = []struct {
+	err    error
+	reason xpv1.ConditionReason
+	hint   string
+}{
+	{bitbucket.ErrUnauthorized, ReasonUnauthorized, "Bitbucket rejected the credentials of the ProviderConfig"},
+	{preflight.ErrInsufficientPermissions, ReasonInsufficientPermissions, "the credentials of the ProviderConfig lack a permission the resource needs"},
+	{bitbucket.ErrForbidden, ReasonForbidden, "the credentials of the ProviderConfig lack the permission for this operation"},
+	{moved.ErrMoved, ReasonRepoMoved, "the repository was renamed or moved in Bitbucket after the resource was created in it"},
+	{moved.ErrGone, ReasonRepoGone, "the repository the resource was created in was deleted or moved to another project"},
+	// Missing webhooks and access keys are created, so a remaining not found
+	// error is about the project or repository they belong to.
+	{bitbucket.ErrNotFound, ReasonRepoNotFound, "the project or repository does not exist in Bitbucket"},
+	{bitbucket.ErrConflict, ReasonConflict, "Bitbucket rejected the change because it conflicts with its current state"},
+	{bitbucket.ErrRateLimited, ReasonRateLimited, "Bitbucket is rate limiting the requests of the provider"},
+	{bitbucket.ErrServer, ReasonServerError, "Bitbucket is failing or unavailable"},
+	{bitbucket.ErrCircuitOpen, ReasonServerError, "Bitbucket is failing or unavailable"},
+	{bitbucket.ErrUnreachable, ReasonUnreachable, "Bitbucket cannot be reached from the provider"},
+}
+
+// Reason returns the condition reason and a hint for the cause of an error
+// returned by the Bitbucket API. It returns false for all other errors.
+func Reason(err error) (xpv1.ConditionReason, string, bool) {
+	for _, r := range reasons {
+		if errors.Is(err, r.err) {
+			return r.reason, r.hint, true
+		}
+	}
+	return "", "", false
+}
+
+type cause struct {
+	reason xpv1.ConditionReason
+	hint   string
+}
+
+// A Tracker remembers the Bitbucket API error of the last operation on each
+// managed resource, and sets the reason of their ReconcileError conditions
+// accordingly when their status is updated. The managed reconciler of
+// crossplane-runtime sets the ReconcileError condition itself, so the reason
+// can only be replaced in the status update that ends each reconcile.
+type Tracker struct {
+	mu     sync.Mutex
+	causes map[types.UID]cause
+}
+
+// NewTracker returns a Tracker without any errors.
+func NewTracker() *Tracker {
+	return &Tracker{causes: map[types.UID]cause{}}
+}
+
+// record the error of an operation on the managed resource, forgetting any
+// earlier error when it is not a Bitbucket API error.
+func (t *Tracker) record(mg resource.Managed, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if reason, hint, ok := Reason(err); ok {
+		t.causes[mg.GetUID()] = cause{reason: reason, hint: hint}
+		return
+	}
+	delete(t.causes, mg.GetUID())
+}
+
+// take returns the cause of the last recorded Bitbucket API error of the
+// managed resource and forgets it. Since every reconcile ends with a status
+// update, no cause outlives the reconcile it was recorded in, even when the
+// managed resource is deleted or orphaned afterwards.
+func (t *Tracker) take(mg resource.Managed) (cause, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ca, ok := t.causes[mg.GetUID()]
+	delete(t.causes, mg.GetUID())
+	return ca, ok
+}
+
+// setReason replaces the reason of a ReconcileError condition of the managed
+// resource by the one of the last recorded Bitbucket API error.
+func (t *Tracker) setReason(mg resource.Managed) {
+	ca, ok := t.take(mg)
+	if !ok {
+		return
+	}
+	c := mg.GetCondition(xpv1.TypeSynced)
+	if c.Reason != xpv1.ReasonReconcileError {
+		return
+	}
+	c.Reason = ca.reason
+	c.Message = ca.hint + ": " + c.Message
+	mg.SetConditions(c)
+}
+
+// Manager wraps the supplied manager so that the status updates of managed
+// resources made by its client use the reasons of the tracked errors. Pass
+// it to managed.NewReconciler.
+func (t *Tracker) Manager(m ctrl.Manager) ctrl.Manager {
+	return &trackingManager{Manager: m, tracker: t}
+}
+
+// NewConnecter wraps an ExternalConnecter so that the errors of its clients
+// are tracked.
+func (t *Tracker) NewConnecter(c managed.ExternalConnecter) managed.ExternalConnecter {
+	return &connecter{connecter: c, tracker: t}
+}
+
+type trackingManager struct {
+	ctrl.Manager
+	tracker *Tracker
+}
+
+// GetClient implements ctrl.Manager
+func (m *trackingManager) GetClient() client.Client {
+	return &trackingClient{Client: m.Manager.GetClient(), tracker: m.tracker}
+}
+
+type trackingClient struct {
+	client.Client
+	tracker *Tracker
+}
+
+// Status implements client.StatusClient
+func (c *trackingClient) Status() client.StatusWriter {
+	return &statusWriter{StatusWriter: c.Client.Status(), tracker: c.tracker}
+}
+
+type statusWriter struct {
+	client.StatusWriter
+	tracker *Tracker
+}
+
+// Update implements client.StatusWriter
+func (w *statusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if mg, ok := obj.(resource.Managed); ok {
+		w.tracker.setReason(mg)
+	}
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+type connecter struct {
+	connecter managed.ExternalConnecter
+	tracker   *Tracker
+}
+
+// Connect implements managed.ExternalConnecter
+func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	ec, err := c.connecter.Connect(ctx, mg)
+	c.tracker.record(mg, err)
+	if err != nil {
+		return nil, err
+	}
+	return &external{client: ec, tracker: c.tracker}, nil
+}
+
+type external struct {
+	client  managed.ExternalClient
+	tracker *Tracker
+}
+
+// Observe implements managed.ExternalClient
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	o, err := e.client.Observe(ctx, mg)
+	e.tracker.record(mg, err)
+	return o, err
+}
+
+// Create implements managed.ExternalClient
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	c, err := e.client.Create(ctx, mg)
+	e.tracker.record(mg, err)
+	return c, err
+}
+
+// Update implements managed.ExternalClient
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	u, err := e.client.Update(ctx, mg)
+	e.tracker.record(mg, err)
+	return u, err
+}
+
+// Delete implements managed.ExternalClient
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	err := e.client.Delete(ctx, mg)
+	e.tracker.record(mg, err)
+	return err
+}
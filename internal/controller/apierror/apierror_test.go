@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apierror
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	xpfake "github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	webhookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/moved"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/preflight"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+func TestReason(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want xpv1.ConditionReason
+		ok   bool
+	}{
+		"Unauthorized":            {err: errors.Wrap(bitbucket.ErrUnauthorized, "GetWebhook"), want: ReasonUnauthorized, ok: true},
+		"Forbidden":               {err: bitbucket.ErrForbidden, want: ReasonForbidden, ok: true},
+		"InsufficientPermissions": {err: errors.Wrap(preflight.ErrInsufficientPermissions, "credentials lack the admin permission on repository PRJ/repo"), want: ReasonInsufficientPermissions, ok: true},
+		"NotFound":                {err: bitbucket.ErrNotFound, want: ReasonRepoNotFound, ok: true},
+		"RepoMoved":               {err: errors.Wrap(moved.ErrMoved, "repository 5 at PRJ/old is now PRJ/new"), want: ReasonRepoMoved, ok: true},
+		"RepoGone":                {err: moved.ErrGone, want: ReasonRepoGone, ok: true},
+		"Conflict":                {err: bitbucket.ErrConflict, want: ReasonConflict, ok: true},
+		"RateLimited":             {err: bitbucket.ErrRateLimited, want: ReasonRateLimited, ok: true},
+		"ServerError":             {err: bitbucket.ErrServer, want: ReasonServerError, ok: true},
+		"CircuitOpen":             {err: bitbucket.ErrCircuitOpen, want: ReasonServerError, ok: true},
+		"Unreachable":             {err: errors.Wrap(bitbucket.ErrUnreachable, "dial tcp"), want: ReasonUnreachable, ok: true},
+		"Other":                   {err: errors.New("boom")},
+		"NoError":                 {},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, _, ok := Reason(tc.err)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Reason(...): -want, +got\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.ok, ok); diff != "" {
+				t.Errorf("Reason(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTracker(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		observeErr error
+		condition  xpv1.Condition
+		want       xpv1.ConditionReason
+	}{
+		"APIError": {
+			observeErr: bitbucket.ErrUnauthorized,
+			condition:  xpv1.ReconcileError(bitbucket.ErrUnauthorized),
+			want:       ReasonUnauthorized,
+		},
+		"OtherError": {
+			observeErr: errBoom,
+			condition:  xpv1.ReconcileError(errBoom),
+			want:       xpv1.ReasonReconcileError,
+		},
+		"ReconcileSuccess": {
+			condition: xpv1.ReconcileSuccess(),
+			want:      xpv1.ReasonReconcileSuccess,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var updated resource.Managed
+			mgr := &xpfake.Manager{Client: &test.MockClient{
+				MockStatusUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+					updated = obj.(resource.Managed)
+					return nil
+				},
+			}}
+
+			tr := NewTracker()
+			// An earlier error must not leak into later reconciles.
+			tr.record(&webhookv1alpha1.Webhook{}, bitbucket.ErrServer)
+
+			c := tr.NewConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return managed.ExternalClientFns{
+					ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+						return managed.ExternalObservation{}, tc.observeErr
+					},
+				}, nil
+			}))
+
+			cr := &webhookv1alpha1.Webhook{}
+			ec, err := c.Connect(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("Connect(...): %v", err)
+			}
+			_, err = ec.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.observeErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Observe(...): -want, +got\n%s", diff)
+			}
+
+			cr.SetConditions(tc.condition)
+			if err := tr.Manager(mgr).GetClient().Status().Update(context.Background(), cr); err != nil {
+				t.Fatalf("Update(...): %v", err)
+			}
+			got := updated.GetCondition(xpv1.TypeSynced)
+			if diff := cmp.Diff(tc.want, got.Reason); diff != "" {
+				t.Errorf("Update(...): -want, +got\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.condition.Status, got.Status); diff != "" {
+				t.Errorf("Update(...): -want, +got\n%s", diff)
+			}
+			if diff := cmp.Diff(0, len(tr.causes)); diff != "" {
+				t.Errorf("Update(...): -want tracked causes, +got tracked causes\n%s", diff)
+			}
+		})
+	}
+}
@@ -17,25 +17,62 @@ limitations under the License.
 package controller
 
 import (
-	"k8s.io/client-go/util/workqueue"
+	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 
-	"github.com/crossplane/crossplane-runtime/pkg/logging"
-
+	accesskeyv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/accesskey/v1alpha1"
+	defaultpermissionv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/defaultpermission/v1alpha1"
+	loggerv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/logger/v1alpha1"
+	mergestrategyv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/mergestrategy/v1alpha1"
+	projecthookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/projecthook/v1alpha1"
+	webhookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
 	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/accesskey"
 	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/config"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/defaultpermission"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/inventory"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/licenseinfo"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/logger"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/mergestrategy"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/projecthook"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/serverinfo"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/setup"
 	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/webhook"
 )
 
 // Setup creates all Bitbucket Server controllers with the supplied
-// logger and adds them to the supplied manager.
-func Setup(mgr ctrl.Manager, l logging.Logger, wl workqueue.RateLimiter) error {
-	for _, setup := range []func(ctrl.Manager, logging.Logger, workqueue.RateLimiter) error{
+// options and adds them to the supplied manager.
+func Setup(mgr ctrl.Manager, o setup.Options) error {
+	for _, setup := range []func(ctrl.Manager, setup.Options) error{
 		config.Setup,
 		accesskey.Setup,
+		defaultpermission.Setup,
+		inventory.Setup,
+		licenseinfo.Setup,
+		logger.Setup,
+		mergestrategy.Setup,
+		projecthook.Setup,
+		serverinfo.Setup,
 		webhook.Setup,
 	} {
-		if err := setup(mgr, l, wl); err != nil {
+		if err := setup(mgr, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetupWebhooks registers the admission webhooks defaulting and validating
+// all Bitbucket Server managed resources with the supplied manager.
+func SetupWebhooks(mgr ctrl.Manager) error {
+	for _, obj := range []runtime.Object{
+		&accesskeyv1alpha1.AccessKey{},
+		&defaultpermissionv1alpha1.DefaultPermission{},
+		&loggerv1alpha1.Logger{},
+		&mergestrategyv1alpha1.MergeStrategy{},
+		&projecthookv1alpha1.ProjectHook{},
+		&webhookv1alpha1.Webhook{},
+	} {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(obj).Complete(); err != nil {
 			return err
 		}
 	}
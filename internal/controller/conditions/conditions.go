@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conditions sets the Ready condition of managed resources the same
+// way for all kinds, so that the external clients of the controllers don't
+// have to:
+//
+//   - Observe marks the managed resource Available when its external resource
+//     exists.
+//   - Create marks it Creating. It stays Creating until the next Observe
+//     confirms that the external resource exists, so that a create whose
+//     follow-up steps fail is not reported ready.
+//   - Update leaves the condition as Observe set it. A failed update is
+//     reported by the Synced condition.
+//   - Delete marks it Deleting before the external resource is deleted, so
+//     that a failing deletion shows up as such.
+package conditions
+
+import (
+	"context"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// NewConnecter wraps an ExternalConnecter so that its clients set the Ready
+// condition of the managed resources they reconcile.
+func NewConnecter(c managed.ExternalConnecter) managed.ExternalConnecter {
+	return &connecter{connecter: c}
+}
+
+type connecter struct {
+	connecter managed.ExternalConnecter
+}
+
+// Connect implements managed.ExternalConnecter
+func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	ec, err := c.connecter.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+	return &external{ExternalClient: ec}, nil
+}
+
+type external struct {
+	managed.ExternalClient
+}
+
+// Observe implements managed.ExternalClient
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	o, err := e.ExternalClient.Observe(ctx, mg)
+	if err == nil && o.ResourceExists {
+		mg.SetConditions(xpv1.Available())
+	}
+	return o, err
+}
+
+// Create implements managed.ExternalClient
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	mg.SetConditions(xpv1.Creating())
+	return e.ExternalClient.Create(ctx, mg)
+}
+
+// Delete implements managed.ExternalClient
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	mg.SetConditions(xpv1.Deleting())
+	return e.ExternalClient.Delete(ctx, mg)
+}
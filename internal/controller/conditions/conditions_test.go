@@ -0,0 +1,178 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	webhookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+)
+
+func instance(c ...xpv1.Condition) *webhookv1alpha1.Webhook {
+	cr := &webhookv1alpha1.Webhook{}
+	cr.SetConditions(c...)
+	return cr
+}
+
+func connect(t *testing.T, ec managed.ExternalClient, mg resource.Managed) managed.ExternalClient {
+	t.Helper()
+	e, err := NewConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+		return ec, nil
+	})).Connect(context.Background(), mg)
+	if err != nil {
+		t.Fatalf("Connect(...): %v", err)
+	}
+	return e
+}
+
+var ignoreTime = cmpopts.IgnoreFields(xpv1.Condition{}, "LastTransitionTime")
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		o    managed.ExternalObservation
+		err  error
+		want *webhookv1alpha1.Webhook
+	}{
+		"Exists": {
+			o:    managed.ExternalObservation{ResourceExists: true},
+			want: instance(xpv1.Available()),
+		},
+		"DoesNotExist": {
+			want: instance(),
+		},
+		"Failed": {
+			o:    managed.ExternalObservation{ResourceExists: true},
+			err:  errBoom,
+			want: instance(),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := instance()
+			e := connect(t, managed.ExternalClientFns{
+				ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return tc.o, tc.err
+				},
+			}, cr)
+			_, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Observe(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want, cr, ignoreTime); diff != "" {
+				t.Errorf("Observe(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		err  error
+		want *webhookv1alpha1.Webhook
+	}{
+		"Created": {
+			want: instance(xpv1.Creating()),
+		},
+		"Failed": {
+			err:  errBoom,
+			want: instance(xpv1.Creating()),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := instance()
+			e := connect(t, managed.ExternalClientFns{
+				CreateFn: func(_ context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+					if diff := cmp.Diff(instance(xpv1.Creating()), mg, ignoreTime); diff != "" {
+						t.Errorf("Create(...): -want, +got:\n%s", diff)
+					}
+					return managed.ExternalCreation{}, tc.err
+				},
+			}, cr)
+			_, err := e.Create(context.Background(), cr)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Create(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want, cr, ignoreTime); diff != "" {
+				t.Errorf("Create(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	cr := instance(xpv1.Available())
+	e := connect(t, managed.ExternalClientFns{
+		UpdateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+			return managed.ExternalUpdate{}, nil
+		},
+	}, cr)
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Errorf("Update(...): %v", err)
+	}
+	if diff := cmp.Diff(instance(xpv1.Available()), cr, ignoreTime); diff != "" {
+		t.Errorf("Update(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		err error
+	}{
+		"Deleted": {},
+		"Failed":  {err: errBoom},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := instance(xpv1.Available())
+			e := connect(t, managed.ExternalClientFns{
+				DeleteFn: func(_ context.Context, mg resource.Managed) error {
+					if diff := cmp.Diff(instance(xpv1.Deleting()), mg, ignoreTime); diff != "" {
+						t.Errorf("Delete(...): -want, +got:\n%s", diff)
+					}
+					return tc.err
+				},
+			}, cr)
+			err := e.Delete(context.Background(), cr)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Delete(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(instance(xpv1.Deleting()), cr, ignoreTime); diff != "" {
+				t.Errorf("Delete(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
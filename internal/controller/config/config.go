@@ -17,44 +17,59 @@ limitations under the License.
 package config
 
 import (
+	"context"
 	"crypto/tls"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 
-	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/crossplane/crossplane-runtime/pkg/event"
-	"github.com/crossplane/crossplane-runtime/pkg/logging"
-	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/providerconfig"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/setup"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/rest"
 )
 
 // Setup adds a controller that reconciles ProviderConfigs by accounting for
-// their current usage.
-func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter) error {
+// their current usage and exporting the version of their server.
+func Setup(mgr ctrl.Manager, o setup.Options) error {
 	name := providerconfig.ControllerName(v1alpha1.ProviderConfigGroupKind)
 
-	o := controller.Options{
-		RateLimiter: ratelimiter.NewDefaultManagedRateLimiter(rl),
-	}
-
 	of := resource.ProviderConfigKinds{
 		Config:    v1alpha1.ProviderConfigGroupVersionKind,
 		UsageList: v1alpha1.ProviderConfigUsageListGroupVersionKind,
 	}
 
+	if o.ConfigCache != nil {
+		i, err := mgr.GetCache().GetInformer(context.Background(), &corev1.Secret{})
+		if err != nil {
+			return errors.Wrap(err, errSecretInformer)
+		}
+		i.AddEventHandler(o.ConfigCache.SecretHandler())
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
-		WithOptions(o).
+		WithOptions(o.ForControllerRuntime()).
 		For(&v1alpha1.ProviderConfig{}).
 		Watches(&source.Kind{Type: &v1alpha1.ProviderConfigUsage{}}, &resource.EnqueueRequestForProviderConfig{}).
-		Complete(providerconfig.NewReconciler(mgr, of,
-			providerconfig.WithLogger(l.WithValues("controller", name)),
-			providerconfig.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))))
+		Complete(&versionReconciler{
+			kube: mgr.GetClient(),
+			reconciler: providerconfig.NewReconciler(mgr, of,
+				providerconfig.WithLogger(o.Logger.WithValues("controller", name)),
+				providerconfig.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))),
+			newClient: func(c clients.Config) VersionClient { return clients.NewClient(c) },
+			log:       o.Logger.WithValues("controller", name),
+			retry:     o.PollInterval,
+		})
 }
 
 // NewTLSConfig creates TLS config to override security configuration for bitbucket clients
@@ -72,3 +87,65 @@ func NewTLSConfig(pc v1alpha1.ProviderConfig) *tls.Config {
 	}
 	return tlsConfig
 }
+
+// RequestTimeout returns the timeout for single requests to the bitbucket
+// server, zero if none is configured
+func RequestTimeout(pc v1alpha1.ProviderConfig) time.Duration {
+	if pc.Spec.RequestTimeout == nil {
+		return 0
+	}
+	return pc.Spec.RequestTimeout.Duration
+}
+
+const (
+	errNoHeaderName = "authentication scheme Header requires a headerName"
+	errGetPC        = "cannot get ProviderConfig"
+	errGetCreds     = "cannot get credentials"
+	errAuth         = "cannot configure authentication"
+
+	errSecretInformer = "cannot get informer of Secrets"
+)
+
+// ClientConfig returns the configuration of a client of the Bitbucket
+// server of the ProviderConfig, including its credentials.
+func ClientConfig(ctx context.Context, kube client.Client, pc *v1alpha1.ProviderConfig) (clients.Config, error) {
+	cd := pc.Spec.Credentials
+	data, err := resource.CommonCredentialExtractor(ctx, cd.Source, kube, cd.CommonCredentialSelectors)
+	if err != nil {
+		return clients.Config{}, errors.Wrap(err, errGetCreds)
+	}
+
+	auth, err := NewAuthenticator(*pc, string(data))
+	if err != nil {
+		return clients.Config{}, errors.Wrap(err, errAuth)
+	}
+
+	return clients.Config{
+		BaseURL:         pc.Spec.BaseURL,
+		Token:           string(data),
+		TLSConfig:       NewTLSConfig(*pc),
+		Timeout:         RequestTimeout(*pc),
+		Auth:            auth,
+		PageConcurrency: pc.Spec.PageConcurrency,
+	}, nil
+}
+
+// NewAuthenticator creates the strategy for sending the token as configured
+// in the ProviderConfig, by default as bearer token
+func NewAuthenticator(pc v1alpha1.ProviderConfig, token string) (rest.Authenticator, error) {
+	a := pc.Spec.Authentication
+	if a == nil {
+		return rest.BearerAuth{Token: token}, nil
+	}
+	switch a.Scheme {
+	case v1alpha1.AuthSchemeBasic:
+		return rest.BasicAuth{Username: a.Username, Password: token}, nil
+	case v1alpha1.AuthSchemeHeader:
+		if a.HeaderName == "" {
+			return nil, errors.New(errNoHeaderName)
+		}
+		return rest.HeaderAuth{Header: a.HeaderName, Token: token}, nil
+	default:
+		return rest.BearerAuth{Token: token}, nil
+	}
+}
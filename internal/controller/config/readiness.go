@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+)
+
+const errReadinessPing = "cannot reach Bitbucket with the credentials of the ProviderConfig"
+
+// A Pinger checks that a Bitbucket server can be reached and accepts the
+// credentials of the client.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// NewReadinessCheck returns a health check which fails unless the Bitbucket
+// server of the named ProviderConfig can be reached with its credentials.
+func NewReadinessCheck(kube client.Client, name string) healthz.Checker {
+	return newReadinessCheck(kube, name, func(c clients.Config) Pinger { return clients.NewClient(c) })
+}
+
+func newReadinessCheck(kube client.Client, name string, newPinger func(clients.Config) Pinger) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx := req.Context()
+
+		pc := &v1alpha1.ProviderConfig{}
+		if err := kube.Get(ctx, types.NamespacedName{Name: name}, pc); err != nil {
+			return errors.Wrap(err, errGetPC)
+		}
+		cfg, err := ClientConfig(ctx, kube, pc)
+		if err != nil {
+			return err
+		}
+		return errors.Wrap(newPinger(cfg).Ping(ctx), errReadinessPing)
+	}
+}
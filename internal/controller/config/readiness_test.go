@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+type pingFn func(ctx context.Context) error
+
+func (fn pingFn) Ping(ctx context.Context) error { return fn(ctx) }
+
+func TestReadinessCheck(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		getErr  error
+		pingErr error
+		want    error
+	}{
+		"Ready": {},
+		"NoProviderConfig": {
+			getErr: errBoom,
+			want:   errors.Wrap(errBoom, errGetPC),
+		},
+		"Unauthorized": {
+			pingErr: bitbucket.ErrUnauthorized,
+			want:    errors.Wrap(bitbucket.ErrUnauthorized, errReadinessPing),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					switch o := obj.(type) {
+					case *v1alpha1.ProviderConfig:
+						if tc.getErr != nil {
+							return tc.getErr
+						}
+						o.Spec.BaseURL = "https://bitbucket.example.com"
+						o.Spec.Credentials.Source = xpv1.CredentialsSourceSecret
+						o.Spec.Credentials.SecretRef = &xpv1.SecretKeySelector{Key: "token"}
+					case *corev1.Secret:
+						o.Data = map[string][]byte{"token": []byte("secret")}
+					}
+					return nil
+				},
+			}
+			check := newReadinessCheck(kube, "default", func(c clients.Config) Pinger {
+				if c.Token != "secret" || c.BaseURL != "https://bitbucket.example.com" {
+					t.Errorf("newPinger(...): unexpected config %+v", c)
+				}
+				return pingFn(func(_ context.Context) error { return tc.pingErr })
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+			if diff := cmp.Diff(tc.want, check(req), test.EquateErrors()); diff != "" {
+				t.Errorf("check(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"time"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/metrics"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+)
+
+// A VersionClient gets the version of a Bitbucket server.
+type VersionClient interface {
+	ServerVersion(ctx context.Context) (string, error)
+}
+
+// A versionReconciler exports the version of the Bitbucket server of a
+// ProviderConfig after the wrapped reconciler reconciled it, and removes
+// its metrics once it is deleted.
+type versionReconciler struct {
+	kube       client.Client
+	reconciler reconcile.Reconciler
+	newClient  func(clients.Config) VersionClient
+	log        logging.Logger
+	// retry is the delay after the version could not be detected
+	retry time.Duration
+}
+
+// Reconcile the ProviderConfig and detect the version of its server.
+func (r *versionReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	res, err := r.reconciler.Reconcile(ctx, req)
+	if err != nil {
+		return res, err
+	}
+
+	pc := &v1alpha1.ProviderConfig{}
+	if err := r.kube.Get(ctx, req.NamespacedName, pc); err != nil {
+		if kerrors.IsNotFound(err) {
+			metrics.DeleteProviderConfig(req.Name)
+		}
+		return res, nil
+	}
+	if pc.GetDeletionTimestamp() != nil {
+		return res, nil
+	}
+
+	cfg, err := ClientConfig(ctx, r.kube, pc)
+	if err != nil {
+		r.log.Debug("Cannot detect the version of Bitbucket", "providerConfig", req.Name, "error", err)
+		return res, nil
+	}
+	v, err := r.newClient(cfg).ServerVersion(ctx)
+	if err != nil {
+		r.log.Debug("Cannot detect the version of Bitbucket", "providerConfig", req.Name, "error", err)
+		if res.RequeueAfter == 0 || res.RequeueAfter > r.retry {
+			res.RequeueAfter = r.retry
+		}
+		return res, nil
+	}
+	metrics.SetServerVersion(req.Name, v)
+	return res, nil
+}
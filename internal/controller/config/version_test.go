@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/metrics"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+)
+
+type versionFn func(ctx context.Context) (string, error)
+
+func (fn versionFn) ServerVersion(ctx context.Context) (string, error) { return fn(ctx) }
+
+func TestVersionReconciler(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		getErr     error
+		version    string
+		versionErr error
+		want       reconcile.Result
+		wantSeries int
+	}{
+		"Detected": {
+			version:    "7.21.0",
+			wantSeries: 1,
+		},
+		"Unreachable": {
+			versionErr: errBoom,
+			want:       reconcile.Result{RequeueAfter: time.Minute},
+		},
+		"Deleted": {
+			getErr: kerrors.NewNotFound(schema.GroupResource{}, "example"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			metrics.DeleteProviderConfig("example")
+
+			kube := &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					switch o := obj.(type) {
+					case *v1alpha1.ProviderConfig:
+						if tc.getErr != nil {
+							return tc.getErr
+						}
+						o.Spec.BaseURL = "https://bitbucket.example.com"
+						o.Spec.Credentials.Source = xpv1.CredentialsSourceSecret
+						o.Spec.Credentials.SecretRef = &xpv1.SecretKeySelector{Key: "token"}
+					case *corev1.Secret:
+						o.Data = map[string][]byte{"token": []byte("secret")}
+					}
+					return nil
+				},
+			}
+			r := &versionReconciler{
+				kube: kube,
+				reconciler: reconcile.Func(func(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+					return reconcile.Result{}, nil
+				}),
+				newClient: func(_ clients.Config) VersionClient {
+					return versionFn(func(_ context.Context) (string, error) { return tc.version, tc.versionErr })
+				},
+				log:   logging.NewNopLogger(),
+				retry: time.Minute,
+			}
+
+			got, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "example"}})
+			if err != nil {
+				t.Errorf("Reconcile(...): unexpected error %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Reconcile(...): -want, +got\n%s", diff)
+			}
+			if got := testutil.CollectAndCount(metrics.ServerInfo); got != tc.wantSeries {
+				t.Errorf("Reconcile(...): want %d version series, got %d", tc.wantSeries, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package configcache caches the client configuration of ProviderConfigs,
+// so that the credentials are not read and parsed again on every reconcile
+// of every managed resource.
+package configcache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clock"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+)
+
+// A LoadFn reads the client configuration of a ProviderConfig, including its
+// credentials.
+type LoadFn func(ctx context.Context, kube client.Client, pc *v1alpha1.ProviderConfig) (clients.Config, error)
+
+// A Cache holds the client configuration of ProviderConfigs by name for a
+// while after loading it. An entry is loaded again when its ProviderConfig
+// changed, and dropped when the Secret of its credentials changes. It is
+// shared by all controllers. A nil Cache caches nothing.
+type Cache struct {
+	ttl   time.Duration
+	clock clock.Clock
+
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+// entry is the configuration loaded from a version of a ProviderConfig
+type entry struct {
+	resourceVersion string
+	// secret is the namespace/name of the Secret of the credentials, if
+	// they are read from one
+	secret string
+	loaded time.Time
+	cfg    clients.Config
+}
+
+// New returns a Cache which keeps configurations for the ttl, telling the
+// time by the clock.
+func New(ttl time.Duration, clk clock.Clock) *Cache {
+	return &Cache{ttl: ttl, clock: clk, entries: map[string]entry{}}
+}
+
+// ClientConfig returns the cached configuration of the ProviderConfig, or
+// loads and caches it if it is not cached, expired or of an older version
+// of the ProviderConfig. It only loads it if the Cache is nil.
+func (c *Cache) ClientConfig(ctx context.Context, kube client.Client, pc *v1alpha1.ProviderConfig, load LoadFn) (clients.Config, error) {
+	if c == nil {
+		return load(ctx, kube, pc)
+	}
+
+	c.mu.Lock()
+	e, ok := c.entries[pc.GetName()]
+	c.mu.Unlock()
+	if ok && e.resourceVersion == pc.GetResourceVersion() && c.clock.Now().Before(e.loaded.Add(c.ttl)) {
+		return e.cfg, nil
+	}
+
+	cfg, err := load(ctx, kube, pc)
+	if err != nil {
+		return clients.Config{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[pc.GetName()] = entry{
+		resourceVersion: pc.GetResourceVersion(),
+		secret:          secretKey(pc),
+		loaded:          c.clock.Now(),
+		cfg:             cfg,
+	}
+	return cfg, nil
+}
+
+// secretKey returns the namespace/name of the Secret of the credentials of the
+// ProviderConfig, empty if they are not read from a Secret.
+func secretKey(pc *v1alpha1.ProviderConfig) string {
+	ref := pc.Spec.Credentials.SecretRef
+	if ref == nil {
+		return ""
+	}
+	return ref.Namespace + "/" + ref.Name
+}
+
+// Forget drops the configurations with credentials from the Secret with the
+// namespace/name key, e.g. before reconciling the managed resources using
+// them after the Secret changed. It does nothing if the Cache is nil.
+func (c *Cache) Forget(key string) {
+	if c == nil {
+		return
+	}
+	c.invalidate(key)
+}
+
+// invalidate drops the configurations with credentials from the Secret with
+// the namespace/name key.
+func (c *Cache) invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, e := range c.entries {
+		if e.secret == key {
+			delete(c.entries, name)
+		}
+	}
+}
+
+// SecretHandler returns a handler of the events of an informer of Secrets,
+// which drops the configurations with credentials from changed or deleted
+// Secrets.
+func (c *Cache) SecretHandler() toolscache.ResourceEventHandler {
+	drop := func(obj interface{}) {
+		if key, err := toolscache.DeletionHandlingMetaNamespaceKeyFunc(obj); err == nil {
+			c.invalidate(key)
+		}
+	}
+	return toolscache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, obj interface{}) { drop(obj) },
+		DeleteFunc: drop,
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clock"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+)
+
+func providerConfig(resourceVersion string) *v1alpha1.ProviderConfig {
+	pc := &v1alpha1.ProviderConfig{}
+	pc.SetName("default")
+	pc.SetResourceVersion(resourceVersion)
+	pc.Spec.BaseURL = "https://bitbucket.example.com"
+	pc.Spec.Credentials.Source = xpv1.CredentialsSourceSecret
+	pc.Spec.Credentials.SecretRef = &xpv1.SecretKeySelector{
+		SecretReference: xpv1.SecretReference{Namespace: "crossplane-system", Name: "bitbucket"},
+		Key:             "token",
+	}
+	return pc
+}
+
+func secret(namespace, name string) *corev1.Secret {
+	return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+}
+
+func TestClientConfig(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		cfg   clients.Config
+		err   error
+		loads int
+	}
+
+	cases := map[string]struct {
+		// do gets configurations from the cache and may advance the clock
+		do   func(ctx context.Context, c *Cache, load LoadFn, now *time.Time) (clients.Config, error)
+		load func() error
+		want want
+	}{
+		"LoadedOnce": {
+			do: func(ctx context.Context, c *Cache, load LoadFn, _ *time.Time) (clients.Config, error) {
+				if _, err := c.ClientConfig(ctx, nil, providerConfig("1"), load); err != nil {
+					return clients.Config{}, err
+				}
+				return c.ClientConfig(ctx, nil, providerConfig("1"), load)
+			},
+			want: want{cfg: clients.Config{BaseURL: "https://bitbucket.example.com", Token: "token"}, loads: 1},
+		},
+		"LoadFailed": {
+			do: func(ctx context.Context, c *Cache, load LoadFn, _ *time.Time) (clients.Config, error) {
+				if _, err := c.ClientConfig(ctx, nil, providerConfig("1"), load); err == nil {
+					return clients.Config{}, errors.New("want error")
+				}
+				return c.ClientConfig(ctx, nil, providerConfig("1"), load)
+			},
+			load: func() error { return errBoom },
+			want: want{err: errBoom, loads: 2},
+		},
+		"Expired": {
+			do: func(ctx context.Context, c *Cache, load LoadFn, now *time.Time) (clients.Config, error) {
+				if _, err := c.ClientConfig(ctx, nil, providerConfig("1"), load); err != nil {
+					return clients.Config{}, err
+				}
+				*now = now.Add(time.Minute)
+				return c.ClientConfig(ctx, nil, providerConfig("1"), load)
+			},
+			want: want{cfg: clients.Config{BaseURL: "https://bitbucket.example.com", Token: "token"}, loads: 2},
+		},
+		"ProviderConfigChanged": {
+			do: func(ctx context.Context, c *Cache, load LoadFn, _ *time.Time) (clients.Config, error) {
+				if _, err := c.ClientConfig(ctx, nil, providerConfig("1"), load); err != nil {
+					return clients.Config{}, err
+				}
+				return c.ClientConfig(ctx, nil, providerConfig("2"), load)
+			},
+			want: want{cfg: clients.Config{BaseURL: "https://bitbucket.example.com", Token: "token"}, loads: 2},
+		},
+		"SecretChanged": {
+			do: func(ctx context.Context, c *Cache, load LoadFn, _ *time.Time) (clients.Config, error) {
+				if _, err := c.ClientConfig(ctx, nil, providerConfig("1"), load); err != nil {
+					return clients.Config{}, err
+				}
+				c.SecretHandler().OnUpdate(secret("crossplane-system", "bitbucket"), secret("crossplane-system", "bitbucket"))
+				return c.ClientConfig(ctx, nil, providerConfig("1"), load)
+			},
+			want: want{cfg: clients.Config{BaseURL: "https://bitbucket.example.com", Token: "token"}, loads: 2},
+		},
+		"SecretDeleted": {
+			do: func(ctx context.Context, c *Cache, load LoadFn, _ *time.Time) (clients.Config, error) {
+				if _, err := c.ClientConfig(ctx, nil, providerConfig("1"), load); err != nil {
+					return clients.Config{}, err
+				}
+				c.SecretHandler().OnDelete(secret("crossplane-system", "bitbucket"))
+				return c.ClientConfig(ctx, nil, providerConfig("1"), load)
+			},
+			want: want{cfg: clients.Config{BaseURL: "https://bitbucket.example.com", Token: "token"}, loads: 2},
+		},
+		"Forgotten": {
+			do: func(ctx context.Context, c *Cache, load LoadFn, _ *time.Time) (clients.Config, error) {
+				if _, err := c.ClientConfig(ctx, nil, providerConfig("1"), load); err != nil {
+					return clients.Config{}, err
+				}
+				c.Forget("crossplane-system/bitbucket")
+				return c.ClientConfig(ctx, nil, providerConfig("1"), load)
+			},
+			want: want{cfg: clients.Config{BaseURL: "https://bitbucket.example.com", Token: "token"}, loads: 2},
+		},
+		"OtherSecretChanged": {
+			do: func(ctx context.Context, c *Cache, load LoadFn, _ *time.Time) (clients.Config, error) {
+				if _, err := c.ClientConfig(ctx, nil, providerConfig("1"), load); err != nil {
+					return clients.Config{}, err
+				}
+				c.SecretHandler().OnUpdate(secret("default", "bitbucket"), secret("default", "bitbucket"))
+				return c.ClientConfig(ctx, nil, providerConfig("1"), load)
+			},
+			want: want{cfg: clients.Config{BaseURL: "https://bitbucket.example.com", Token: "token"}, loads: 1},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+			loads := 0
+			load := func(_ context.Context, _ client.Client, pc *v1alpha1.ProviderConfig) (clients.Config, error) {
+				loads++
+				if tc.load != nil {
+					if err := tc.load(); err != nil {
+						return clients.Config{}, err
+					}
+				}
+				return clients.Config{BaseURL: pc.Spec.BaseURL, Token: "token"}, nil
+			}
+			c := New(30*time.Second, clock.ClockFn(func() time.Time { return now }))
+
+			got, err := tc.do(context.Background(), c, load, &now)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ClientConfig(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.cfg, got); diff != "" {
+				t.Errorf("ClientConfig(...): -want, +got:\n%s", diff)
+			}
+			if loads != tc.want.loads {
+				t.Errorf("ClientConfig(...): want %d loads, got %d", tc.want.loads, loads)
+			}
+		})
+	}
+}
+
+func TestNilCache(t *testing.T) {
+	var c *Cache
+	loads := 0
+	load := func(_ context.Context, _ client.Client, _ *v1alpha1.ProviderConfig) (clients.Config, error) {
+		loads++
+		return clients.Config{}, nil
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := c.ClientConfig(context.Background(), nil, providerConfig("1"), load); err != nil {
+			t.Fatalf("ClientConfig(...): %v", err)
+		}
+	}
+	if loads != 2 {
+		t.Errorf("ClientConfig(...): want 2 loads for a nil cache, got %d", loads)
+	}
+	c.Forget("crossplane-system/bitbucket")
+}
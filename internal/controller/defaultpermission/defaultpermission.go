@@ -0,0 +1,245 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaultpermission
+
+import (
+	"context"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/defaultpermission/v1alpha1"
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/apierror"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/conditions"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/config"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/configcache"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/deletionprotection"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/dependents"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/drift"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/filter"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/managementpolicy"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/setup"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/features"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/metrics"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+const (
+	errNotDefaultPermission = "managed resource is not a DefaultPermission custom resource"
+	errTrackPCUsage         = "cannot track ProviderConfig usage"
+	errGetPC                = "cannot get ProviderConfig"
+
+	errGetFailed    = "cannot get default permission from bitbucket API"
+	errSetFailed    = "cannot set default permission with bitbucket API"
+	errDeleteFailed = "cannot revoke default permission with bitbucket API"
+)
+
+// Setup adds a controller that reconciles DefaultPermission managed resources.
+func Setup(mgr ctrl.Manager, o setup.Options) error {
+	name := managed.ControllerName(v1alpha1.DefaultPermissionGroupKind)
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	var conn managed.ExternalConnecter = deletionprotection.NewConnecter(conditions.NewConnecter(&connector{
+		kube:         mgr.GetClient(),
+		recorder:     recorder,
+		usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+		newServiceFn: clients.NewDefaultPermissionClient,
+		httpLog:      o.HTTPLogger(name),
+		clientLog:    o.ClientLogger(name),
+		warnFields:   o.WarnUnknownFields,
+		configs:      o.ConfigCache,
+	}))
+	conn = managementpolicy.NewConnecter(conn, o.Features.Enabled(features.EnableManagementPolicies))
+
+	errs := apierror.NewTracker()
+	r := managed.NewReconciler(errs.Manager(mgr),
+		resource.ManagedKind(v1alpha1.DefaultPermissionGroupVersionKind),
+		managed.WithExternalConnecter(errs.NewConnecter(conn)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(o.Timeout),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(recorder))
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.DefaultPermission{}, builder.WithPredicates(filter.Changes()))
+	b, err := dependents.Watch(mgr, b, o, &v1alpha1.DefaultPermission{}, func() resource.ManagedList { return &v1alpha1.DefaultPermissionList{} }, nil)
+	if err != nil {
+		return err
+	}
+	return b.Complete(o.Reconciler(mgr, v1alpha1.DefaultPermissionGroupVersionKind, r))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube         client.Client
+	usage        resource.Tracker
+	recorder     event.Recorder
+	newServiceFn func(clients.Config) bitbucket.DefaultPermissionClientAPI
+	httpLog      logging.Logger
+	clientLog    logging.Logger
+	warnFields   bool
+	configs      *configcache.Cache
+}
+
+// Connect typically produces an ExternalClient by:
+// 1. Tracking that the managed resource is using a ProviderConfig.
+// 2. Getting the managed resource's ProviderConfig.
+// 3. Getting the credentials specified by the ProviderConfig.
+// 4. Using the credentials to form a client.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.DefaultPermission)
+	if !ok {
+		return nil, errors.New(errNotDefaultPermission)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	pc := &apisv1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	cfg, err := c.configs.ClientConfig(ctx, c.kube, pc, config.ClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	cfg.HTTPLogger = c.httpLog
+	cfg.Logger = c.clientLog
+	cfg.WarnUnknownFields = c.warnFields
+	return &external{service: c.newServiceFn(cfg), recorder: c.recorder}, nil
+}
+
+// An external grants a permission on a project to all licensed users. Read
+// and write are separate grants in Bitbucket, so the granted permission is
+// the highest one all licensed users have.
+type external struct {
+	service  bitbucket.DefaultPermissionClientAPI
+	recorder event.Recorder
+}
+
+// permissions are the default permissions of projects, highest first
+var permissions = []string{bitbucket.PermissionProjectWrite, bitbucket.PermissionProjectRead}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.DefaultPermission)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotDefaultPermission)
+	}
+
+	granted, err := c.granted(ctx, cr.ProjectKey())
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetFailed)
+	}
+	cr.Status.AtProvider = v1alpha1.DefaultPermissionObservation{Permission: granted}
+
+	if granted == "" {
+		return managed.ExternalObservation{}, nil
+	}
+
+	want := v1alpha1.DefaultPermissionObservation{Permission: cr.Permission()}
+	diff := cmp.Diff(want, cr.Status.AtProvider)
+	if diff != "" {
+		fields := drift.Fields(want, cr.Status.AtProvider)
+		metrics.RecordDrift(v1alpha1.DefaultPermissionKind, fields)
+		c.recorder.Event(cr, drift.Event(fields))
+	}
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: diff == "",
+		Diff:             diff,
+	}, nil
+}
+
+// granted returns the highest permission all licensed users have on the
+// project, or an empty string if they have none.
+func (c *external) granted(ctx context.Context, projectKey string) (string, error) {
+	for _, p := range permissions {
+		permitted, err := c.service.GetDefaultPermission(ctx, projectKey, p)
+		if err != nil {
+			return "", err
+		}
+		if permitted {
+			return p, nil
+		}
+	}
+	return "", nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.DefaultPermission)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotDefaultPermission)
+	}
+
+	return managed.ExternalCreation{}, c.set(ctx, cr)
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.DefaultPermission)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotDefaultPermission)
+	}
+
+	return managed.ExternalUpdate{}, c.set(ctx, cr)
+}
+
+// set grants the permission of the managed resource and revokes a higher one
+// granted before, so that lowering write to read takes effect.
+func (c *external) set(ctx context.Context, cr *v1alpha1.DefaultPermission) error {
+	want := cr.Permission()
+	if want == bitbucket.PermissionProjectRead && cr.Status.AtProvider.Permission == bitbucket.PermissionProjectWrite {
+		if err := c.service.SetDefaultPermission(ctx, cr.ProjectKey(), bitbucket.PermissionProjectWrite, false); err != nil {
+			return errors.Wrap(err, errSetFailed)
+		}
+	}
+	if err := c.service.SetDefaultPermission(ctx, cr.ProjectKey(), want, true); err != nil {
+		return errors.Wrap(err, errSetFailed)
+	}
+	cr.Status.AtProvider.Permission = want
+	return nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.DefaultPermission)
+	if !ok {
+		return errors.New(errNotDefaultPermission)
+	}
+
+	for _, p := range permissions {
+		if err := c.service.SetDefaultPermission(ctx, cr.ProjectKey(), p, false); err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+			return errors.Wrap(err, errDeleteFailed)
+		}
+	}
+	return nil
+}
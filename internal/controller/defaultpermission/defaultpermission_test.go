@@ -0,0 +1,263 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaultpermission
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/defaultpermission/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket/fake"
+)
+
+type resourceModifier func(*v1alpha1.DefaultPermission)
+
+func withPermission(p string) resourceModifier {
+	return func(r *v1alpha1.DefaultPermission) { r.Spec.ForProvider.Permission = p }
+}
+
+func withObservation(p string) resourceModifier {
+	return func(r *v1alpha1.DefaultPermission) { r.Status.AtProvider.Permission = p }
+}
+
+func instance(rm ...resourceModifier) *v1alpha1.DefaultPermission {
+	r := &v1alpha1.DefaultPermission{}
+	r.Spec.ForProvider = v1alpha1.DefaultPermissionParameters{ProjectKey: "prj"}
+	for _, m := range rm {
+		m(r)
+	}
+	return r
+}
+
+var _ managed.ExternalConnecter = &connector{}
+var _ managed.ExternalClient = &external{}
+
+// grant is a call of SetDefaultPermission
+type grant struct {
+	Permission string
+	Allow      bool
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		cr  *v1alpha1.DefaultPermission
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		cr      *v1alpha1.DefaultPermission
+		granted map[string]bool
+		err     error
+		want    want
+	}{
+		"NotGranted": {
+			cr: instance(),
+			want: want{
+				cr: instance(),
+				o:  managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"UpToDate": {
+			cr:      instance(),
+			granted: map[string]bool{bitbucket.PermissionProjectRead: true},
+			want: want{
+				cr: instance(withObservation(bitbucket.PermissionProjectRead)),
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"WriteGranted": {
+			cr:      instance(),
+			granted: map[string]bool{bitbucket.PermissionProjectRead: true, bitbucket.PermissionProjectWrite: true},
+			want: want{
+				cr: instance(withObservation(bitbucket.PermissionProjectWrite)),
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+		"WriteWanted": {
+			cr:      instance(withPermission(bitbucket.PermissionProjectWrite)),
+			granted: map[string]bool{bitbucket.PermissionProjectRead: true},
+			want: want{
+				cr: instance(withPermission(bitbucket.PermissionProjectWrite), withObservation(bitbucket.PermissionProjectRead)),
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+		"GetFailed": {
+			cr:  instance(),
+			err: errBoom,
+			want: want{
+				cr:  instance(),
+				err: errors.Wrap(errBoom, errGetFailed),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{
+				service: &fake.MockDefaultPermissionClient{
+					MockGetDefaultPermission: func(_ context.Context, projectKey, permission string) (bool, error) {
+						if projectKey != "PRJ" {
+							t.Errorf("GetDefaultPermission(...): unexpected project %s", projectKey)
+						}
+						return tc.granted[permission], tc.err
+					},
+				},
+				recorder: event.NewNopRecorder(),
+			}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Observe(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.o.ResourceExists, got.ResourceExists); diff != "" {
+				t.Errorf("Observe(...): -want exists, +got exists:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.o.ResourceUpToDate, got.ResourceUpToDate); diff != "" {
+				t.Errorf("Observe(...): -want up to date, +got up to date:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.cr); diff != "" {
+				t.Errorf("Observe(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		grants []grant
+		err    error
+	}
+
+	cases := map[string]struct {
+		cr   *v1alpha1.DefaultPermission
+		err  error
+		want want
+	}{
+		"GrantRead": {
+			cr:   instance(),
+			want: want{grants: []grant{{bitbucket.PermissionProjectRead, true}}},
+		},
+		"GrantWrite": {
+			cr:   instance(withPermission(bitbucket.PermissionProjectWrite), withObservation(bitbucket.PermissionProjectRead)),
+			want: want{grants: []grant{{bitbucket.PermissionProjectWrite, true}}},
+		},
+		"LowerToRead": {
+			cr: instance(withObservation(bitbucket.PermissionProjectWrite)),
+			want: want{grants: []grant{
+				{bitbucket.PermissionProjectWrite, false},
+				{bitbucket.PermissionProjectRead, true},
+			}},
+		},
+		"SetFailed": {
+			cr:   instance(),
+			err:  errBoom,
+			want: want{grants: []grant{{bitbucket.PermissionProjectRead, true}}, err: errors.Wrap(errBoom, errSetFailed)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var grants []grant
+			e := &external{
+				service: &fake.MockDefaultPermissionClient{
+					MockSetDefaultPermission: func(_ context.Context, _ string, permission string, allow bool) error {
+						grants = append(grants, grant{permission, allow})
+						return tc.err
+					},
+				},
+				recorder: event.NewNopRecorder(),
+			}
+			_, err := e.Update(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Update(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.grants, grants); diff != "" {
+				t.Errorf("Update(...): -want grants, +got grants:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		grants []grant
+		err    error
+	}
+
+	cases := map[string]struct {
+		cr   *v1alpha1.DefaultPermission
+		err  error
+		want want
+	}{
+		"Revoked": {
+			cr: instance(),
+			want: want{grants: []grant{
+				{bitbucket.PermissionProjectWrite, false},
+				{bitbucket.PermissionProjectRead, false},
+			}},
+		},
+		"ProjectGone": {
+			cr:  instance(),
+			err: errors.Wrap(bitbucket.ErrNotFound, "404"),
+			want: want{grants: []grant{
+				{bitbucket.PermissionProjectWrite, false},
+				{bitbucket.PermissionProjectRead, false},
+			}},
+		},
+		"RevokeFailed": {
+			cr:   instance(),
+			err:  errBoom,
+			want: want{grants: []grant{{bitbucket.PermissionProjectWrite, false}}, err: errors.Wrap(errBoom, errDeleteFailed)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var grants []grant
+			e := &external{
+				service: &fake.MockDefaultPermissionClient{
+					MockSetDefaultPermission: func(_ context.Context, _ string, permission string, allow bool) error {
+						grants = append(grants, grant{permission, allow})
+						return tc.err
+					},
+				},
+				recorder: event.NewNopRecorder(),
+			}
+			err := e.Delete(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Delete(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.grants, grants); diff != "" {
+				t.Errorf("Delete(...): -want grants, +got grants:\n%s", diff)
+			}
+		})
+	}
+}
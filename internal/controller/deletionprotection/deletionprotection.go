@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package deletionprotection prevents the deletion of external resources
+// whose managed resources are annotated with the deletion protection
+// annotation.
+package deletionprotection
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+const (
+	// AnnotationKey protects the external resource of a managed resource
+	// from deletion when set to AnnotationValueEnabled.
+	AnnotationKey = "bitbucket-server.crossplane.io/deletion-protection"
+
+	// AnnotationValueEnabled enables the deletion protection.
+	AnnotationValueEnabled = "enabled"
+
+	errProtected = "deletion protection is enabled: remove the " + AnnotationKey + " annotation to delete the external resource"
+)
+
+// Enabled returns true if the deletion protection of the object is enabled.
+func Enabled(o metav1.Object) bool {
+	return o.GetAnnotations()[AnnotationKey] == AnnotationValueEnabled
+}
+
+// NewConnecter wraps an ExternalConnecter so that its clients refuse to
+// delete the external resources of protected managed resources.
+func NewConnecter(c managed.ExternalConnecter) managed.ExternalConnecter {
+	return &connecter{connecter: c}
+}
+
+type connecter struct {
+	connecter managed.ExternalConnecter
+}
+
+// Connect implements managed.ExternalConnecter
+func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	ec, err := c.connecter.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+	return &external{ExternalClient: ec}, nil
+}
+
+type external struct {
+	managed.ExternalClient
+}
+
+// Delete implements managed.ExternalClient
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	if Enabled(mg) {
+		return errors.New(errProtected)
+	}
+	return e.ExternalClient.Delete(ctx, mg)
+}
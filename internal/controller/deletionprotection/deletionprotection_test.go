@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deletionprotection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	webhookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+)
+
+func instance(annotations map[string]string) *webhookv1alpha1.Webhook {
+	r := &webhookv1alpha1.Webhook{}
+	r.SetAnnotations(annotations)
+	return r
+}
+
+func TestDelete(t *testing.T) {
+	type want struct {
+		err     error
+		deleted bool
+	}
+
+	cases := map[string]struct {
+		cr *webhookv1alpha1.Webhook
+		want
+	}{
+		"Unprotected": {
+			cr:   instance(nil),
+			want: want{deleted: true},
+		},
+		"Disabled": {
+			cr:   instance(map[string]string{AnnotationKey: "disabled"}),
+			want: want{deleted: true},
+		},
+		"Protected": {
+			cr:   instance(map[string]string{AnnotationKey: AnnotationValueEnabled}),
+			want: want{err: errors.New(errProtected)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			deleted := false
+			c := NewConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return managed.ExternalClientFns{
+					DeleteFn: func(_ context.Context, _ resource.Managed) error {
+						deleted = true
+						return nil
+					},
+				}, nil
+			}))
+			e, err := c.Connect(context.Background(), tc.cr)
+			if err != nil {
+				t.Fatalf("Connect(...): %v", err)
+			}
+			err = e.Delete(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Delete(...): -want, +got\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.deleted, deleted); diff != "" {
+				t.Errorf("Delete(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dependents requeues managed resources right away when the
+// ProviderConfig or the Secrets they depend on change, instead of on their
+// next poll, so that e.g. a fixed base URL or rotated credentials are used
+// promptly.
+package dependents
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/configcache"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/setup"
+)
+
+const (
+	// indexProviderConfig indexes managed resources by the name of their
+	// ProviderConfig
+	indexProviderConfig = "dependents.providerConfig"
+	// indexSecrets indexes managed resources by the namespace/name of the
+	// Secrets they reference
+	indexSecrets = "dependents.secrets"
+
+	errIndex = "cannot index managed resources"
+)
+
+// A SecretsFn returns the namespace/name keys of the Secrets a managed
+// resource references itself, besides the credentials of its ProviderConfig.
+type SecretsFn func(mg resource.Managed) []string
+
+// SecretKey returns the namespace/name key of a Secret.
+func SecretKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// A watcher maps changed ProviderConfigs and Secrets to the managed
+// resources of a kind which depend on them.
+type watcher struct {
+	kube    client.Reader
+	newList func() resource.ManagedList
+	configs *configcache.Cache
+	log     logging.Logger
+}
+
+// Watch indexes the managed resources of the kind of mg by their
+// ProviderConfig and by the Secrets the SecretsFn returns, which may be nil,
+// and makes the builder requeue them when their ProviderConfig, these
+// Secrets or the Secret of the credentials of their ProviderConfig change.
+func Watch(mgr ctrl.Manager, b *builder.Builder, o setup.Options, mg resource.Managed, newList func() resource.ManagedList, secrets SecretsFn) (*builder.Builder, error) {
+	ctx := context.Background()
+	err := mgr.GetFieldIndexer().IndexField(ctx, mg, indexProviderConfig, func(obj client.Object) []string {
+		if ref := obj.(resource.Managed).GetProviderConfigReference(); ref != nil {
+			return []string{ref.Name}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errIndex)
+	}
+	if secrets != nil {
+		err := mgr.GetFieldIndexer().IndexField(ctx, mg, indexSecrets, func(obj client.Object) []string {
+			return secrets(obj.(resource.Managed))
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, errIndex)
+		}
+	}
+
+	w := &watcher{kube: mgr.GetClient(), newList: newList, configs: o.ConfigCache, log: o.Logger}
+	// The status of a ProviderConfig changes with every usage, only
+	// changes of its spec are of interest.
+	b = b.Watches(&source.Kind{Type: &apisv1alpha1.ProviderConfig{}}, handler.EnqueueRequestsFromMapFunc(w.providerConfigRequests), builder.WithPredicates(predicate.GenerationChangedPredicate{}))
+	return b.Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(w.secretRequests)), nil
+}
+
+// providerConfigRequests returns requests for the managed resources using
+// the ProviderConfig.
+func (w *watcher) providerConfigRequests(obj client.Object) []reconcile.Request {
+	return w.requests(context.Background(), client.MatchingFields{indexProviderConfig: obj.GetName()})
+}
+
+// secretRequests returns requests for the managed resources which reference
+// the Secret or whose ProviderConfig has its credentials in it. The cached
+// client configurations with these credentials are dropped first, so that
+// the managed resources are reconciled with the changed credentials.
+func (w *watcher) secretRequests(obj client.Object) []reconcile.Request {
+	ctx := context.Background()
+	key := SecretKey(obj.GetNamespace(), obj.GetName())
+	w.configs.Forget(key)
+
+	reqs := w.requests(ctx, client.MatchingFields{indexSecrets: key})
+
+	pcs := &apisv1alpha1.ProviderConfigList{}
+	if err := w.kube.List(ctx, pcs); err != nil {
+		w.log.Info("Cannot list ProviderConfigs to requeue the managed resources using a changed Secret", "secret", key, "error", err)
+		return reqs
+	}
+	for _, pc := range pcs.Items {
+		ref := pc.Spec.Credentials.SecretRef
+		if ref == nil || SecretKey(ref.Namespace, ref.Name) != key {
+			continue
+		}
+		reqs = append(reqs, w.requests(ctx, client.MatchingFields{indexProviderConfig: pc.GetName()})...)
+	}
+	return reqs
+}
+
+// requests returns requests for the managed resources matching the fields.
+func (w *watcher) requests(ctx context.Context, fields client.MatchingFields) []reconcile.Request {
+	l := w.newList()
+	if err := w.kube.List(ctx, l, fields); err != nil {
+		w.log.Info("Cannot list managed resources to requeue", "fields", fields, "error", err)
+		return nil
+	}
+	items := l.GetItems()
+	reqs := make([]reconcile.Request, 0, len(items))
+	for _, mg := range items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: mg.GetName()}})
+	}
+	return reqs
+}
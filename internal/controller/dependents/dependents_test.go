@@ -0,0 +1,176 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dependents
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+)
+
+var errBoom = errors.New("boom")
+
+func providerConfig(name, secretNamespace, secretName string) apisv1alpha1.ProviderConfig {
+	pc := apisv1alpha1.ProviderConfig{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	pc.Spec.Credentials.SecretRef = &xpv1.SecretKeySelector{
+		SecretReference: xpv1.SecretReference{Namespace: secretNamespace, Name: secretName},
+		Key:             "credentials",
+	}
+	return pc
+}
+
+func secret(namespace, name string) *corev1.Secret {
+	return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+}
+
+func request(name string) reconcile.Request {
+	return reconcile.Request{NamespacedName: types.NamespacedName{Name: name}}
+}
+
+func TestSecretRequests(t *testing.T) {
+	// webhooks are the names of the webhooks matching each field
+	webhooks := map[string][]string{
+		indexSecrets + "=crossplane-system/url":          {"by-url"},
+		indexProviderConfig + "=default":                 {"default-1", "default-2"},
+		indexProviderConfig + "=other":                   {"other"},
+		indexSecrets + "=crossplane-system/credentials":  nil,
+		indexSecrets + "=crossplane-system/unreferenced": nil,
+	}
+	configs := []apisv1alpha1.ProviderConfig{
+		providerConfig("default", "crossplane-system", "credentials"),
+		providerConfig("other", "crossplane-system", "other"),
+		{ObjectMeta: metav1.ObjectMeta{Name: "no-secret"}},
+	}
+
+	list := func(pcErr, mgErr error) test.MockListFn {
+		return func(_ context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+			switch l := obj.(type) {
+			case *apisv1alpha1.ProviderConfigList:
+				if pcErr != nil {
+					return pcErr
+				}
+				l.Items = configs
+			case *v1alpha1.WebhookList:
+				if mgErr != nil {
+					return mgErr
+				}
+				for f, v := range opts[0].(client.MatchingFields) {
+					for _, name := range webhooks[f+"="+v] {
+						l.Items = append(l.Items, v1alpha1.Webhook{ObjectMeta: metav1.ObjectMeta{Name: name}})
+					}
+				}
+			}
+			return nil
+		}
+	}
+
+	cases := map[string]struct {
+		list   test.MockListFn
+		secret *corev1.Secret
+		want   []reconcile.Request
+	}{
+		"ReferencedSecret": {
+			list:   list(nil, nil),
+			secret: secret("crossplane-system", "url"),
+			want:   []reconcile.Request{request("by-url")},
+		},
+		"ProviderConfigSecret": {
+			list:   list(nil, nil),
+			secret: secret("crossplane-system", "credentials"),
+			want:   []reconcile.Request{request("default-1"), request("default-2")},
+		},
+		"UnreferencedSecret": {
+			list:   list(nil, nil),
+			secret: secret("crossplane-system", "unreferenced"),
+			want:   []reconcile.Request{},
+		},
+		"ListProviderConfigsFailed": {
+			list:   list(errBoom, nil),
+			secret: secret("crossplane-system", "url"),
+			want:   []reconcile.Request{request("by-url")},
+		},
+		"ListManagedFailed": {
+			list:   list(nil, errBoom),
+			secret: secret("crossplane-system", "credentials"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := &watcher{
+				kube:    &test.MockClient{MockList: tc.list},
+				newList: func() resource.ManagedList { return &v1alpha1.WebhookList{} },
+				log:     logging.NewNopLogger(),
+			}
+			got := w.secretRequests(tc.secret)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("secretRequests(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProviderConfigRequests(t *testing.T) {
+	cases := map[string]struct {
+		list test.MockListFn
+		want []reconcile.Request
+	}{
+		"Dependents": {
+			list: func(_ context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+				if diff := cmp.Diff(client.MatchingFields{indexProviderConfig: "default"}, opts[0]); diff != "" {
+					t.Errorf("List(...): -want fields, +got:\n%s", diff)
+				}
+				l := obj.(*v1alpha1.WebhookList)
+				l.Items = []v1alpha1.Webhook{{ObjectMeta: metav1.ObjectMeta{Name: "a"}}, {ObjectMeta: metav1.ObjectMeta{Name: "b"}}}
+				return nil
+			},
+			want: []reconcile.Request{request("a"), request("b")},
+		},
+		"ListFailed": {
+			list: test.NewMockListFn(errBoom),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := &watcher{
+				kube:    &test.MockClient{MockList: tc.list},
+				newList: func() resource.ManagedList { return &v1alpha1.WebhookList{} },
+				log:     logging.NewNopLogger(),
+			}
+			got := w.providerConfigRequests(&apisv1alpha1.ProviderConfig{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("providerConfigRequests(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package drift reports the fields in which external resources drifted from
+// the desired state of their managed resources.
+package drift
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+)
+
+// ReasonDetected is the reason of events about drifted external resources.
+const ReasonDetected event.Reason = "DriftDetected"
+
+// Fields returns the sorted names of the fields in which y differs from x.
+// Only the names are returned, so they are safe to show even if the fields
+// hold secrets.
+func Fields(x, y interface{}, opts ...cmp.Option) []string {
+	r := &reporter{fields: map[string]bool{}}
+	cmp.Equal(x, y, append(opts, cmp.Reporter(r))...)
+
+	fields := make([]string, 0, len(r.fields))
+	for f := range r.fields {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Event returns an event about the drift of an external resource in the
+// supplied fields.
+func Event(fields []string) event.Event {
+	return event.Normal(ReasonDetected, "External resource drifted from the desired state in: "+strings.Join(fields, ", "))
+}
+
+// A reporter collects the fields of the differences found by cmp.
+type reporter struct {
+	path   cmp.Path
+	fields map[string]bool
+}
+
+func (r *reporter) PushStep(ps cmp.PathStep) {
+	r.path = append(r.path, ps)
+}
+
+func (r *reporter) Report(rs cmp.Result) {
+	if rs.Equal() {
+		return
+	}
+	f := strings.TrimPrefix(r.path.String(), ".")
+	if f == "" {
+		f = "<root>"
+	}
+	r.fields[f] = true
+}
+
+func (r *reporter) PopStep() {
+	r.path = r.path[:len(r.path)-1]
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drift
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+func TestFields(t *testing.T) {
+	hook := func(url, secret string, events ...string) bitbucket.Webhook {
+		h := bitbucket.Webhook{Name: "name", URL: url, Events: events}
+		h.Configuration.Secret = secret
+		return h
+	}
+
+	cases := map[string]struct {
+		x, y interface{}
+		opts []cmp.Option
+		want []string
+	}{
+		"Equal": {
+			x:    hook("https://example.com", "s3cr3t", "repo:modified"),
+			y:    hook("https://example.com", "s3cr3t", "repo:modified"),
+			want: []string{},
+		},
+		"Fields": {
+			x:    hook("https://example.com", "s3cr3t", "repo:modified"),
+			y:    hook("https://other.example.com", "changed", "repo:modified", "repo:refs_changed"),
+			want: []string{"Configuration.Secret", "Events", "URL"},
+		},
+		"IgnoredField": {
+			x:    hook("https://example.com", "s3cr3t"),
+			y:    hook("https://example.com", "changed"),
+			opts: []cmp.Option{cmpopts.IgnoreFields(bitbucket.Webhook{}, "Configuration")},
+			want: []string{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Fields(tc.x, tc.y, tc.opts...)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Fields(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package externalname parses the external names of managed resources.
+package externalname
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+)
+
+// ErrInvalid is returned for external names that do not identify an external
+// resource.
+var ErrInvalid = errors.New("invalid external name")
+
+// ID returns the numeric ID of the external resource from the external name
+// of the object.
+func ID(o metav1.Object) (int, error) {
+	name := meta.GetExternalName(o)
+	id, err := strconv.Atoi(name)
+	if err != nil {
+		return 0, errors.Wrapf(ErrInvalid, "%q is not an ID", name)
+	}
+	return id, nil
+}
+
+// Pending returns true if the external name of the object is empty or the
+// name of the object, which the managed reconciler sets as external name
+// until the external resource is created.
+func Pending(o metav1.Object) bool {
+	name := meta.GetExternalName(o)
+	return name == "" || name == o.GetName()
+}
+
+// RepoID returns the numeric ID of the external resource of a repository
+// from an external name which is either the bare ID or PROJECT/repo/ID,
+// where repo is one of the supplied names of the repository and PROJECT
+// matches the project key in any case. It returns false if the external name
+// is not an ID, which is the case until the external resource is created.
+func RepoID(name, projectKey string, repos ...string) (int, bool, error) {
+	parts := strings.Split(name, "/")
+	if len(parts) == 1 {
+		id, err := strconv.Atoi(name)
+		return id, err == nil, nil
+	}
+
+	if len(parts) != 3 || !strings.EqualFold(parts[0], projectKey) || !contains(repos, parts[1]) {
+		return 0, false, errors.Wrapf(ErrInvalid, "%q is not PROJECT/repo/ID of the repository", name)
+	}
+	id, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return 0, false, errors.Wrapf(ErrInvalid, "%q is not PROJECT/repo/ID of the repository", name)
+	}
+	return id, true, nil
+}
+
+// RepoName returns the external name PROJECT/repo/ID of the external
+// resource of a repository.
+func RepoName(projectKey, repo string, id int) string {
+	return fmt.Sprintf("%s/%s/%d", projectKey, repo, id)
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
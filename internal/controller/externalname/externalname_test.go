@@ -0,0 +1,193 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externalname
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func object(name, externalName string) *metav1.ObjectMeta {
+	o := &metav1.ObjectMeta{Name: name}
+	if externalName != "" {
+		meta.SetExternalName(o, externalName)
+	}
+	return o
+}
+
+func TestID(t *testing.T) {
+	type want struct {
+		id  int
+		err error
+	}
+
+	cases := map[string]struct {
+		o    *metav1.ObjectMeta
+		want want
+	}{
+		"ID": {
+			o:    object("hook", "42"),
+			want: want{id: 42},
+		},
+		"NotNumeric": {
+			o:    object("hook", "ci-hook"),
+			want: want{err: errors.Wrapf(ErrInvalid, "%q is not an ID", "ci-hook")},
+		},
+		"Pending": {
+			o:    object("hook", "hook"),
+			want: want{err: errors.Wrapf(ErrInvalid, "%q is not an ID", "hook")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			id, err := ID(tc.o)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ID(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.id, id); diff != "" {
+				t.Errorf("ID(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPending(t *testing.T) {
+	cases := map[string]struct {
+		o    *metav1.ObjectMeta
+		want bool
+	}{
+		"Empty": {
+			o:    object("hook", ""),
+			want: true,
+		},
+		"NameOfObject": {
+			o:    object("hook", "hook"),
+			want: true,
+		},
+		"ID": {
+			o:    object("hook", "42"),
+			want: false,
+		},
+		"OtherName": {
+			o:    object("hook", "ci-hook"),
+			want: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, Pending(tc.o)); diff != "" {
+				t.Errorf("Pending(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRepoID(t *testing.T) {
+	type want struct {
+		id  int
+		ok  bool
+		err error
+	}
+
+	invalid := func(name string) error {
+		return errors.Wrapf(ErrInvalid, "%q is not PROJECT/repo/ID of the repository", name)
+	}
+
+	cases := map[string]struct {
+		name string
+		want want
+	}{
+		"BareID": {
+			name: "42",
+			want: want{id: 42, ok: true},
+		},
+		"RepoID": {
+			name: "PRJ/repo/42",
+			want: want{id: 42, ok: true},
+		},
+		"OtherNameOfRepo": {
+			name: "PRJ/Repo/42",
+			want: want{id: 42, ok: true},
+		},
+		"ProjectKeyInOtherCase": {
+			name: "prj/repo/42",
+			want: want{id: 42, ok: true},
+		},
+		"WrongProject": {
+			name: "OPS/repo/42",
+			want: want{err: invalid("OPS/repo/42")},
+		},
+		"WrongRepo": {
+			name: "PRJ/other/42",
+			want: want{err: invalid("PRJ/other/42")},
+		},
+		"NotNumeric": {
+			name: "PRJ/repo/hook",
+			want: want{err: invalid("PRJ/repo/hook")},
+		},
+		"TooManySegments": {
+			name: "PRJ/repo/hooks/42",
+			want: want{err: invalid("PRJ/repo/hooks/42")},
+		},
+		"TooFewSegments": {
+			name: "repo/42",
+			want: want{err: invalid("repo/42")},
+		},
+		"Pending": {
+			name: "hook",
+			want: want{ok: false},
+		},
+		"Empty": {
+			name: "",
+			want: want{ok: false},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			id, ok, err := RepoID(tc.name, "PRJ", "Repo", "repo")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("RepoID(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.ok, ok); diff != "" {
+				t.Errorf("RepoID(...): -want ok, +got ok:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.id, id); diff != "" {
+				t.Errorf("RepoID(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRepoName(t *testing.T) {
+	name := RepoName("PRJ", "repo", 42)
+	if diff := cmp.Diff("PRJ/repo/42", name); diff != "" {
+		t.Errorf("RepoName(...): -want, +got:\n%s", diff)
+	}
+	id, ok, err := RepoID(name, "PRJ", "repo")
+	if err != nil || !ok || id != 42 {
+		t.Errorf("RepoID(RepoName(...)): want 42, true, nil, got %d, %t, %v", id, ok, err)
+	}
+}
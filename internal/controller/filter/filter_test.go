@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+)
+
+func webhook(mod ...func(*v1alpha1.Webhook)) *v1alpha1.Webhook {
+	cr := &v1alpha1.Webhook{}
+	cr.SetGeneration(1)
+	cr.SetResourceVersion("1")
+	cr.SetAnnotations(map[string]string{"crossplane.io/external-name": "1"})
+	for _, m := range mod {
+		m(cr)
+	}
+	return cr
+}
+
+func TestChanges(t *testing.T) {
+	now := metav1.Now()
+
+	cases := map[string]struct {
+		old, new client.Object
+		want     bool
+	}{
+		"Resync": {
+			old:  webhook(),
+			new:  webhook(),
+			want: false,
+		},
+		"StatusOnly": {
+			old: webhook(),
+			new: webhook(func(cr *v1alpha1.Webhook) {
+				cr.SetResourceVersion("2")
+				cr.Status.AtProvider.ID = 1
+			}),
+			want: false,
+		},
+		"SpecChanged": {
+			old: webhook(),
+			new: webhook(func(cr *v1alpha1.Webhook) {
+				cr.SetResourceVersion("2")
+				cr.SetGeneration(2)
+			}),
+			want: true,
+		},
+		"Paused": {
+			old: webhook(),
+			new: webhook(func(cr *v1alpha1.Webhook) {
+				cr.SetResourceVersion("2")
+				cr.SetAnnotations(map[string]string{"crossplane.io/external-name": "1", "crossplane.io/paused": "true"})
+			}),
+			want: true,
+		},
+		"Deleted": {
+			old: webhook(),
+			new: webhook(func(cr *v1alpha1.Webhook) {
+				cr.SetResourceVersion("2")
+				cr.SetDeletionTimestamp(&now)
+			}),
+			want: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Changes().Update(event.UpdateEvent{ObjectOld: tc.old, ObjectNew: tc.new})
+			if got != tc.want {
+				t.Errorf("Update(...): want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
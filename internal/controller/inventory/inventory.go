@@ -0,0 +1,403 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	accesskeyv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/accesskey/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/inventory/v1alpha1"
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	webhookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clock"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/apierror"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/conditions"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/config"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/configcache"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/dependents"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/externalname"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/filter"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/setup"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+const (
+	errNotInventory = "managed resource is not an Inventory custom resource"
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+
+	errListProjects = "cannot list projects"
+	errNoProject    = "project %s not found"
+	errListRepos    = "cannot list repositories of project %s"
+	errListWebhooks = "cannot list webhooks of repository %s/%s"
+	errListKeys     = "cannot list access keys of repository %s/%s"
+	errListManaged  = "cannot list managed resources"
+
+	reasonOrphans event.Reason = "OrphansFound"
+
+	// defaultRefreshInterval is how often the projects and repositories are
+	// listed unless the parameters say otherwise.
+	defaultRefreshInterval = time.Hour
+
+	// maxRepositories is how many repositories are listed in the status at
+	// most, so that the status of large servers fits into etcd.
+	maxRepositories = 1000
+)
+
+// Setup adds a controller that reconciles Inventory managed resources.
+func Setup(mgr ctrl.Manager, o setup.Options) error {
+	name := managed.ControllerName(v1alpha1.InventoryGroupKind)
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	conn := conditions.NewConnecter(&connector{
+		kube:         mgr.GetClient(),
+		usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+		newServiceFn: clients.NewInventoryClient,
+		httpLog:      o.HTTPLogger(name),
+		clientLog:    o.ClientLogger(name),
+		warnFields:   o.WarnUnknownFields,
+		configs:      o.ConfigCache,
+		clock:        clock.System,
+		recorder:     recorder,
+	})
+
+	errs := apierror.NewTracker()
+	r := managed.NewReconciler(errs.Manager(mgr),
+		resource.ManagedKind(v1alpha1.InventoryGroupVersionKind),
+		managed.WithExternalConnecter(errs.NewConnecter(conn)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(o.Timeout),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(recorder))
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Inventory{}, builder.WithPredicates(filter.Changes()))
+	b, err := dependents.Watch(mgr, b, o, &v1alpha1.Inventory{}, func() resource.ManagedList { return &v1alpha1.InventoryList{} }, nil)
+	if err != nil {
+		return err
+	}
+	return b.Complete(o.Reconciler(mgr, v1alpha1.InventoryGroupVersionKind, r))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube         client.Client
+	usage        resource.Tracker
+	newServiceFn func(clients.Config) bitbucket.InventoryClientAPI
+	httpLog      logging.Logger
+	clientLog    logging.Logger
+	warnFields   bool
+	configs      *configcache.Cache
+	clock        clock.Clock
+	recorder     event.Recorder
+}
+
+// Connect typically produces an ExternalClient by:
+// 1. Tracking that the managed resource is using a ProviderConfig.
+// 2. Getting the managed resource's ProviderConfig.
+// 3. Getting the credentials specified by the ProviderConfig.
+// 4. Using the credentials to form a client.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Inventory)
+	if !ok {
+		return nil, errors.New(errNotInventory)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	pc := &apisv1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	cfg, err := c.configs.ClientConfig(ctx, c.kube, pc, config.ClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	cfg.HTTPLogger = c.httpLog
+	cfg.Logger = c.clientLog
+	cfg.WarnUnknownFields = c.warnFields
+	return &external{service: c.newServiceFn(cfg), kube: c.kube, clock: c.clock, recorder: c.recorder}, nil
+}
+
+// An external lists the projects and repositories into the status of an
+// Inventory. It never changes the server: the inventory always exists and is
+// up to date, so the managed reconciler never calls Create or Update.
+type external struct {
+	service  bitbucket.InventoryClientAPI
+	kube     client.Reader
+	clock    clock.Clock
+	recorder event.Recorder
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Inventory)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotInventory)
+	}
+
+	// There is nothing to delete, so the finalizer can be removed right away.
+	if meta.WasDeleted(cr) {
+		return managed.ExternalObservation{}, nil
+	}
+
+	if c.due(cr) {
+		var ids map[managedID]bool
+		if cr.Spec.ForProvider.Orphans != nil {
+			var err error
+			if ids, err = c.managedIDs(ctx); err != nil {
+				return managed.ExternalObservation{}, err
+			}
+		}
+		projects, err := c.list(ctx, cr.Spec.ForProvider, ids)
+		if err != nil {
+			return managed.ExternalObservation{}, err
+		}
+		repos, orphans := 0, 0
+		for _, p := range projects {
+			repos += len(p.Repositories)
+			for _, r := range p.Repositories {
+				orphans += len(r.Orphans)
+			}
+		}
+		now := metav1.NewTime(c.clock.Now())
+		listed, truncated := truncate(projects, maxRepositories)
+		cr.Status.AtProvider = v1alpha1.InventoryObservation{
+			Projects:        listed,
+			Truncated:       truncated,
+			ProjectCount:    len(projects),
+			RepositoryCount: repos,
+			ListedAt:        &now,
+		}
+		if cr.Spec.ForProvider.Orphans != nil {
+			cr.Status.AtProvider.OrphanCount = &orphans
+			if orphans > 0 {
+				c.recorder.Event(cr, event.Warning(reasonOrphans, errors.Errorf("Found %d webhooks and access keys without a managed resource, see status.atProvider", orphans)))
+			}
+		}
+	}
+
+	return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+}
+
+// due returns true if the inventory was never listed or its refresh interval
+// has passed since.
+func (c *external) due(cr *v1alpha1.Inventory) bool {
+	listed := cr.Status.AtProvider.ListedAt
+	if listed == nil {
+		return true
+	}
+	interval := defaultRefreshInterval
+	if cr.Spec.ForProvider.RefreshInterval != nil {
+		interval = cr.Spec.ForProvider.RefreshInterval.Duration
+	}
+	return !c.clock.Now().Before(listed.Add(interval))
+}
+
+// truncate returns the projects up to the one with the max-th repository, and
+// whether any projects or repositories were left out.
+func truncate(projects []v1alpha1.ProjectObservation, max int) ([]v1alpha1.ProjectObservation, bool) {
+	repos := 0
+	for i, p := range projects {
+		if repos+len(p.Repositories) > max {
+			if repos == max {
+				return projects[:i], true
+			}
+			p.Repositories = p.Repositories[:max-repos]
+			return append(projects[:i:i], p), true
+		}
+		repos += len(p.Repositories)
+	}
+	return projects, false
+}
+
+// A managedID identifies the external resource of a Webhook or AccessKey.
+type managedID struct {
+	kind string
+	repo bitbucket.Repo
+	id   int
+}
+
+// managedIDs returns the external resources of all Webhooks and AccessKeys,
+// whichever their ProviderConfig, since several ProviderConfigs may point to
+// the same server.
+func (c *external) managedIDs(ctx context.Context) (map[managedID]bool, error) {
+	ids := map[managedID]bool{}
+
+	hooks := &webhookv1alpha1.WebhookList{}
+	if err := c.kube.List(ctx, hooks); err != nil {
+		return nil, errors.Wrap(err, errListManaged)
+	}
+	for i := range hooks.Items {
+		h := &hooks.Items[i]
+		if id, ok, _ := externalname.RepoID(meta.GetExternalName(h), h.Spec.ForProvider.ProjectKey, h.RepoSlug()); ok {
+			ids[managedID{kind: webhookv1alpha1.WebhookKind, repo: h.Repo(), id: id}] = true
+		}
+	}
+
+	keys := &accesskeyv1alpha1.AccessKeyList{}
+	if err := c.kube.List(ctx, keys); err != nil {
+		return nil, errors.Wrap(err, errListManaged)
+	}
+	for i := range keys.Items {
+		k := &keys.Items[i]
+		if id, ok, _ := externalname.RepoID(meta.GetExternalName(k), k.Spec.ForProvider.ProjectKey, k.Spec.ForProvider.RepoName, k.RepoSlug()); ok {
+			ids[managedID{kind: accesskeyv1alpha1.AccessKeyKind, repo: k.Repo(), id: id}] = true
+		}
+	}
+	return ids, nil
+}
+
+// list returns the projects of the parameters with their repositories, sorted
+// by key and slug. The orphans of the repositories are detected against the
+// managed IDs if the parameters ask for them.
+func (c *external) list(ctx context.Context, p v1alpha1.InventoryParameters, ids map[managedID]bool) ([]v1alpha1.ProjectObservation, error) {
+	all, err := c.service.ListProjects(ctx, bitbucket.ProjectFilter{})
+	if err != nil {
+		return nil, errors.Wrap(err, errListProjects)
+	}
+	projects, err := selectProjects(all, p.ProjectKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]v1alpha1.ProjectObservation, 0, len(projects))
+	for _, project := range projects {
+		repos, err := c.service.ListRepositories(ctx, project.Key)
+		if err != nil {
+			return nil, errors.Wrapf(err, errListRepos, project.Key)
+		}
+		sort.Slice(repos, func(i, j int) bool { return repos[i].Slug < repos[j].Slug })
+
+		o := v1alpha1.ProjectObservation{Key: project.Key, Name: project.Name}
+		for _, r := range repos {
+			ro := v1alpha1.RepositoryObservation{ID: r.ID, Slug: r.Slug, Name: r.Name}
+			if p.CountHooksAndKeys || p.Orphans != nil {
+				repo := bitbucket.Repo{ProjectKey: bitbucket.ProjectKey(project.Key), Repo: r.Slug}
+				hooks, keys, err := c.hooksAndKeys(ctx, repo)
+				if err != nil {
+					return nil, err
+				}
+				if p.CountHooksAndKeys {
+					nHooks, nKeys := len(hooks), len(keys)
+					ro.Webhooks, ro.AccessKeys = &nHooks, &nKeys
+				}
+				if p.Orphans != nil {
+					ro.Orphans = orphans(*p.Orphans, ids, repo, hooks, keys)
+				}
+			}
+			o.Repositories = append(o.Repositories, ro)
+		}
+		ret = append(ret, o)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Key < ret[j].Key })
+	return ret, nil
+}
+
+// selectProjects returns the projects with the keys, or all projects if no
+// keys are given.
+func selectProjects(all []bitbucket.Project, keys []string) ([]bitbucket.Project, error) {
+	if len(keys) == 0 {
+		return all, nil
+	}
+	byKey := make(map[string]bitbucket.Project, len(all))
+	for _, p := range all {
+		byKey[bitbucket.ProjectKey(p.Key)] = p
+	}
+	ret := make([]bitbucket.Project, 0, len(keys))
+	for _, k := range keys {
+		p, ok := byKey[bitbucket.ProjectKey(k)]
+		if !ok {
+			return nil, errors.Errorf(errNoProject, k)
+		}
+		ret = append(ret, p)
+	}
+	return ret, nil
+}
+
+// hooksAndKeys returns the webhooks and access keys of the repository.
+func (c *external) hooksAndKeys(ctx context.Context, repo bitbucket.Repo) ([]bitbucket.Webhook, []bitbucket.AccessKey, error) {
+	hooks, err := c.service.ListWebhooks(ctx, repo, bitbucket.WebhookFilter{})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, errListWebhooks, repo.ProjectKey, repo.Repo)
+	}
+	keys, err := c.service.ListAccessKeys(ctx, repo, bitbucket.AccessKeyFilter{})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, errListKeys, repo.ProjectKey, repo.Repo)
+	}
+	return hooks, keys, nil
+}
+
+// orphans returns the webhooks and access keys of the repository with the
+// markers of the detection which are not managed, sorted by kind and ID.
+func orphans(d v1alpha1.OrphanDetection, ids map[managedID]bool, repo bitbucket.Repo, hooks []bitbucket.Webhook, keys []bitbucket.AccessKey) []v1alpha1.OrphanObservation {
+	var ret []v1alpha1.OrphanObservation
+	for _, k := range keys {
+		if strings.HasPrefix(k.Label, d.AccessKeyLabelPrefix) && !ids[managedID{kind: accesskeyv1alpha1.AccessKeyKind, repo: repo, id: k.ID}] {
+			ret = append(ret, v1alpha1.OrphanObservation{Kind: accesskeyv1alpha1.AccessKeyKind, ID: k.ID, Name: k.Label})
+		}
+	}
+	for _, h := range hooks {
+		if strings.HasPrefix(h.Name, d.WebhookNamePrefix) && !ids[managedID{kind: webhookv1alpha1.WebhookKind, repo: repo, id: h.ID}] {
+			ret = append(ret, v1alpha1.OrphanObservation{Kind: webhookv1alpha1.WebhookKind, ID: h.ID, Name: h.Name})
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Kind != ret[j].Kind {
+			return ret[i].Kind < ret[j].Kind
+		}
+		return ret[i].ID < ret[j].ID
+	})
+	return ret
+}
+
+// Create does nothing, an inventory only observes the server.
+func (c *external) Create(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+	return managed.ExternalCreation{}, nil
+}
+
+// Update does nothing, an inventory only observes the server.
+func (c *external) Update(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+	return managed.ExternalUpdate{}, nil
+}
+
+// Delete does nothing, an inventory only observes the server.
+func (c *external) Delete(_ context.Context, _ resource.Managed) error {
+	return nil
+}
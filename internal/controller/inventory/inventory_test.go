@@ -0,0 +1,398 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	accesskeyv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/accesskey/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/inventory/v1alpha1"
+	webhookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clock"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket/fake"
+)
+
+type fakeClient struct {
+	*fake.MockProjectClient
+	*fake.MockWebhookClient
+	*fake.MockKeyClient
+}
+
+func newFakeClient() fakeClient {
+	return fakeClient{
+		MockProjectClient: &fake.MockProjectClient{
+			MockListProjects: func(_ context.Context, _ bitbucket.ProjectFilter) ([]bitbucket.Project, error) {
+				return []bitbucket.Project{{Key: "PRJ", Name: "Project"}, {Key: "OPS", Name: "Operations"}}, nil
+			},
+		},
+		MockWebhookClient: &fake.MockWebhookClient{
+			MockListRepositories: func(_ context.Context, projectKey string) ([]bitbucket.Repository, error) {
+				if projectKey == "OPS" {
+					return nil, nil
+				}
+				return []bitbucket.Repository{
+					{ID: 2, Slug: "web", Name: "Web", ProjectKey: projectKey},
+					{ID: 1, Slug: "api", Name: "API", ProjectKey: projectKey},
+				}, nil
+			},
+			MockListWebhooks: func(_ context.Context, _ bitbucket.Repo, _ bitbucket.WebhookFilter) ([]bitbucket.Webhook, error) {
+				return []bitbucket.Webhook{{ID: 3}}, nil
+			},
+		},
+		MockKeyClient: &fake.MockKeyClient{
+			MockListAccessKeys: func(_ context.Context, _ bitbucket.Repo, _ bitbucket.AccessKeyFilter) ([]bitbucket.AccessKey, error) {
+				return []bitbucket.AccessKey{{ID: 4}, {ID: 5}}, nil
+			},
+		},
+	}
+}
+
+type resourceModifier func(*v1alpha1.Inventory)
+
+func withParameters(p v1alpha1.InventoryParameters) resourceModifier {
+	return func(r *v1alpha1.Inventory) { r.Spec.ForProvider = p }
+}
+
+func withObservation(o v1alpha1.InventoryObservation) resourceModifier {
+	return func(r *v1alpha1.Inventory) { r.Status.AtProvider = o }
+}
+
+func instance(rm ...resourceModifier) *v1alpha1.Inventory {
+	r := &v1alpha1.Inventory{}
+	for _, m := range rm {
+		m(r)
+	}
+	return r
+}
+
+func count(n int) *int { return &n }
+
+// An eventRecorder records the events of the resources.
+type eventRecorder struct {
+	events []event.Event
+}
+
+func (r *eventRecorder) Event(_ runtime.Object, e event.Event) {
+	r.events = append(r.events, e)
+}
+
+func (r *eventRecorder) WithAnnotations(_ ...string) event.Recorder {
+	return r
+}
+
+// managedList lists a Webhook managing webhook 3 of PRJ/api and an
+// AccessKey managing access key 4 of PRJ/web.
+func managedList(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+	switch l := obj.(type) {
+	case *webhookv1alpha1.WebhookList:
+		h := webhookv1alpha1.Webhook{}
+		h.Spec.ForProvider.ProjectKey = "prj"
+		h.Spec.ForProvider.RepoName = "API"
+		meta.SetExternalName(&h, "3")
+		l.Items = []webhookv1alpha1.Webhook{h}
+	case *accesskeyv1alpha1.AccessKeyList:
+		k := accesskeyv1alpha1.AccessKey{}
+		k.Spec.ForProvider.ProjectKey = "PRJ"
+		k.Spec.ForProvider.RepoName = "web"
+		meta.SetExternalName(&k, "PRJ/web/4")
+		l.Items = []accesskeyv1alpha1.AccessKey{k}
+	}
+	return nil
+}
+
+var _ managed.ExternalConnecter = &connector{}
+var _ managed.ExternalClient = &external{}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	listed := metav1.NewTime(now.Add(-30 * time.Minute))
+	nowTime := metav1.NewTime(now)
+
+	listedAll := v1alpha1.InventoryObservation{
+		Projects: []v1alpha1.ProjectObservation{
+			{Key: "OPS", Name: "Operations"},
+			{Key: "PRJ", Name: "Project", Repositories: []v1alpha1.RepositoryObservation{
+				{ID: 1, Slug: "api", Name: "API"},
+				{ID: 2, Slug: "web", Name: "Web"},
+			}},
+		},
+		ProjectCount:    2,
+		RepositoryCount: 2,
+		ListedAt:        &nowTime,
+	}
+	stale := v1alpha1.InventoryObservation{ProjectCount: 1, ListedAt: &listed}
+
+	type want struct {
+		cr  *v1alpha1.Inventory
+		o   managed.ExternalObservation
+		err error
+	}
+
+	orphans := &v1alpha1.OrphanDetection{WebhookNamePrefix: "crossplane-"}
+
+	cases := map[string]struct {
+		cr     *v1alpha1.Inventory
+		client func() fakeClient
+		kube   client.Reader
+		want   want
+		events []event.Event
+	}{
+		"ListsAllProjects": {
+			cr:     instance(),
+			client: newFakeClient,
+			want: want{
+				cr: instance(withObservation(listedAll)),
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"CountsHooksAndKeys": {
+			cr:     instance(withParameters(v1alpha1.InventoryParameters{ProjectKeys: []string{"PRJ"}, CountHooksAndKeys: true})),
+			client: newFakeClient,
+			want: want{
+				cr: instance(
+					withParameters(v1alpha1.InventoryParameters{ProjectKeys: []string{"PRJ"}, CountHooksAndKeys: true}),
+					withObservation(v1alpha1.InventoryObservation{
+						Projects: []v1alpha1.ProjectObservation{
+							{Key: "PRJ", Name: "Project", Repositories: []v1alpha1.RepositoryObservation{
+								{ID: 1, Slug: "api", Name: "API", Webhooks: count(1), AccessKeys: count(2)},
+								{ID: 2, Slug: "web", Name: "Web", Webhooks: count(1), AccessKeys: count(2)},
+							}},
+						},
+						ProjectCount:    1,
+						RepositoryCount: 2,
+						ListedAt:        &nowTime,
+					})),
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"DetectsOrphans": {
+			cr: instance(withParameters(v1alpha1.InventoryParameters{ProjectKeys: []string{"PRJ"}, Orphans: orphans})),
+			client: func() fakeClient {
+				c := newFakeClient()
+				c.MockListWebhooks = func(_ context.Context, _ bitbucket.Repo, _ bitbucket.WebhookFilter) ([]bitbucket.Webhook, error) {
+					return []bitbucket.Webhook{{ID: 3, Name: "crossplane-ci"}, {ID: 6, Name: "manual"}}, nil
+				}
+				return c
+			},
+			kube: &test.MockClient{MockList: managedList},
+			want: want{
+				cr: instance(
+					withParameters(v1alpha1.InventoryParameters{ProjectKeys: []string{"PRJ"}, Orphans: orphans}),
+					withObservation(v1alpha1.InventoryObservation{
+						Projects: []v1alpha1.ProjectObservation{
+							{Key: "PRJ", Name: "Project", Repositories: []v1alpha1.RepositoryObservation{
+								{ID: 1, Slug: "api", Name: "API", Orphans: []v1alpha1.OrphanObservation{
+									{Kind: "AccessKey", ID: 4},
+									{Kind: "AccessKey", ID: 5},
+								}},
+								{ID: 2, Slug: "web", Name: "Web", Orphans: []v1alpha1.OrphanObservation{
+									{Kind: "AccessKey", ID: 5},
+									{Kind: "Webhook", ID: 3, Name: "crossplane-ci"},
+								}},
+							}},
+						},
+						ProjectCount:    1,
+						RepositoryCount: 2,
+						OrphanCount:     count(4),
+						ListedAt:        &nowTime,
+					})),
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+			events: []event.Event{event.Warning(reasonOrphans, errors.New("Found 4 webhooks and access keys without a managed resource, see status.atProvider"))},
+		},
+		"ListManagedFailed": {
+			cr:     instance(withParameters(v1alpha1.InventoryParameters{Orphans: orphans})),
+			client: newFakeClient,
+			kube:   &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+			want: want{
+				cr:  instance(withParameters(v1alpha1.InventoryParameters{Orphans: orphans})),
+				err: errors.Wrap(errBoom, errListManaged),
+			},
+		},
+		"NotDue": {
+			cr: instance(
+				withParameters(v1alpha1.InventoryParameters{RefreshInterval: &metav1.Duration{Duration: time.Hour}}),
+				withObservation(stale)),
+			client: func() fakeClient {
+				c := newFakeClient()
+				c.MockListProjects = func(_ context.Context, _ bitbucket.ProjectFilter) ([]bitbucket.Project, error) { return nil, errBoom }
+				return c
+			},
+			want: want{
+				cr: instance(
+					withParameters(v1alpha1.InventoryParameters{RefreshInterval: &metav1.Duration{Duration: time.Hour}}),
+					withObservation(stale)),
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"NotDueByDefault": {
+			cr: instance(withObservation(stale)),
+			client: func() fakeClient {
+				c := newFakeClient()
+				c.MockListProjects = func(_ context.Context, _ bitbucket.ProjectFilter) ([]bitbucket.Project, error) { return nil, errBoom }
+				return c
+			},
+			want: want{
+				cr: instance(withObservation(stale)),
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"Due": {
+			cr: instance(
+				withParameters(v1alpha1.InventoryParameters{RefreshInterval: &metav1.Duration{Duration: 30 * time.Minute}}),
+				withObservation(stale)),
+			client: newFakeClient,
+			want: want{
+				cr: instance(
+					withParameters(v1alpha1.InventoryParameters{RefreshInterval: &metav1.Duration{Duration: 30 * time.Minute}}),
+					withObservation(listedAll)),
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"UnknownProject": {
+			cr:     instance(withParameters(v1alpha1.InventoryParameters{ProjectKeys: []string{"NOPE"}})),
+			client: newFakeClient,
+			want: want{
+				cr:  instance(withParameters(v1alpha1.InventoryParameters{ProjectKeys: []string{"NOPE"}})),
+				err: errors.Errorf(errNoProject, "NOPE"),
+			},
+		},
+		"Deleted": {
+			cr: instance(func(r *v1alpha1.Inventory) { r.SetDeletionTimestamp(&nowTime) }),
+			client: func() fakeClient {
+				c := newFakeClient()
+				c.MockListProjects = func(_ context.Context, _ bitbucket.ProjectFilter) ([]bitbucket.Project, error) { return nil, errBoom }
+				return c
+			},
+			want: want{
+				cr: instance(func(r *v1alpha1.Inventory) { r.SetDeletionTimestamp(&nowTime) }),
+			},
+		},
+		"ListFailed": {
+			cr: instance(),
+			client: func() fakeClient {
+				c := newFakeClient()
+				c.MockListRepositories = func(_ context.Context, _ string) ([]bitbucket.Repository, error) { return nil, errBoom }
+				return c
+			},
+			want: want{
+				cr:  instance(),
+				err: errors.Wrapf(errBoom, errListRepos, "PRJ"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			recorder := &eventRecorder{}
+			e := external{service: tc.client(), kube: tc.kube, clock: clock.Fixed(now), recorder: recorder}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\ne.Observe(...): -want error, +got error:\n%s\n", diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\ne.Observe(...): -want, +got:\n%s\n", diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.cr, test.EquateConditions()); diff != "" {
+				t.Errorf("\ne.Observe(...): -want cr, +got cr:\n%s\n", diff)
+			}
+			if diff := cmp.Diff(tc.events, recorder.events, test.EquateErrors()); diff != "" {
+				t.Errorf("\ne.Observe(...): -want events, +got events:\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	repos := func(n int) []v1alpha1.RepositoryObservation {
+		ret := make([]v1alpha1.RepositoryObservation, n)
+		for i := range ret {
+			ret[i].ID = i + 1
+		}
+		return ret
+	}
+	projects := []v1alpha1.ProjectObservation{
+		{Key: "A", Repositories: repos(2)},
+		{Key: "B"},
+		{Key: "C", Repositories: repos(3)},
+		{Key: "D", Repositories: repos(1)},
+	}
+
+	type want struct {
+		projects  []v1alpha1.ProjectObservation
+		truncated bool
+	}
+
+	cases := map[string]struct {
+		max  int
+		want want
+	}{
+		"All": {
+			max:  6,
+			want: want{projects: projects},
+		},
+		"WithinProject": {
+			max: 3,
+			want: want{
+				projects: []v1alpha1.ProjectObservation{
+					{Key: "A", Repositories: repos(2)},
+					{Key: "B"},
+					{Key: "C", Repositories: repos(1)},
+				},
+				truncated: true,
+			},
+		},
+		"AfterProject": {
+			max: 5,
+			want: want{
+				projects: []v1alpha1.ProjectObservation{
+					{Key: "A", Repositories: repos(2)},
+					{Key: "B"},
+					{Key: "C", Repositories: repos(3)},
+				},
+				truncated: true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, truncated := truncate(projects, tc.max)
+			if diff := cmp.Diff(tc.want.projects, got); diff != "" {
+				t.Errorf("truncate(...): -want projects, +got projects:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.truncated, truncated); diff != "" {
+				t.Errorf("truncate(...): -want truncated, +got truncated:\n%s", diff)
+			}
+		})
+	}
+}
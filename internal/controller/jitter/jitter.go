@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jitter spreads the reconciles of managed resources over time, so
+// that a restart of the provider doesn't observe all of them at once and
+// their polls don't stay in lockstep afterwards.
+package jitter
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clock"
+)
+
+// A Reconciler delays the first reconcile of managed resources which existed
+// before the provider started, and the polls of all managed resources, by a
+// random duration up to the jitter. Only the reconciles within the jitter
+// after the start are delayed; later first reconciles are already spread by
+// the rate limiter of the controllers.
+type Reconciler struct {
+	client     client.Client
+	newManaged func() resource.Managed
+	jitter     time.Duration
+	reconciler reconcile.Reconciler
+	clock      clock.Clock
+	// started is when the reconciler was created, i.e. the provider
+	// started
+	started time.Time
+	random  func(max time.Duration) time.Duration
+
+	mu sync.Mutex
+	// delayed are the managed resources whose first reconcile was delayed
+	// and which were not reconciled since. Nil once the jitter after the
+	// start has passed.
+	delayed map[types.NamespacedName]bool
+}
+
+// NewReconciler wraps the supplied reconciler of managed resources of the
+// supplied kind so that their reconciles are spread over the jitter. It
+// returns the supplied reconciler if the jitter is not positive.
+func NewReconciler(m ctrl.Manager, of resource.ManagedKind, jitter time.Duration, r reconcile.Reconciler) reconcile.Reconciler {
+	if jitter <= 0 {
+		return r
+	}
+	nm := func() resource.Managed {
+		return resource.MustCreateObject(schema.GroupVersionKind(of), m.GetScheme()).(resource.Managed)
+	}
+	return &Reconciler{
+		client:     m.GetClient(),
+		newManaged: nm,
+		jitter:     jitter,
+		reconciler: r,
+		clock:      clock.System,
+		started:    clock.System.Now(),
+		random:     random,
+		delayed:    map[types.NamespacedName]bool{},
+	}
+}
+
+// random returns a random duration in [0, max).
+func random(max time.Duration) time.Duration {
+	return time.Duration(rand.Int63n(int64(max))) // nolint:gosec // No need for a secure random number
+}
+
+// Reconcile a managed resource, unless it is the first reconcile of a managed
+// resource which existed before the start, which is requeued after a random
+// delay instead.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	if r.delay(ctx, req) {
+		return reconcile.Result{RequeueAfter: r.random(r.jitter)}, nil
+	}
+
+	res, err := r.reconciler.Reconcile(ctx, req)
+	if err == nil && res.RequeueAfter > 0 {
+		res.RequeueAfter += r.random(r.jitter)
+	}
+	return res, err
+}
+
+// delay returns true if the reconcile of the request is the first one of a
+// managed resource which existed before the start, and the jitter since the
+// start has not passed. The delayed resources are forgotten once they are
+// reconciled, and all of them once the jitter has passed.
+func (r *Reconciler) delay(ctx context.Context, req reconcile.Request) bool {
+	r.mu.Lock()
+	if !r.clock.Now().Before(r.started.Add(r.jitter)) {
+		r.delayed = nil
+		r.mu.Unlock()
+		return false
+	}
+	if r.delayed[req.NamespacedName] {
+		delete(r.delayed, req.NamespacedName)
+		r.mu.Unlock()
+		return false
+	}
+	r.mu.Unlock()
+
+	mg := r.newManaged()
+	if err := r.client.Get(ctx, req.NamespacedName, mg); err != nil {
+		return false
+	}
+	if !mg.GetCreationTimestamp().Time.Before(r.started) || meta.WasDeleted(mg) {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.delayed == nil {
+		return false
+	}
+	r.delayed[req.NamespacedName] = true
+	return true
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jitter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	webhookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clock"
+)
+
+func TestReconcile(t *testing.T) {
+	started := time.Unix(1000, 0)
+	before := metav1.NewTime(started.Add(-time.Hour))
+	after := metav1.NewTime(started.Add(time.Second))
+	errBoom := errors.New("boom")
+
+	type want struct {
+		result     reconcile.Result
+		err        error
+		reconciled bool
+		delayed    bool
+		forgotten  bool
+	}
+
+	cases := map[string]struct {
+		created metav1.Time
+		deleted bool
+		getErr  error
+		delayed bool
+		now     time.Time
+		result  reconcile.Result
+		err     error
+		want    want
+	}{
+		"ExistedBeforeStart": {
+			created: before,
+			want:    want{result: reconcile.Result{RequeueAfter: 10 * time.Second}, delayed: true},
+		},
+		"CreatedAfterStart": {
+			created: after,
+			result:  reconcile.Result{RequeueAfter: time.Minute},
+			want:    want{result: reconcile.Result{RequeueAfter: time.Minute + 10*time.Second}, reconciled: true},
+		},
+		"Deleted": {
+			created: before,
+			deleted: true,
+			want:    want{reconciled: true},
+		},
+		"NotFound": {
+			getErr: kerrors.NewNotFound(schema.GroupResource{}, "example"),
+			want:   want{reconciled: true},
+		},
+		"Delayed": {
+			created: before,
+			delayed: true,
+			result:  reconcile.Result{RequeueAfter: time.Minute},
+			want:    want{result: reconcile.Result{RequeueAfter: time.Minute + 10*time.Second}, reconciled: true},
+		},
+		"NoRequeue": {
+			created: before,
+			delayed: true,
+			want:    want{reconciled: true},
+		},
+		"Error": {
+			created: before,
+			delayed: true,
+			err:     errBoom,
+			want:    want{err: errBoom, reconciled: true},
+		},
+		"JitterPassed": {
+			created: before,
+			delayed: true,
+			now:     started.Add(2 * time.Minute),
+			result:  reconcile.Result{RequeueAfter: time.Minute},
+			want:    want{result: reconcile.Result{RequeueAfter: time.Minute + 10*time.Second}, reconciled: true, forgotten: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "example"}}
+			now := tc.now
+			if now.IsZero() {
+				now = started.Add(time.Second)
+			}
+			reconciled := false
+			r := &Reconciler{
+				client: &test.MockClient{
+					MockGet: test.NewMockGetFn(tc.getErr, func(obj client.Object) error {
+						obj.SetCreationTimestamp(tc.created)
+						if tc.deleted {
+							obj.SetDeletionTimestamp(&after)
+						}
+						return nil
+					}),
+				},
+				newManaged: func() resource.Managed { return &webhookv1alpha1.Webhook{} },
+				jitter:     time.Minute,
+				reconciler: reconcile.Func(func(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+					reconciled = true
+					return tc.result, tc.err
+				}),
+				clock:   clock.Fixed(now),
+				started: started,
+				random:  func(time.Duration) time.Duration { return 10 * time.Second },
+				delayed: map[types.NamespacedName]bool{},
+			}
+			if tc.delayed {
+				r.delayed[req.NamespacedName] = true
+			}
+
+			got, err := r.Reconcile(context.Background(), req)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Reconcile(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("Reconcile(...): -want, +got:\n%s", diff)
+			}
+			if reconciled != tc.want.reconciled {
+				t.Errorf("Reconcile(...): want reconciled %t, got %t", tc.want.reconciled, reconciled)
+			}
+			if delayed := r.delayed[req.NamespacedName]; delayed != tc.want.delayed {
+				t.Errorf("Reconcile(...): want delayed %t, got %t", tc.want.delayed, delayed)
+			}
+			if forgotten := r.delayed == nil; forgotten != tc.want.forgotten {
+				t.Errorf("Reconcile(...): want all delayed resources forgotten %t, got %t", tc.want.forgotten, forgotten)
+			}
+		})
+	}
+}
+
+func TestRandom(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if d := random(time.Second); d < 0 || d >= time.Second {
+			t.Fatalf("random(1s): want [0, 1s), got %s", d)
+		}
+	}
+}
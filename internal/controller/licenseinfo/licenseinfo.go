@@ -0,0 +1,189 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package licenseinfo
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/licenseinfo/v1alpha1"
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/apierror"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/conditions"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/config"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/configcache"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/dependents"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/filter"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/setup"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+const (
+	errNotLicenseInfo = "managed resource is not a LicenseInfo custom resource"
+	errTrackPCUsage   = "cannot track ProviderConfig usage"
+	errGetPC          = "cannot get ProviderConfig"
+
+	errGetFailed = "cannot get license from bitbucket API"
+)
+
+// Setup adds a controller that reconciles LicenseInfo managed resources.
+func Setup(mgr ctrl.Manager, o setup.Options) error {
+	name := managed.ControllerName(v1alpha1.LicenseInfoGroupKind)
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	conn := conditions.NewConnecter(&connector{
+		kube:         mgr.GetClient(),
+		usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+		newServiceFn: clients.NewLicenseClient,
+		httpLog:      o.HTTPLogger(name),
+		clientLog:    o.ClientLogger(name),
+		warnFields:   o.WarnUnknownFields,
+		configs:      o.ConfigCache,
+	})
+
+	errs := apierror.NewTracker()
+	r := managed.NewReconciler(errs.Manager(mgr),
+		resource.ManagedKind(v1alpha1.LicenseInfoGroupVersionKind),
+		managed.WithExternalConnecter(errs.NewConnecter(conn)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(o.Timeout),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(recorder))
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.LicenseInfo{}, builder.WithPredicates(filter.Changes()))
+	b, err := dependents.Watch(mgr, b, o, &v1alpha1.LicenseInfo{}, func() resource.ManagedList { return &v1alpha1.LicenseInfoList{} }, nil)
+	if err != nil {
+		return err
+	}
+	return b.Complete(o.Reconciler(mgr, v1alpha1.LicenseInfoGroupVersionKind, r))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube         client.Client
+	usage        resource.Tracker
+	newServiceFn func(clients.Config) bitbucket.LicenseClientAPI
+	httpLog      logging.Logger
+	clientLog    logging.Logger
+	warnFields   bool
+	configs      *configcache.Cache
+}
+
+// Connect typically produces an ExternalClient by:
+// 1. Tracking that the managed resource is using a ProviderConfig.
+// 2. Getting the managed resource's ProviderConfig.
+// 3. Getting the credentials specified by the ProviderConfig.
+// 4. Using the credentials to form a client.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.LicenseInfo)
+	if !ok {
+		return nil, errors.New(errNotLicenseInfo)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	pc := &apisv1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	cfg, err := c.configs.ClientConfig(ctx, c.kube, pc, config.ClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	cfg.HTTPLogger = c.httpLog
+	cfg.Logger = c.clientLog
+	cfg.WarnUnknownFields = c.warnFields
+	return &external{service: c.newServiceFn(cfg)}, nil
+}
+
+// An external observes the license of the server into the status of a
+// LicenseInfo. It never changes the server: the license always exists and is
+// up to date, so the managed reconciler never calls Create or Update.
+type external struct {
+	service bitbucket.LicenseClientAPI
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.LicenseInfo)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotLicenseInfo)
+	}
+
+	// There is nothing to delete, so the finalizer can be removed right away.
+	if meta.WasDeleted(cr) {
+		return managed.ExternalObservation{}, nil
+	}
+
+	l, err := c.service.GetLicense(ctx)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetFailed)
+	}
+	cr.Status.AtProvider = v1alpha1.LicenseInfoObservation{
+		CurrentUsers:          l.CurrentUsers,
+		MaximumUsers:          l.MaximumUsers,
+		UnlimitedUsers:        l.UnlimitedUsers,
+		ExpiryDate:            date(l.ExpiryDate),
+		MaintenanceExpiryDate: date(l.MaintenanceExpiryDate),
+	}
+
+	return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+}
+
+// date returns the time, or nil for the zero time.
+func date(t time.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
+	}
+	mt := metav1.NewTime(t)
+	return &mt
+}
+
+// Create does nothing, a license info only observes the server.
+func (c *external) Create(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+	return managed.ExternalCreation{}, nil
+}
+
+// Update does nothing, a license info only observes the server.
+func (c *external) Update(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+	return managed.ExternalUpdate{}, nil
+}
+
+// Delete does nothing, a license info only observes the server.
+func (c *external) Delete(_ context.Context, _ resource.Managed) error {
+	return nil
+}
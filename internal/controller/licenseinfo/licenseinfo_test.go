@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package licenseinfo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/licenseinfo/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket/fake"
+)
+
+var _ managed.ExternalConnecter = &connector{}
+var _ managed.ExternalClient = &external{}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+	expiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	mexpiry := metav1.NewTime(expiry)
+
+	type want struct {
+		o   v1alpha1.LicenseInfoObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		license bitbucket.License
+		err     error
+		want    want
+	}{
+		"Observed": {
+			license: bitbucket.License{MaximumUsers: 500, CurrentUsers: 412, ExpiryDate: expiry},
+			want: want{o: v1alpha1.LicenseInfoObservation{
+				CurrentUsers: 412,
+				MaximumUsers: 500,
+				ExpiryDate:   &mexpiry,
+			}},
+		},
+		"Unlimited": {
+			license: bitbucket.License{UnlimitedUsers: true, CurrentUsers: 3, MaintenanceExpiryDate: expiry},
+			want: want{o: v1alpha1.LicenseInfoObservation{
+				CurrentUsers:          3,
+				UnlimitedUsers:        true,
+				MaintenanceExpiryDate: &mexpiry,
+			}},
+		},
+		"GetFailed": {
+			err:  errBoom,
+			want: want{err: errors.Wrap(errBoom, errGetFailed)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{service: &fake.MockLicenseClient{
+				MockGetLicense: func(_ context.Context) (bitbucket.License, error) {
+					return tc.license, tc.err
+				},
+			}}
+			cr := &v1alpha1.LicenseInfo{}
+			got, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("Observe(...): -want error, +got error:\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if !got.ResourceExists || !got.ResourceUpToDate {
+				t.Errorf("Observe(...): want existing and up to date, got %+v", got)
+			}
+			if diff := cmp.Diff(tc.want.o, cr.Status.AtProvider); diff != "" {
+				t.Errorf("Observe(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
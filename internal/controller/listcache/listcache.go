@@ -0,0 +1,211 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package listcache observes the webhooks and access keys of a repository
+// with a single list request which is cached briefly, instead of one request
+// per managed resource. This keeps the number of requests linear in the
+// repositories rather than in the managed resources.
+package listcache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clock"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+// A Cache holds the webhooks and access keys of repositories by ID for a
+// while after listing them. It is shared by all controllers. A nil Cache
+// caches nothing.
+type Cache struct {
+	ttl   time.Duration
+	clock clock.Clock
+
+	mu      sync.Mutex
+	entries map[key]*entry
+}
+
+// key of the list of a kind of objects of a repository, as seen with the
+// credentials of a ProviderConfig
+type key struct {
+	providerConfig string
+	kind           string
+	repo           bitbucket.Repo
+}
+
+// entry is a list of objects by ID. Its mutex is held while listing, so that
+// concurrent reconciles of a repository wait for a single list request.
+type entry struct {
+	mu     sync.Mutex
+	listed time.Time
+	byID   map[int]interface{}
+}
+
+// Kinds of cached objects
+const (
+	kindWebhook   = "webhook"
+	kindAccessKey = "accesskey"
+)
+
+// New returns a Cache which keeps lists for the ttl, telling the time by the
+// clock.
+func New(ttl time.Duration, clk clock.Clock) *Cache {
+	return &Cache{ttl: ttl, clock: clk, entries: map[key]*entry{}}
+}
+
+// get returns the object with the ID from the cached list, listing the
+// objects again if the list expired. It returns bitbucket.ErrNotFound if the
+// list has no object with the ID.
+func (c *Cache) get(k key, id int, list func() (map[int]interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	e, ok := c.entries[k]
+	if !ok {
+		e = &entry{}
+		c.entries[k] = e
+	}
+	c.mu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.byID == nil || !c.clock.Now().Before(e.listed.Add(c.ttl)) {
+		byID, err := list()
+		if err != nil {
+			return nil, err
+		}
+		e.byID, e.listed = byID, c.clock.Now()
+	}
+	obj, ok := e.byID[id]
+	if !ok {
+		return nil, bitbucket.ErrNotFound
+	}
+	return obj, nil
+}
+
+// invalidate drops the cached list, so that the next get lists the objects
+// again, e.g. after one of them changed.
+func (c *Cache) invalidate(k key) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, k)
+}
+
+// Webhooks returns a client which gets webhooks from the cached lists of
+// their repositories, as seen by the ProviderConfig. It returns the client
+// itself if the Cache is nil.
+func (c *Cache) Webhooks(client bitbucket.WebhookClientAPI, providerConfig string) bitbucket.WebhookClientAPI {
+	if c == nil {
+		return client
+	}
+	return &webhooks{WebhookClientAPI: client, cache: c, providerConfig: providerConfig}
+}
+
+type webhooks struct {
+	bitbucket.WebhookClientAPI
+	cache          *Cache
+	providerConfig string
+}
+
+func (w *webhooks) key(repo bitbucket.Repo) key {
+	return key{providerConfig: w.providerConfig, kind: kindWebhook, repo: repo}
+}
+
+func (w *webhooks) GetWebhook(ctx context.Context, repo bitbucket.Repo, id int) (bitbucket.Webhook, error) {
+	obj, err := w.cache.get(w.key(repo), id, func() (map[int]interface{}, error) {
+		hooks, err := w.WebhookClientAPI.ListWebhooks(ctx, repo, bitbucket.WebhookFilter{})
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[int]interface{}, len(hooks))
+		for _, h := range hooks {
+			byID[h.ID] = h
+		}
+		return byID, nil
+	})
+	if err != nil {
+		return bitbucket.Webhook{}, err
+	}
+	return obj.(bitbucket.Webhook), nil
+}
+
+func (w *webhooks) CreateWebhook(ctx context.Context, repo bitbucket.Repo, hook bitbucket.Webhook) (bitbucket.Webhook, error) {
+	defer w.cache.invalidate(w.key(repo))
+	return w.WebhookClientAPI.CreateWebhook(ctx, repo, hook)
+}
+
+func (w *webhooks) UpdateWebhook(ctx context.Context, repo bitbucket.Repo, id int, hook bitbucket.Webhook) (bitbucket.Webhook, error) {
+	defer w.cache.invalidate(w.key(repo))
+	return w.WebhookClientAPI.UpdateWebhook(ctx, repo, id, hook)
+}
+
+func (w *webhooks) DeleteWebhook(ctx context.Context, repo bitbucket.Repo, id int) error {
+	defer w.cache.invalidate(w.key(repo))
+	return w.WebhookClientAPI.DeleteWebhook(ctx, repo, id)
+}
+
+// AccessKeys returns a client which gets access keys from the cached lists
+// of their repositories, as seen by the ProviderConfig. It returns the
+// client itself if the Cache is nil.
+func (c *Cache) AccessKeys(client bitbucket.KeyClientAPI, providerConfig string) bitbucket.KeyClientAPI {
+	if c == nil {
+		return client
+	}
+	return &accessKeys{KeyClientAPI: client, cache: c, providerConfig: providerConfig}
+}
+
+type accessKeys struct {
+	bitbucket.KeyClientAPI
+	cache          *Cache
+	providerConfig string
+}
+
+func (a *accessKeys) key(repo bitbucket.Repo) key {
+	return key{providerConfig: a.providerConfig, kind: kindAccessKey, repo: repo}
+}
+
+func (a *accessKeys) GetAccessKey(ctx context.Context, repo bitbucket.Repo, id int) (bitbucket.AccessKey, error) {
+	obj, err := a.cache.get(a.key(repo), id, func() (map[int]interface{}, error) {
+		keys, err := a.KeyClientAPI.ListAccessKeys(ctx, repo, bitbucket.AccessKeyFilter{})
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[int]interface{}, len(keys))
+		for _, k := range keys {
+			byID[k.ID] = k
+		}
+		return byID, nil
+	})
+	if err != nil {
+		return bitbucket.AccessKey{}, err
+	}
+	return obj.(bitbucket.AccessKey), nil
+}
+
+func (a *accessKeys) CreateAccessKey(ctx context.Context, repo bitbucket.Repo, k bitbucket.AccessKey) (bitbucket.AccessKey, error) {
+	defer a.cache.invalidate(a.key(repo))
+	return a.KeyClientAPI.CreateAccessKey(ctx, repo, k)
+}
+
+func (a *accessKeys) UpdateAccessKeyPermission(ctx context.Context, repo bitbucket.Repo, id int, permission string) error {
+	defer a.cache.invalidate(a.key(repo))
+	return a.KeyClientAPI.UpdateAccessKeyPermission(ctx, repo, id, permission)
+}
+
+func (a *accessKeys) DeleteAccessKey(ctx context.Context, repo bitbucket.Repo, id int) error {
+	defer a.cache.invalidate(a.key(repo))
+	return a.KeyClientAPI.DeleteAccessKey(ctx, repo, id)
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clock"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket/fake"
+)
+
+var repo = bitbucket.Repo{ProjectKey: "PRJ", Repo: "repo"}
+
+func TestWebhooks(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		hook  bitbucket.Webhook
+		err   error
+		lists int
+	}
+
+	cases := map[string]struct {
+		// do gets webhooks with the client and may advance the clock
+		do   func(ctx context.Context, c bitbucket.WebhookClientAPI, now *time.Time) (bitbucket.Webhook, error)
+		list func() ([]bitbucket.Webhook, error)
+		want want
+	}{
+		"ListedOnce": {
+			do: func(ctx context.Context, c bitbucket.WebhookClientAPI, _ *time.Time) (bitbucket.Webhook, error) {
+				if _, err := c.GetWebhook(ctx, repo, 1); err != nil {
+					return bitbucket.Webhook{}, err
+				}
+				return c.GetWebhook(ctx, repo, 2)
+			},
+			want: want{hook: bitbucket.Webhook{ID: 2, Name: "two"}, lists: 1},
+		},
+		"NotFound": {
+			do: func(ctx context.Context, c bitbucket.WebhookClientAPI, _ *time.Time) (bitbucket.Webhook, error) {
+				return c.GetWebhook(ctx, repo, 3)
+			},
+			want: want{err: bitbucket.ErrNotFound, lists: 1},
+		},
+		"ListFailed": {
+			do: func(ctx context.Context, c bitbucket.WebhookClientAPI, _ *time.Time) (bitbucket.Webhook, error) {
+				return c.GetWebhook(ctx, repo, 1)
+			},
+			list: func() ([]bitbucket.Webhook, error) { return nil, errBoom },
+			want: want{err: errBoom, lists: 1},
+		},
+		"Expired": {
+			do: func(ctx context.Context, c bitbucket.WebhookClientAPI, now *time.Time) (bitbucket.Webhook, error) {
+				if _, err := c.GetWebhook(ctx, repo, 1); err != nil {
+					return bitbucket.Webhook{}, err
+				}
+				*now = now.Add(time.Minute)
+				return c.GetWebhook(ctx, repo, 1)
+			},
+			want: want{hook: bitbucket.Webhook{ID: 1, Name: "one"}, lists: 2},
+		},
+		"InvalidatedByUpdate": {
+			do: func(ctx context.Context, c bitbucket.WebhookClientAPI, _ *time.Time) (bitbucket.Webhook, error) {
+				if _, err := c.GetWebhook(ctx, repo, 1); err != nil {
+					return bitbucket.Webhook{}, err
+				}
+				if _, err := c.UpdateWebhook(ctx, repo, 1, bitbucket.Webhook{}); err != nil {
+					return bitbucket.Webhook{}, err
+				}
+				return c.GetWebhook(ctx, repo, 1)
+			},
+			want: want{hook: bitbucket.Webhook{ID: 1, Name: "one"}, lists: 2},
+		},
+		"OtherRepo": {
+			do: func(ctx context.Context, c bitbucket.WebhookClientAPI, _ *time.Time) (bitbucket.Webhook, error) {
+				if _, err := c.GetWebhook(ctx, repo, 1); err != nil {
+					return bitbucket.Webhook{}, err
+				}
+				return c.GetWebhook(ctx, bitbucket.Repo{ProjectKey: "PRJ", Repo: "other"}, 1)
+			},
+			want: want{hook: bitbucket.Webhook{ID: 1, Name: "one"}, lists: 2},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+			lists := 0
+			client := &fake.MockWebhookClient{
+				MockListWebhooks: func(_ context.Context, _ bitbucket.Repo, _ bitbucket.WebhookFilter) ([]bitbucket.Webhook, error) {
+					lists++
+					if tc.list != nil {
+						return tc.list()
+					}
+					return []bitbucket.Webhook{{ID: 1, Name: "one"}, {ID: 2, Name: "two"}}, nil
+				},
+				MockUpdateWebhook: func(_ context.Context, _ bitbucket.Repo, _ int, h bitbucket.Webhook) (bitbucket.Webhook, error) {
+					return h, nil
+				},
+			}
+			c := New(30*time.Second, clock.ClockFn(func() time.Time { return now }))
+
+			got, err := tc.do(context.Background(), c.Webhooks(client, "default"), &now)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("GetWebhook(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.hook, got); diff != "" {
+				t.Errorf("GetWebhook(...): -want, +got:\n%s", diff)
+			}
+			if lists != tc.want.lists {
+				t.Errorf("GetWebhook(...): want %d list requests, got %d", tc.want.lists, lists)
+			}
+		})
+	}
+}
+
+func TestAccessKeys(t *testing.T) {
+	lists := 0
+	client := &fake.MockKeyClient{
+		MockListAccessKeys: func(_ context.Context, _ bitbucket.Repo, _ bitbucket.AccessKeyFilter) ([]bitbucket.AccessKey, error) {
+			lists++
+			return []bitbucket.AccessKey{{ID: 1, Label: "one"}, {ID: 2, Label: "two"}}, nil
+		},
+		MockDeleteAccessKey: func(_ context.Context, _ bitbucket.Repo, _ int) error { return nil },
+	}
+	c := New(time.Minute, clock.Fixed(time.Now())).AccessKeys(client, "default")
+	ctx := context.Background()
+
+	for _, id := range []int{1, 2} {
+		if _, err := c.GetAccessKey(ctx, repo, id); err != nil {
+			t.Fatalf("GetAccessKey(...): %v", err)
+		}
+	}
+	if err := c.DeleteAccessKey(ctx, repo, 2); err != nil {
+		t.Fatalf("DeleteAccessKey(...): %v", err)
+	}
+	got, err := c.GetAccessKey(ctx, repo, 1)
+	if err != nil {
+		t.Fatalf("GetAccessKey(...): %v", err)
+	}
+	if diff := cmp.Diff(bitbucket.AccessKey{ID: 1, Label: "one"}, got); diff != "" {
+		t.Errorf("GetAccessKey(...): -want, +got:\n%s", diff)
+	}
+	if lists != 2 {
+		t.Errorf("GetAccessKey(...): want 2 list requests, got %d", lists)
+	}
+}
+
+func TestNilCache(t *testing.T) {
+	client := &fake.MockWebhookClient{}
+	var c *Cache
+	if c.Webhooks(client, "default") != bitbucket.WebhookClientAPI(client) {
+		t.Errorf("Webhooks(...): want the client itself for a nil cache")
+	}
+}
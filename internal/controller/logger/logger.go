@@ -0,0 +1,229 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"context"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/logger/v1alpha1"
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/apierror"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/conditions"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/config"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/configcache"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/deletionprotection"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/dependents"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/drift"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/filter"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/lateinit"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/managementpolicy"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/setup"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/features"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/metrics"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+const (
+	errNotLogger    = "managed resource is not a Logger custom resource"
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+
+	errGetFailed    = "cannot get logger level from bitbucket API"
+	errSetFailed    = "cannot set logger level with bitbucket API"
+	errDeleteFailed = "cannot reset logger level with bitbucket API"
+)
+
+// Setup adds a controller that reconciles Logger managed resources.
+func Setup(mgr ctrl.Manager, o setup.Options) error {
+	name := managed.ControllerName(v1alpha1.LoggerGroupKind)
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	var conn managed.ExternalConnecter = deletionprotection.NewConnecter(conditions.NewConnecter(&connector{
+		kube:         mgr.GetClient(),
+		recorder:     recorder,
+		usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+		newServiceFn: clients.NewLoggerClient,
+		httpLog:      o.HTTPLogger(name),
+		clientLog:    o.ClientLogger(name),
+		warnFields:   o.WarnUnknownFields,
+		configs:      o.ConfigCache,
+	}))
+	conn = managementpolicy.NewConnecter(conn, o.Features.Enabled(features.EnableManagementPolicies))
+
+	errs := apierror.NewTracker()
+	r := managed.NewReconciler(errs.Manager(mgr),
+		resource.ManagedKind(v1alpha1.LoggerGroupVersionKind),
+		managed.WithExternalConnecter(errs.NewConnecter(conn)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(o.Timeout),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(recorder))
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Logger{}, builder.WithPredicates(filter.Changes()))
+	b, err := dependents.Watch(mgr, b, o, &v1alpha1.Logger{}, func() resource.ManagedList { return &v1alpha1.LoggerList{} }, nil)
+	if err != nil {
+		return err
+	}
+	return b.Complete(o.Reconciler(mgr, v1alpha1.LoggerGroupVersionKind, r))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube         client.Client
+	usage        resource.Tracker
+	recorder     event.Recorder
+	newServiceFn func(clients.Config) bitbucket.LoggerClientAPI
+	httpLog      logging.Logger
+	clientLog    logging.Logger
+	warnFields   bool
+	configs      *configcache.Cache
+}
+
+// Connect typically produces an ExternalClient by:
+// 1. Tracking that the managed resource is using a ProviderConfig.
+// 2. Getting the managed resource's ProviderConfig.
+// 3. Getting the credentials specified by the ProviderConfig.
+// 4. Using the credentials to form a client.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.Logger)
+	if !ok {
+		return nil, errors.New(errNotLogger)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	pc := &apisv1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	cfg, err := c.configs.ClientConfig(ctx, c.kube, pc, config.ClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	cfg.HTTPLogger = c.httpLog
+	cfg.Logger = c.clientLog
+	cfg.WarnUnknownFields = c.warnFields
+	return &external{service: c.newServiceFn(cfg), recorder: c.recorder}, nil
+}
+
+// An external sets the level of a logger. Loggers always exist in Bitbucket,
+// so deleting one sets it back to the reset level instead, and it counts as
+// deleted once it has that level.
+type external struct {
+	service  bitbucket.LoggerClientAPI
+	recorder event.Recorder
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Logger)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotLogger)
+	}
+
+	level, err := c.service.GetLoggerLevel(ctx, cr.Spec.ForProvider.LoggerName)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetFailed)
+	}
+	cr.Status.AtProvider = v1alpha1.LoggerObservation{Level: v1alpha1.Level(level)}
+
+	if meta.WasDeleted(cr) {
+		reset := cr.Spec.ForProvider.ResetLevel
+		return managed.ExternalObservation{ResourceExists: reset != "" && cr.Status.AtProvider.Level != reset}, nil
+	}
+
+	// The level before the logger is first changed is persisted before Update
+	// is called, so that it survives restarts of the provider.
+	reset := string(cr.Spec.ForProvider.ResetLevel)
+	resourceLateInitialized := lateinit.String(&reset, level)
+	cr.Spec.ForProvider.ResetLevel = v1alpha1.Level(reset)
+
+	want := v1alpha1.LoggerObservation{Level: cr.Spec.ForProvider.Level}
+	diff := cmp.Diff(want, cr.Status.AtProvider)
+	if diff != "" {
+		fields := drift.Fields(want, cr.Status.AtProvider)
+		metrics.RecordDrift(v1alpha1.LoggerKind, fields)
+		c.recorder.Event(cr, drift.Event(fields))
+	}
+
+	return managed.ExternalObservation{
+		ResourceExists:          true,
+		ResourceUpToDate:        diff == "",
+		ResourceLateInitialized: resourceLateInitialized,
+		Diff:                    diff,
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Logger)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotLogger)
+	}
+
+	return managed.ExternalCreation{}, errors.Wrap(c.set(ctx, cr, cr.Spec.ForProvider.Level), errSetFailed)
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Logger)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotLogger)
+	}
+
+	return managed.ExternalUpdate{}, errors.Wrap(c.set(ctx, cr, cr.Spec.ForProvider.Level), errSetFailed)
+}
+
+func (c *external) set(ctx context.Context, cr *v1alpha1.Logger, level v1alpha1.Level) error {
+	if err := c.service.SetLoggerLevel(ctx, cr.Spec.ForProvider.LoggerName, string(level)); err != nil {
+		return err
+	}
+	cr.Status.AtProvider.Level = level
+	return nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Logger)
+	if !ok {
+		return errors.New(errNotLogger)
+	}
+
+	// Without a reset level the level is left as it is.
+	if cr.Spec.ForProvider.ResetLevel == "" {
+		return nil
+	}
+
+	return errors.Wrap(c.set(ctx, cr, cr.Spec.ForProvider.ResetLevel), errDeleteFailed)
+}
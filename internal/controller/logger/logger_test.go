@@ -0,0 +1,203 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/logger/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket/fake"
+)
+
+const loggerName = "com.atlassian.bitbucket.hook"
+
+type resourceModifier func(*v1alpha1.Logger)
+
+func withResetLevel(l v1alpha1.Level) resourceModifier {
+	return func(r *v1alpha1.Logger) { r.Spec.ForProvider.ResetLevel = l }
+}
+
+func withObservation(l v1alpha1.Level) resourceModifier {
+	return func(r *v1alpha1.Logger) { r.Status.AtProvider.Level = l }
+}
+
+var deleted = metav1.Now()
+
+func withDeleted() resourceModifier {
+	return func(r *v1alpha1.Logger) { r.SetDeletionTimestamp(&deleted) }
+}
+
+func instance(rm ...resourceModifier) *v1alpha1.Logger {
+	r := &v1alpha1.Logger{}
+	r.Spec.ForProvider = v1alpha1.LoggerParameters{LoggerName: loggerName, Level: "DEBUG"}
+	for _, m := range rm {
+		m(r)
+	}
+	return r
+}
+
+var _ managed.ExternalConnecter = &connector{}
+var _ managed.ExternalClient = &external{}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		cr  *v1alpha1.Logger
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		cr    *v1alpha1.Logger
+		level string
+		err   error
+		want  want
+	}{
+		"NotChangedYet": {
+			cr:    instance(),
+			level: "WARN",
+			want: want{
+				cr: instance(withResetLevel("WARN"), withObservation("WARN")),
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false, ResourceLateInitialized: true},
+			},
+		},
+		"UpToDate": {
+			cr:    instance(withResetLevel("WARN")),
+			level: "DEBUG",
+			want: want{
+				cr: instance(withResetLevel("WARN"), withObservation("DEBUG")),
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"DeletedNotReset": {
+			cr:    instance(withResetLevel("WARN"), withDeleted()),
+			level: "DEBUG",
+			want: want{
+				cr: instance(withResetLevel("WARN"), withDeleted(), withObservation("DEBUG")),
+				o:  managed.ExternalObservation{ResourceExists: true},
+			},
+		},
+		"DeletedReset": {
+			cr:    instance(withResetLevel("WARN"), withDeleted()),
+			level: "WARN",
+			want: want{
+				cr: instance(withResetLevel("WARN"), withDeleted(), withObservation("WARN")),
+				o:  managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"GetFailed": {
+			cr:  instance(),
+			err: errBoom,
+			want: want{
+				cr:  instance(),
+				err: errors.Wrap(errBoom, errGetFailed),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{
+				service: &fake.MockLoggerClient{
+					MockGetLoggerLevel: func(_ context.Context, name string) (string, error) {
+						if name != loggerName {
+							t.Errorf("GetLoggerLevel(...): unexpected logger %s", name)
+						}
+						return tc.level, tc.err
+					},
+				},
+				recorder: event.NewNopRecorder(),
+			}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Observe(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.o.ResourceExists, got.ResourceExists); diff != "" {
+				t.Errorf("Observe(...): -want exists, +got exists:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.o.ResourceUpToDate, got.ResourceUpToDate); diff != "" {
+				t.Errorf("Observe(...): -want up to date, +got up to date:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.o.ResourceLateInitialized, got.ResourceLateInitialized); diff != "" {
+				t.Errorf("Observe(...): -want late initialized, +got late initialized:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.cr); diff != "" {
+				t.Errorf("Observe(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		level string
+		err   error
+	}
+
+	cases := map[string]struct {
+		cr   *v1alpha1.Logger
+		err  error
+		want want
+	}{
+		"Reset": {
+			cr:   instance(withResetLevel("WARN")),
+			want: want{level: "WARN"},
+		},
+		"NoResetLevel": {
+			cr: instance(),
+		},
+		"ResetFailed": {
+			cr:   instance(withResetLevel("WARN")),
+			err:  errBoom,
+			want: want{level: "WARN", err: errors.Wrap(errBoom, errDeleteFailed)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			level := ""
+			e := &external{
+				service: &fake.MockLoggerClient{
+					MockSetLoggerLevel: func(_ context.Context, _ string, l string) error {
+						level = l
+						return tc.err
+					},
+				},
+				recorder: event.NewNopRecorder(),
+			}
+			err := e.Delete(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Delete(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.level, level); diff != "" {
+				t.Errorf("Delete(...): -want level, +got level:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package managementpolicy restricts the operations performed on external
+// resources to the management policies of the managed resources.
+package managementpolicy
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+)
+
+const (
+	errCreateNotAllowed = "external resource does not exist and the management policies do not allow creating it"
+	errRestoreSpec      = "cannot revert late initialization not allowed by the management policies"
+	errFmtNotEnabled    = "spec.managementPolicies is set to %v, but management policies are not enabled"
+)
+
+// A Managed resource with management policies
+type Managed interface {
+	resource.Managed
+	GetManagementPolicies() v1alpha1.ManagementPolicies
+}
+
+// NewConnecter wraps an ExternalConnecter so that its clients only perform
+// the operations allowed by the management policies of the managed resource.
+// Unless enabled, the policies are not enforced and managed resources with
+// policies other than full control fail to connect, instead of being fully
+// controlled.
+func NewConnecter(c managed.ExternalConnecter, enabled bool) managed.ExternalConnecter {
+	return &connecter{connecter: c, enabled: enabled}
+}
+
+type connecter struct {
+	connecter managed.ExternalConnecter
+	enabled   bool
+}
+
+// Connect implements managed.ExternalConnecter
+func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	var policies v1alpha1.ManagementPolicies
+	if m, ok := mg.(Managed); ok {
+		policies = m.GetManagementPolicies()
+	}
+	if !policies.IsFullControl() && !c.enabled {
+		return nil, errors.Errorf(errFmtNotEnabled, policies)
+	}
+
+	ec, err := c.connecter.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+	if policies.IsFullControl() {
+		return ec, nil
+	}
+	return &external{client: ec, policies: policies}, nil
+}
+
+type external struct {
+	client   managed.ExternalClient
+	policies v1alpha1.ManagementPolicies
+}
+
+// Observe implements managed.ExternalClient
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	// The external resource is left in place, so report it as gone to let the
+	// managed resource reconciler remove the finalizer.
+	if meta.WasDeleted(mg) && !e.policies.Allows(v1alpha1.ManagementActionDelete) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	before := mg.DeepCopyObject()
+	o, err := e.client.Observe(ctx, mg)
+	if err != nil {
+		return o, err
+	}
+
+	if !o.ResourceExists && !meta.WasDeleted(mg) && !e.policies.Allows(v1alpha1.ManagementActionCreate) {
+		return o, errors.New(errCreateNotAllowed)
+	}
+
+	if o.ResourceLateInitialized && !e.policies.Allows(v1alpha1.ManagementActionLateInitialize) {
+		if err := restoreSpec(before, mg); err != nil {
+			return o, errors.Wrap(err, errRestoreSpec)
+		}
+		o.ResourceLateInitialized = false
+	}
+
+	if !e.policies.Allows(v1alpha1.ManagementActionUpdate) {
+		o.ResourceUpToDate = true
+	}
+
+	return o, nil
+}
+
+// Create implements managed.ExternalClient
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	if !e.policies.Allows(v1alpha1.ManagementActionCreate) {
+		return managed.ExternalCreation{}, errors.New(errCreateNotAllowed)
+	}
+	return e.client.Create(ctx, mg)
+}
+
+// Update implements managed.ExternalClient
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	if !e.policies.Allows(v1alpha1.ManagementActionUpdate) {
+		return managed.ExternalUpdate{}, nil
+	}
+	return e.client.Update(ctx, mg)
+}
+
+// Delete implements managed.ExternalClient
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	if !e.policies.Allows(v1alpha1.ManagementActionDelete) {
+		return nil
+	}
+	return e.client.Delete(ctx, mg)
+}
+
+// restoreSpec copies the spec of from back into to, keeping the rest of to
+// including the status set while observing.
+func restoreSpec(from runtime.Object, to resource.Managed) error {
+	f, err := runtime.DefaultUnstructuredConverter.ToUnstructured(from)
+	if err != nil {
+		return err
+	}
+	t, err := runtime.DefaultUnstructuredConverter.ToUnstructured(to)
+	if err != nil {
+		return err
+	}
+	t["spec"] = f["spec"]
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(t, to)
+}
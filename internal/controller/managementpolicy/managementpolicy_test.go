@@ -0,0 +1,199 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managementpolicy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	webhookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+)
+
+type resourceModifier func(*webhookv1alpha1.Webhook)
+
+func withPolicies(p ...v1alpha1.ManagementAction) resourceModifier {
+	return func(r *webhookv1alpha1.Webhook) { r.Spec.ManagementPolicies = p }
+}
+
+func withURL(url string) resourceModifier {
+	return func(r *webhookv1alpha1.Webhook) { r.Spec.ForProvider.Webhook.URL = url }
+}
+
+func withDeletionTimestamp() resourceModifier {
+	return func(r *webhookv1alpha1.Webhook) {
+		ts := metav1.Unix(1, 0)
+		r.SetDeletionTimestamp(&ts)
+	}
+}
+
+func instance(rm ...resourceModifier) *webhookv1alpha1.Webhook {
+	r := &webhookv1alpha1.Webhook{}
+	for _, m := range rm {
+		m(r)
+	}
+	return r
+}
+
+func TestObserve(t *testing.T) {
+	observeOnly := []v1alpha1.ManagementAction{v1alpha1.ManagementActionObserve}
+	noDelete := []v1alpha1.ManagementAction{
+		v1alpha1.ManagementActionObserve,
+		v1alpha1.ManagementActionCreate,
+		v1alpha1.ManagementActionUpdate,
+		v1alpha1.ManagementActionLateInitialize,
+	}
+
+	type want struct {
+		cr       *webhookv1alpha1.Webhook
+		o        managed.ExternalObservation
+		err      error
+		observed bool
+	}
+
+	cases := map[string]struct {
+		cr *webhookv1alpha1.Webhook
+		o  managed.ExternalObservation
+		want
+	}{
+		"ObserveOnlyDrifted": {
+			cr: instance(withPolicies(observeOnly...)),
+			o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			want: want{
+				cr:       instance(withPolicies(observeOnly...)),
+				o:        managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				observed: true,
+			},
+		},
+		"ObserveOnlyMissing": {
+			cr: instance(withPolicies(observeOnly...)),
+			o:  managed.ExternalObservation{ResourceExists: false},
+			want: want{
+				cr:       instance(withPolicies(observeOnly...)),
+				o:        managed.ExternalObservation{ResourceExists: false, ResourceUpToDate: false},
+				err:      errors.New(errCreateNotAllowed),
+				observed: true,
+			},
+		},
+		"ObserveOnlyLateInitialized": {
+			cr: instance(withPolicies(observeOnly...)),
+			o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true, ResourceLateInitialized: true},
+			want: want{
+				cr:       instance(withPolicies(observeOnly...)),
+				o:        managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				observed: true,
+			},
+		},
+		"NoDeleteWhileDeleted": {
+			cr: instance(withPolicies(noDelete...), withDeletionTimestamp()),
+			o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			want: want{
+				cr: instance(withPolicies(noDelete...), withDeletionTimestamp()),
+				o:  managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			observed := false
+			e := &external{
+				policies: tc.cr.GetManagementPolicies(),
+				client: managed.ExternalClientFns{
+					ObserveFn: func(_ context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+						observed = true
+						if tc.o.ResourceLateInitialized {
+							withURL("https://late.example.com")(mg.(*webhookv1alpha1.Webhook))
+						}
+						return tc.o, nil
+					},
+				},
+			}
+			o, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.cr, tc.cr, test.EquateConditions()); diff != "" {
+				t.Errorf("Observe(...): -want, +got\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Observe(...): -want, +got\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.o, o); diff != "" {
+				t.Errorf("Observe(...): -want, +got\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.observed, observed); diff != "" {
+				t.Errorf("Observe(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConnectFullControl(t *testing.T) {
+	inner := &managed.NopClient{}
+	c := NewConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+		return inner, nil
+	}), true)
+
+	ec, err := c.Connect(context.Background(), instance(withPolicies(v1alpha1.ManagementActionAll)))
+	if err != nil {
+		t.Fatalf("Connect(...): %v", err)
+	}
+	if ec != managed.ExternalClient(inner) {
+		t.Errorf("Connect(...): want the unwrapped client for full control, got %T", ec)
+	}
+}
+
+func TestConnectNotEnabled(t *testing.T) {
+	inner := &managed.NopClient{}
+	c := NewConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+		return inner, nil
+	}), false)
+
+	cases := map[string]struct {
+		cr   *webhookv1alpha1.Webhook
+		want error
+	}{
+		"Default": {
+			cr: instance(),
+		},
+		"FullControl": {
+			cr: instance(withPolicies(v1alpha1.ManagementActionAll)),
+		},
+		"ObserveOnly": {
+			cr:   instance(withPolicies(v1alpha1.ManagementActionObserve)),
+			want: errors.Errorf(errFmtNotEnabled, v1alpha1.ManagementPolicies{v1alpha1.ManagementActionObserve}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ec, err := c.Connect(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Connect(...): -want error, +got error:\n%s", diff)
+			}
+			if tc.want == nil && ec != managed.ExternalClient(inner) {
+				t.Errorf("Connect(...): want the unwrapped client, got %T", ec)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mergestrategy
+
+import (
+	"context"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/mergestrategy/v1alpha1"
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/apierror"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/conditions"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/config"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/configcache"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/deletionprotection"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/dependents"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/drift"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/filter"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/managementpolicy"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/setup"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/features"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/metrics"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+const (
+	errNotMergeStrategy = "managed resource is not a MergeStrategy custom resource"
+	errTrackPCUsage     = "cannot track ProviderConfig usage"
+	errGetPC            = "cannot get ProviderConfig"
+
+	errGetFailed    = "cannot get merge strategies from bitbucket API"
+	errSetFailed    = "cannot set merge strategies with bitbucket API"
+	errDeleteFailed = "cannot delete merge strategies from bitbucket API"
+)
+
+// Setup adds a controller that reconciles MergeStrategy managed resources.
+func Setup(mgr ctrl.Manager, o setup.Options) error {
+	name := managed.ControllerName(v1alpha1.MergeStrategyGroupKind)
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	var conn managed.ExternalConnecter = deletionprotection.NewConnecter(conditions.NewConnecter(&connector{
+		kube:         mgr.GetClient(),
+		recorder:     recorder,
+		usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+		newServiceFn: clients.NewMergeConfigClient,
+		httpLog:      o.HTTPLogger(name),
+		clientLog:    o.ClientLogger(name),
+		warnFields:   o.WarnUnknownFields,
+		configs:      o.ConfigCache,
+	}))
+	conn = managementpolicy.NewConnecter(conn, o.Features.Enabled(features.EnableManagementPolicies))
+
+	errs := apierror.NewTracker()
+	r := managed.NewReconciler(errs.Manager(mgr),
+		resource.ManagedKind(v1alpha1.MergeStrategyGroupVersionKind),
+		managed.WithExternalConnecter(errs.NewConnecter(conn)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(o.Timeout),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(recorder))
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.MergeStrategy{}, builder.WithPredicates(filter.Changes()))
+	b, err := dependents.Watch(mgr, b, o, &v1alpha1.MergeStrategy{}, func() resource.ManagedList { return &v1alpha1.MergeStrategyList{} }, nil)
+	if err != nil {
+		return err
+	}
+	return b.Complete(o.Reconciler(mgr, v1alpha1.MergeStrategyGroupVersionKind, r))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube         client.Client
+	usage        resource.Tracker
+	recorder     event.Recorder
+	newServiceFn func(clients.Config) bitbucket.MergeConfigClientAPI
+	httpLog      logging.Logger
+	clientLog    logging.Logger
+	warnFields   bool
+	configs      *configcache.Cache
+}
+
+// Connect typically produces an ExternalClient by:
+// 1. Tracking that the managed resource is using a ProviderConfig.
+// 2. Getting the managed resource's ProviderConfig.
+// 3. Getting the credentials specified by the ProviderConfig.
+// 4. Using the credentials to form a client.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.MergeStrategy)
+	if !ok {
+		return nil, errors.New(errNotMergeStrategy)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	pc := &apisv1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	cfg, err := c.configs.ClientConfig(ctx, c.kube, pc, config.ClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	cfg.HTTPLogger = c.httpLog
+	cfg.Logger = c.clientLog
+	cfg.WarnUnknownFields = c.warnFields
+	return &external{service: c.newServiceFn(cfg), recorder: c.recorder}, nil
+}
+
+// An external sets the merge strategies of a project or repository. They
+// always exist in Bitbucket, so they only count as existing when they are set
+// at the scope of the managed resource rather than inherited.
+type external struct {
+	service  bitbucket.MergeConfigClientAPI
+	recorder event.Recorder
+}
+
+// ignoreOrder compares the strategies regardless of their order, and no
+// strategies as equal to an empty list
+var ignoreOrder = cmp.Options{cmpopts.SortSlices(func(a, b string) bool { return a < b }), cmpopts.EquateEmpty()}
+
+// ignoreType ignores the scope the strategies are set at
+var ignoreType = cmpopts.IgnoreFields(bitbucket.MergeConfig{}, "Type")
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.MergeStrategy)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotMergeStrategy)
+	}
+
+	cfg, err := c.service.GetMergeConfig(ctx, cr.Repo())
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetFailed)
+	}
+	observe(cr, cfg)
+
+	// Inherited strategies are set once the managed resource sets its own.
+	if cfg.Type != cr.Scope() {
+		return managed.ExternalObservation{}, nil
+	}
+
+	want := cr.MergeConfig()
+	diff := cmp.Diff(want, cfg, ignoreOrder, ignoreType)
+	if diff != "" {
+		fields := drift.Fields(want, cfg, ignoreOrder, ignoreType)
+		metrics.RecordDrift(v1alpha1.MergeStrategyKind, fields)
+		c.recorder.Event(cr, drift.Event(fields))
+	}
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: diff == "",
+		Diff:             diff,
+	}, nil
+}
+
+// observe records the strategies in the status of the managed resource.
+func observe(cr *v1alpha1.MergeStrategy, cfg bitbucket.MergeConfig) {
+	strategies := make([]v1alpha1.Strategy, 0, len(cfg.Strategies))
+	for _, s := range cfg.Strategies {
+		strategies = append(strategies, v1alpha1.Strategy(s))
+	}
+	cr.Status.AtProvider = v1alpha1.MergeStrategyObservation{
+		Strategies:      strategies,
+		DefaultStrategy: v1alpha1.Strategy(cfg.DefaultStrategy),
+		Scope:           cfg.Type,
+	}
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.MergeStrategy)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotMergeStrategy)
+	}
+
+	return managed.ExternalCreation{}, c.set(ctx, cr)
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.MergeStrategy)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotMergeStrategy)
+	}
+
+	return managed.ExternalUpdate{}, c.set(ctx, cr)
+}
+
+func (c *external) set(ctx context.Context, cr *v1alpha1.MergeStrategy) error {
+	cfg, err := c.service.SetMergeConfig(ctx, cr.Repo(), cr.MergeConfig())
+	if err != nil {
+		return errors.Wrap(err, errSetFailed)
+	}
+	observe(cr, cfg)
+	return nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.MergeStrategy)
+	if !ok {
+		return errors.New(errNotMergeStrategy)
+	}
+
+	if err := c.service.DeleteMergeConfig(ctx, cr.Repo()); err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+		return errors.Wrap(err, errDeleteFailed)
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mergestrategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/mergestrategy/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket/fake"
+)
+
+type resourceModifier func(*v1alpha1.MergeStrategy)
+
+func withRepoName(name string) resourceModifier {
+	return func(r *v1alpha1.MergeStrategy) { r.Spec.ForProvider.RepoName = name }
+}
+
+func withDefault(s v1alpha1.Strategy) resourceModifier {
+	return func(r *v1alpha1.MergeStrategy) { r.Spec.ForProvider.DefaultStrategy = s }
+}
+
+func withObservation(o v1alpha1.MergeStrategyObservation) resourceModifier {
+	return func(r *v1alpha1.MergeStrategy) { r.Status.AtProvider = o }
+}
+
+func instance(rm ...resourceModifier) *v1alpha1.MergeStrategy {
+	r := &v1alpha1.MergeStrategy{}
+	r.Spec.ForProvider = v1alpha1.MergeStrategyParameters{
+		ProjectKey: "prj",
+		RepoName:   "My Repo",
+		Strategies: []v1alpha1.Strategy{"squash", "ff-only"},
+	}
+	for _, m := range rm {
+		m(r)
+	}
+	return r
+}
+
+var repo = bitbucket.Repo{ProjectKey: "PRJ", Repo: "my-repo"}
+
+var _ managed.ExternalConnecter = &connector{}
+var _ managed.ExternalClient = &external{}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		cr  *v1alpha1.MergeStrategy
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		cr   *v1alpha1.MergeStrategy
+		repo bitbucket.Repo
+		cfg  bitbucket.MergeConfig
+		err  error
+		want want
+	}{
+		"UpToDate": {
+			cr:   instance(),
+			repo: repo,
+			cfg:  bitbucket.MergeConfig{Strategies: []string{"ff-only", "squash"}, DefaultStrategy: "squash", Type: bitbucket.MergeConfigRepository},
+			want: want{
+				cr: instance(withObservation(v1alpha1.MergeStrategyObservation{
+					Strategies:      []v1alpha1.Strategy{"ff-only", "squash"},
+					DefaultStrategy: "squash",
+					Scope:           bitbucket.MergeConfigRepository,
+				})),
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"Drifted": {
+			cr:   instance(withDefault("ff-only")),
+			repo: repo,
+			cfg:  bitbucket.MergeConfig{Strategies: []string{"squash", "ff-only"}, DefaultStrategy: "squash", Type: bitbucket.MergeConfigRepository},
+			want: want{
+				cr: instance(withDefault("ff-only"), withObservation(v1alpha1.MergeStrategyObservation{
+					Strategies:      []v1alpha1.Strategy{"squash", "ff-only"},
+					DefaultStrategy: "squash",
+					Scope:           bitbucket.MergeConfigRepository,
+				})),
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+		"Inherited": {
+			cr:   instance(),
+			repo: repo,
+			cfg:  bitbucket.MergeConfig{Strategies: []string{"no-ff"}, DefaultStrategy: "no-ff", Type: bitbucket.MergeConfigProject},
+			want: want{
+				cr: instance(withObservation(v1alpha1.MergeStrategyObservation{
+					Strategies:      []v1alpha1.Strategy{"no-ff"},
+					DefaultStrategy: "no-ff",
+					Scope:           bitbucket.MergeConfigProject,
+				})),
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"Project": {
+			cr:   instance(withRepoName("")),
+			repo: bitbucket.Repo{ProjectKey: "PRJ"},
+			cfg:  bitbucket.MergeConfig{Strategies: []string{"squash", "ff-only"}, DefaultStrategy: "squash", Type: bitbucket.MergeConfigProject},
+			want: want{
+				cr: instance(withRepoName(""), withObservation(v1alpha1.MergeStrategyObservation{
+					Strategies:      []v1alpha1.Strategy{"squash", "ff-only"},
+					DefaultStrategy: "squash",
+					Scope:           bitbucket.MergeConfigProject,
+				})),
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"GetFailed": {
+			cr:   instance(),
+			repo: repo,
+			err:  errBoom,
+			want: want{
+				cr:  instance(),
+				err: errors.Wrap(errBoom, errGetFailed),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{
+				service: &fake.MockMergeConfigClient{
+					MockGetMergeConfig: func(_ context.Context, r bitbucket.Repo) (bitbucket.MergeConfig, error) {
+						if diff := cmp.Diff(tc.repo, r); diff != "" {
+							t.Errorf("GetMergeConfig(...): -want repo, +got repo:\n%s", diff)
+						}
+						return tc.cfg, tc.err
+					},
+				},
+				recorder: event.NewNopRecorder(),
+			}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Observe(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.o.ResourceExists, got.ResourceExists); diff != "" {
+				t.Errorf("Observe(...): -want exists, +got exists:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.o.ResourceUpToDate, got.ResourceUpToDate); diff != "" {
+				t.Errorf("Observe(...): -want up to date, +got up to date:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.cr); diff != "" {
+				t.Errorf("Observe(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		cr   *v1alpha1.MergeStrategy
+		err  error
+		want bitbucket.MergeConfig
+		// wantErr is the error of Create
+		wantErr error
+	}{
+		"DefaultsToFirstStrategy": {
+			cr:   instance(),
+			want: bitbucket.MergeConfig{Strategies: []string{"squash", "ff-only"}, DefaultStrategy: "squash"},
+		},
+		"Default": {
+			cr:   instance(withDefault("ff-only")),
+			want: bitbucket.MergeConfig{Strategies: []string{"squash", "ff-only"}, DefaultStrategy: "ff-only"},
+		},
+		"SetFailed": {
+			cr:      instance(),
+			err:     errBoom,
+			want:    bitbucket.MergeConfig{Strategies: []string{"squash", "ff-only"}, DefaultStrategy: "squash"},
+			wantErr: errors.Wrap(errBoom, errSetFailed),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{
+				service: &fake.MockMergeConfigClient{
+					MockSetMergeConfig: func(_ context.Context, r bitbucket.Repo, cfg bitbucket.MergeConfig) (bitbucket.MergeConfig, error) {
+						if diff := cmp.Diff(repo, r); diff != "" {
+							t.Errorf("SetMergeConfig(...): -want repo, +got repo:\n%s", diff)
+						}
+						if diff := cmp.Diff(tc.want, cfg); diff != "" {
+							t.Errorf("SetMergeConfig(...): -want, +got:\n%s", diff)
+						}
+						cfg.Type = bitbucket.MergeConfigRepository
+						return cfg, tc.err
+					},
+				},
+				recorder: event.NewNopRecorder(),
+			}
+			_, err := e.Create(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.wantErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Create(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		deleted bool
+		err     error
+	}
+
+	cases := map[string]struct {
+		cr   *v1alpha1.MergeStrategy
+		err  error
+		want want
+	}{
+		"Deleted": {
+			cr:   instance(),
+			want: want{deleted: true},
+		},
+		"RepoGone": {
+			cr:   instance(),
+			err:  errors.Wrap(bitbucket.ErrNotFound, "404"),
+			want: want{deleted: true},
+		},
+		"DeleteFailed": {
+			cr:   instance(),
+			err:  errBoom,
+			want: want{deleted: true, err: errors.Wrap(errBoom, errDeleteFailed)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			deleted := false
+			e := &external{
+				service: &fake.MockMergeConfigClient{
+					MockDeleteMergeConfig: func(_ context.Context, r bitbucket.Repo) error {
+						deleted = true
+						return tc.err
+					},
+				},
+				recorder: event.NewNopRecorder(),
+			}
+			err := e.Delete(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Delete(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.deleted, deleted); diff != "" {
+				t.Errorf("Delete(...): -want deleted, +got deleted:\n%s", diff)
+			}
+		})
+	}
+}
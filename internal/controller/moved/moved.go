@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package moved tells repositories which were renamed, moved or deleted in
+// Bitbucket from missing webhooks and access keys, whose requests fail with
+// not found either way. Creating the webhook or access key again would fail
+// for a missing repository, or register it with a different repository which
+// took over the path.
+package moved
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+var (
+	// ErrMoved is returned when the repository was renamed or moved within
+	// its project.
+	ErrMoved = errors.New("repository was renamed or moved")
+
+	// ErrGone is returned when the repository was deleted or moved to
+	// another project.
+	ErrGone = errors.New("repository was deleted or moved to another project")
+)
+
+const (
+	errGetRepo   = "cannot get repository %s/%s"
+	errListRepos = "cannot list repositories of project %s"
+)
+
+// Check returns nil if the repository at repo is still the one with the ID,
+// so that a missing webhook or access key of it was deleted and may be
+// created again. It returns ErrMoved with the current slug if the repository
+// with the ID is found in the project under another slug, and ErrGone if it
+// is not found.
+func Check(ctx context.Context, c bitbucket.RepositoryClientAPI, repo bitbucket.Repo, id int) error {
+	r, err := c.GetRepository(ctx, repo)
+	if err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+		return errors.Wrapf(err, errGetRepo, repo.ProjectKey, repo.Repo)
+	}
+	if err == nil && r.ID == id {
+		return nil
+	}
+
+	// The project is gone as well if it is not found
+	repos, err := c.ListRepositories(ctx, repo.ProjectKey)
+	if err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+		return errors.Wrapf(err, errListRepos, repo.ProjectKey)
+	}
+	for _, r := range repos {
+		if r.ID == id {
+			return errors.Wrapf(ErrMoved, "repository %d at %s/%s is now %s/%s", id, repo.ProjectKey, repo.Repo, r.ProjectKey, r.Slug)
+		}
+	}
+	return errors.Wrapf(ErrGone, "repository %d at %s/%s", id, repo.ProjectKey, repo.Repo)
+}
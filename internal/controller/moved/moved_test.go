@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package moved
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket/fake"
+)
+
+func TestCheck(t *testing.T) {
+	errBoom := errors.New("boom")
+	repo := bitbucket.Repo{ProjectKey: "PRJ", Repo: "old"}
+
+	get := func(r bitbucket.Repository, err error) func(context.Context, bitbucket.Repo) (bitbucket.Repository, error) {
+		return func(_ context.Context, _ bitbucket.Repo) (bitbucket.Repository, error) { return r, err }
+	}
+	list := func(r []bitbucket.Repository, err error) func(context.Context, string) ([]bitbucket.Repository, error) {
+		return func(_ context.Context, _ string) ([]bitbucket.Repository, error) { return r, err }
+	}
+
+	cases := map[string]struct {
+		c    *fake.MockRepositoryClient
+		want error
+	}{
+		"Unchanged": {
+			c: &fake.MockRepositoryClient{MockGetRepository: get(bitbucket.Repository{ID: 5, Slug: "old"}, nil)},
+		},
+		"Renamed": {
+			c: &fake.MockRepositoryClient{
+				MockGetRepository:    get(bitbucket.Repository{}, bitbucket.ErrNotFound),
+				MockListRepositories: list([]bitbucket.Repository{{ID: 4, Slug: "other", ProjectKey: "PRJ"}, {ID: 5, Slug: "new", ProjectKey: "PRJ"}}, nil),
+			},
+			want: errors.Wrap(ErrMoved, "repository 5 at PRJ/old is now PRJ/new"),
+		},
+		"Replaced": {
+			c: &fake.MockRepositoryClient{
+				MockGetRepository:    get(bitbucket.Repository{ID: 9, Slug: "old"}, nil),
+				MockListRepositories: list([]bitbucket.Repository{{ID: 9, Slug: "old", ProjectKey: "PRJ"}, {ID: 5, Slug: "new", ProjectKey: "PRJ"}}, nil),
+			},
+			want: errors.Wrap(ErrMoved, "repository 5 at PRJ/old is now PRJ/new"),
+		},
+		"Deleted": {
+			c: &fake.MockRepositoryClient{
+				MockGetRepository:    get(bitbucket.Repository{}, bitbucket.ErrNotFound),
+				MockListRepositories: list([]bitbucket.Repository{{ID: 4, Slug: "other", ProjectKey: "PRJ"}}, nil),
+			},
+			want: errors.Wrap(ErrGone, "repository 5 at PRJ/old"),
+		},
+		"ProjectDeleted": {
+			c: &fake.MockRepositoryClient{
+				MockGetRepository:    get(bitbucket.Repository{}, bitbucket.ErrNotFound),
+				MockListRepositories: list(nil, bitbucket.ErrNotFound),
+			},
+			want: errors.Wrap(ErrGone, "repository 5 at PRJ/old"),
+		},
+		"GetFailed": {
+			c:    &fake.MockRepositoryClient{MockGetRepository: get(bitbucket.Repository{}, errBoom)},
+			want: errors.Wrapf(errBoom, errGetRepo, "PRJ", "old"),
+		},
+		"ListFailed": {
+			c: &fake.MockRepositoryClient{
+				MockGetRepository:    get(bitbucket.Repository{}, bitbucket.ErrNotFound),
+				MockListRepositories: list(nil, errBoom),
+			},
+			want: errors.Wrapf(errBoom, errListRepos, "PRJ"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := Check(context.Background(), tc.c, repo, 5)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Check(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
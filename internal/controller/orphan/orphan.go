@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package orphan cleans up after creates of external resources whose external
+// name could not be recorded, e.g. because the API server failed to update the
+// managed resource. The managed resource reconciler refuses to reconcile such
+// managed resources, since creating the external resource again would leave
+// the first one behind. The reconciler of this package deletes the external
+// resources the create left behind instead, so that the managed resource is
+// created again.
+package orphan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clock"
+)
+
+const (
+	errGetManaged    = "cannot get managed resource"
+	errCleanup       = "cannot clean up after incomplete create"
+	errUpdateManaged = "cannot record cleaned up create of managed resource"
+
+	reasonCleanedUp event.Reason = "CleanedUpIncompleteCreate"
+)
+
+// A Cleaner deletes the external resources which were created for the managed
+// resource since the supplied time, and returns how many it deleted.
+type Cleaner interface {
+	Cleanup(ctx context.Context, mg resource.Managed, since time.Time) (int, error)
+}
+
+// A CleanerFn is a function that satisfies the Cleaner interface
+type CleanerFn func(ctx context.Context, mg resource.Managed, since time.Time) (int, error)
+
+// Cleanup calls the function
+func (fn CleanerFn) Cleanup(ctx context.Context, mg resource.Managed, since time.Time) (int, error) {
+	return fn(ctx, mg, since)
+}
+
+// A Reconciler cleans up after incomplete creates of managed resources, then
+// delegates to the wrapped reconciler.
+type Reconciler struct {
+	client     client.Client
+	newManaged func() resource.Managed
+	cleaner    Cleaner
+	recorder   event.Recorder
+	clock      clock.Clock
+	reconciler reconcile.Reconciler
+}
+
+// NewReconciler wraps the supplied reconciler of managed resources of the
+// supplied kind so that the external resources left behind by their
+// incomplete creates are deleted by the cleaner.
+func NewReconciler(m ctrl.Manager, of resource.ManagedKind, c Cleaner, rec event.Recorder, r reconcile.Reconciler) *Reconciler {
+	nm := func() resource.Managed {
+		return resource.MustCreateObject(schema.GroupVersionKind(of), m.GetScheme()).(resource.Managed)
+	}
+	return &Reconciler{client: m.GetClient(), newManaged: nm, cleaner: c, recorder: rec, clock: clock.System, reconciler: r}
+}
+
+// Reconcile a managed resource after cleaning up its incomplete create.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	mg := r.newManaged()
+	if err := r.client.Get(ctx, req.NamespacedName, mg); err != nil {
+		// The wrapped reconciler knows how to handle resources that are gone.
+		if resource.IgnoreNotFound(err) == nil {
+			return r.reconciler.Reconcile(ctx, req)
+		}
+		return reconcile.Result{}, errors.Wrap(err, errGetManaged)
+	}
+
+	if !meta.ExternalCreateIncomplete(mg) {
+		return r.reconciler.Reconcile(ctx, req)
+	}
+
+	n, err := r.cleaner.Cleanup(ctx, mg, meta.GetExternalCreatePending(mg))
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errCleanup)
+	}
+	if n > 0 {
+		r.recorder.Event(mg, event.Normal(reasonCleanedUp, fmt.Sprintf("Deleted %d external resources left behind by a create whose external name was not recorded", n)))
+	}
+
+	// The create is recorded as failed, so that the managed resource
+	// reconciler creates the external resource again.
+	meta.SetExternalCreateFailed(mg, r.clock.Now())
+	if err := r.client.Update(ctx, mg); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, errUpdateManaged)
+	}
+	return r.reconciler.Reconcile(ctx, req)
+}
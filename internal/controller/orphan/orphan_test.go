@@ -0,0 +1,225 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orphan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clock"
+)
+
+var errBoom = errors.New("boom")
+
+var (
+	pending = time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	now     = pending.Add(time.Minute)
+)
+
+type resourceModifier func(*v1alpha1.Webhook)
+
+func withPending() resourceModifier {
+	return func(r *v1alpha1.Webhook) { meta.SetExternalCreatePending(r, pending) }
+}
+
+func withSucceeded() resourceModifier {
+	return func(r *v1alpha1.Webhook) { meta.SetExternalCreateSucceeded(r, now) }
+}
+
+func withFailed() resourceModifier {
+	return func(r *v1alpha1.Webhook) { meta.SetExternalCreateFailed(r, now) }
+}
+
+func instance(rm ...resourceModifier) *v1alpha1.Webhook {
+	r := &v1alpha1.Webhook{}
+	for _, m := range rm {
+		m(r)
+	}
+	return r
+}
+
+func get(cr *v1alpha1.Webhook) test.MockGetFn {
+	return test.NewMockGetFn(nil, func(o client.Object) error {
+		cr.DeepCopyInto(o.(*v1alpha1.Webhook))
+		return nil
+	})
+}
+
+// An eventRecorder records the events of the resources.
+type eventRecorder struct {
+	events []event.Event
+}
+
+func (r *eventRecorder) Event(_ runtime.Object, e event.Event) {
+	r.events = append(r.events, e)
+}
+
+func (r *eventRecorder) WithAnnotations(_ ...string) event.Recorder {
+	return r
+}
+
+func TestReconcile(t *testing.T) {
+	delegated := reconcile.Result{Requeue: true}
+	cleanedUp := event.Normal(reasonCleanedUp, "Deleted 1 external resources left behind by a create whose external name was not recorded")
+
+	type args struct {
+		client  client.Client
+		cleaned int
+		err     error
+	}
+	type want struct {
+		result    reconcile.Result
+		err       error
+		delegated bool
+		since     time.Time
+		updated   *v1alpha1.Webhook
+		events    []event.Event
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"NeverCreated": {
+			args: args{client: &test.MockClient{MockGet: get(instance())}},
+			want: want{result: delegated, delegated: true},
+		},
+		"CreateSucceeded": {
+			args: args{client: &test.MockClient{MockGet: get(instance(withPending(), withSucceeded()))}},
+			want: want{result: delegated, delegated: true},
+		},
+		"CreateFailed": {
+			args: args{client: &test.MockClient{MockGet: get(instance(withPending(), withFailed()))}},
+			want: want{result: delegated, delegated: true},
+		},
+		"NotFound": {
+			args: args{client: &test.MockClient{MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, ""))}},
+			want: want{result: delegated, delegated: true},
+		},
+		"GetFailed": {
+			args: args{client: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)}},
+			want: want{err: errors.Wrap(errBoom, errGetManaged)},
+		},
+		"CreateIncomplete": {
+			args: args{
+				client:  &test.MockClient{MockGet: get(instance(withPending())), MockUpdate: test.NewMockUpdateFn(nil)},
+				cleaned: 1,
+			},
+			want: want{
+				result:    delegated,
+				delegated: true,
+				since:     pending,
+				updated:   instance(withPending(), withFailed()),
+				events:    []event.Event{cleanedUp},
+			},
+		},
+		"NothingLeftBehind": {
+			args: args{
+				client: &test.MockClient{MockGet: get(instance(withPending())), MockUpdate: test.NewMockUpdateFn(nil)},
+			},
+			want: want{
+				result:    delegated,
+				delegated: true,
+				since:     pending,
+				updated:   instance(withPending(), withFailed()),
+			},
+		},
+		"CleanupFailed": {
+			args: args{
+				client: &test.MockClient{MockGet: get(instance(withPending()))},
+				err:    errBoom,
+			},
+			want: want{err: errors.Wrap(errBoom, errCleanup), since: pending},
+		},
+		"UpdateFailed": {
+			args: args{
+				client:  &test.MockClient{MockGet: get(instance(withPending())), MockUpdate: test.NewMockUpdateFn(errBoom)},
+				cleaned: 1,
+			},
+			want: want{
+				err:     errors.Wrap(errBoom, errUpdateManaged),
+				since:   pending,
+				updated: instance(withPending(), withFailed()),
+				events:  []event.Event{cleanedUp},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var gotDelegated bool
+			var gotSince time.Time
+			var gotUpdated *v1alpha1.Webhook
+			if mc := tc.args.client.(*test.MockClient); mc.MockUpdate != nil {
+				update := mc.MockUpdate
+				mc.MockUpdate = func(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+					gotUpdated = obj.(*v1alpha1.Webhook)
+					return update(ctx, obj, opts...)
+				}
+			}
+			recorder := &eventRecorder{}
+			r := &Reconciler{
+				client:     tc.args.client,
+				newManaged: func() resource.Managed { return &v1alpha1.Webhook{} },
+				cleaner: CleanerFn(func(_ context.Context, _ resource.Managed, since time.Time) (int, error) {
+					gotSince = since
+					return tc.args.cleaned, tc.args.err
+				}),
+				recorder: recorder,
+				clock:    clock.Fixed(now),
+				reconciler: reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+					gotDelegated = true
+					return delegated, nil
+				}),
+			}
+			got, err := r.Reconcile(context.Background(), reconcile.Request{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Reconcile(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("Reconcile(...): -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.delegated, gotDelegated); diff != "" {
+				t.Errorf("Reconcile(...): -want delegated, +got delegated:\n%s", diff)
+			}
+			if !gotSince.Equal(tc.want.since) {
+				t.Errorf("Cleanup(...): want since %s, got %s", tc.want.since, gotSince)
+			}
+			if diff := cmp.Diff(tc.want.updated, gotUpdated); diff != "" {
+				t.Errorf("Reconcile(...): -want updated, +got updated:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.events, recorder.events); diff != "" {
+				t.Errorf("Reconcile(...): -want events, +got events:\n%s", diff)
+			}
+		})
+	}
+}
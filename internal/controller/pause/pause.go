@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pause stops the reconciliation of managed resources annotated with
+// the crossplane.io/paused annotation.
+package pause
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+const (
+	// AnnotationKeyPaused is the annotation that pauses the reconciliation
+	// of a managed resource when set to "true".
+	AnnotationKeyPaused = "crossplane.io/paused"
+
+	// ReasonReconcilePaused is the reason of the Synced condition of a
+	// managed resource whose reconciliation is paused.
+	ReasonReconcilePaused xpv1.ConditionReason = "ReconcilePaused"
+
+	errGetManaged    = "cannot get managed resource"
+	errUpdateManaged = "cannot update status of paused managed resource"
+)
+
+// ReconcilePaused returns a condition that indicates the reconciliation of a
+// managed resource is paused.
+func ReconcilePaused() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeSynced,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonReconcilePaused,
+	}
+}
+
+// IsPaused returns true if the reconciliation of the object is paused.
+func IsPaused(o metav1.Object) bool {
+	return o.GetAnnotations()[AnnotationKeyPaused] == "true"
+}
+
+// A Reconciler skips the reconciliation of paused managed resources and
+// delegates all others to the wrapped reconciler.
+type Reconciler struct {
+	client     client.Client
+	newManaged func() resource.Managed
+	reconciler reconcile.Reconciler
+}
+
+// NewReconciler wraps the supplied reconciler of managed resources of the
+// supplied kind so that paused resources are not reconciled.
+func NewReconciler(m ctrl.Manager, of resource.ManagedKind, r reconcile.Reconciler) *Reconciler {
+	nm := func() resource.Managed {
+		return resource.MustCreateObject(schema.GroupVersionKind(of), m.GetScheme()).(resource.Managed)
+	}
+	return &Reconciler{client: m.GetClient(), newManaged: nm, reconciler: r}
+}
+
+// Reconcile a managed resource unless its reconciliation is paused.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	mg := r.newManaged()
+	if err := r.client.Get(ctx, req.NamespacedName, mg); err != nil {
+		// The wrapped reconciler knows how to handle resources that are gone.
+		if resource.IgnoreNotFound(err) == nil {
+			return r.reconciler.Reconcile(ctx, req)
+		}
+		return reconcile.Result{}, errors.Wrap(err, errGetManaged)
+	}
+
+	if !IsPaused(mg) {
+		return r.reconciler.Reconcile(ctx, req)
+	}
+
+	// A paused resource is reconciled again when its annotations change, so
+	// there is no need to requeue it.
+	if mg.GetCondition(xpv1.TypeSynced).Equal(ReconcilePaused()) {
+		return reconcile.Result{}, nil
+	}
+	mg.SetConditions(ReconcilePaused())
+	return reconcile.Result{}, errors.Wrap(r.client.Status().Update(ctx, mg), errUpdateManaged)
+}
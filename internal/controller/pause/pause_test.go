@@ -0,0 +1,176 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pause
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+)
+
+var errBoom = errors.New("boom")
+
+type resourceModifier func(*v1alpha1.Webhook)
+
+func withAnnotations(a map[string]string) resourceModifier {
+	return func(r *v1alpha1.Webhook) { r.SetAnnotations(a) }
+}
+
+func withConditions(c ...xpv1.Condition) resourceModifier {
+	return func(r *v1alpha1.Webhook) { r.SetConditions(c...) }
+}
+
+func instance(rm ...resourceModifier) *v1alpha1.Webhook {
+	r := &v1alpha1.Webhook{}
+	for _, m := range rm {
+		m(r)
+	}
+	return r
+}
+
+func TestReconcile(t *testing.T) {
+	paused := map[string]string{AnnotationKeyPaused: "true"}
+	delegated := reconcile.Result{Requeue: true}
+
+	type args struct {
+		client client.Client
+	}
+	type want struct {
+		result    reconcile.Result
+		err       error
+		delegated bool
+		status    *v1alpha1.Webhook
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"NotPaused": {
+			args: args{
+				client: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+						instance(withAnnotations(map[string]string{AnnotationKeyPaused: "false"})).DeepCopyInto(o.(*v1alpha1.Webhook))
+						return nil
+					}),
+				},
+			},
+			want: want{result: delegated, delegated: true},
+		},
+		"NotFound": {
+			args: args{
+				client: &test.MockClient{
+					MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+				},
+			},
+			want: want{result: delegated, delegated: true},
+		},
+		"GetFailed": {
+			args: args{
+				client: &test.MockClient{
+					MockGet: test.NewMockGetFn(errBoom),
+				},
+			},
+			want: want{err: errors.Wrap(errBoom, errGetManaged)},
+		},
+		"Paused": {
+			args: args{
+				client: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+						instance(withAnnotations(paused)).DeepCopyInto(o.(*v1alpha1.Webhook))
+						return nil
+					}),
+					MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+				},
+			},
+			want: want{status: instance(withAnnotations(paused), withConditions(ReconcilePaused()))},
+		},
+		"AlreadyPaused": {
+			args: args{
+				client: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+						instance(withAnnotations(paused), withConditions(ReconcilePaused())).DeepCopyInto(o.(*v1alpha1.Webhook))
+						return nil
+					}),
+				},
+			},
+			want: want{},
+		},
+		"UpdateFailed": {
+			args: args{
+				client: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+						instance(withAnnotations(paused)).DeepCopyInto(o.(*v1alpha1.Webhook))
+						return nil
+					}),
+					MockStatusUpdate: test.NewMockStatusUpdateFn(errBoom),
+				},
+			},
+			want: want{
+				err:    errors.Wrap(errBoom, errUpdateManaged),
+				status: instance(withAnnotations(paused), withConditions(ReconcilePaused())),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var gotDelegated bool
+			var gotStatus *v1alpha1.Webhook
+			if mc, ok := tc.args.client.(*test.MockClient); ok && mc.MockStatusUpdate != nil {
+				update := mc.MockStatusUpdate
+				mc.MockStatusUpdate = func(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+					gotStatus = obj.(*v1alpha1.Webhook)
+					return update(ctx, obj, opts...)
+				}
+			}
+			r := &Reconciler{
+				client:     tc.args.client,
+				newManaged: func() resource.Managed { return &v1alpha1.Webhook{} },
+				reconciler: reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+					gotDelegated = true
+					return delegated, nil
+				}),
+			}
+			got, err := r.Reconcile(context.Background(), reconcile.Request{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Reconcile(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("Reconcile(...): -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.delegated, gotDelegated); diff != "" {
+				t.Errorf("Reconcile(...): -want delegated, +got delegated:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.status, gotStatus, test.EquateConditions()); diff != "" {
+				t.Errorf("Reconcile(...): -want status, +got status:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package preflight checks that the credentials of a ProviderConfig have the
+// permissions a managed resource needs when connecting to Bitbucket, so that
+// missing permissions are reported once and plainly instead of as a 403 of
+// every operation.
+package preflight
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clock"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+const errRepoAdmin = "credentials lack the admin permission on repository %s/%s"
+
+// ErrInsufficientPermissions is returned when the credentials lack a
+// permission the managed resource needs
+var ErrInsufficientPermissions = errors.New("insufficient permissions")
+
+// A Checker checks the permissions of credentials and remembers passed checks
+// for a while, so that they cost one request per repository and ProviderConfig
+// instead of one per reconcile. It is shared by all controllers. A nil Checker
+// checks nothing.
+type Checker struct {
+	ttl   time.Duration
+	clock clock.Clock
+
+	mu     sync.Mutex
+	passed map[key]time.Time
+}
+
+// key is a repository checked with the credentials of a ProviderConfig
+type key struct {
+	providerConfig string
+	repo           bitbucket.Repo
+}
+
+// New returns a Checker which remembers passed checks for the ttl, telling
+// the time by the clock.
+func New(ttl time.Duration, clk clock.Clock) *Checker {
+	return &Checker{ttl: ttl, clock: clk, passed: map[key]time.Time{}}
+}
+
+// RepoAdmin returns ErrInsufficientPermissions if the credentials of the
+// client lack the admin permission on the repository. Other errors of the
+// check are ignored, they are reported by the operation which follows it.
+func (c *Checker) RepoAdmin(ctx context.Context, client bitbucket.PermissionClientAPI, providerConfig string, repo bitbucket.Repo) error {
+	if c == nil {
+		return nil
+	}
+
+	k := key{providerConfig: providerConfig, repo: repo}
+	c.mu.Lock()
+	at, ok := c.passed[k]
+	c.mu.Unlock()
+	if ok && c.clock.Now().Before(at.Add(c.ttl)) {
+		return nil
+	}
+
+	err := client.CheckRepositoryAdmin(ctx, repo)
+	if errors.Is(err, bitbucket.ErrForbidden) {
+		return errors.Wrapf(ErrInsufficientPermissions, errRepoAdmin, repo.ProjectKey, repo.Repo)
+	}
+	if err != nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.passed[k] = c.clock.Now()
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clock"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket/fake"
+)
+
+func TestRepoAdmin(t *testing.T) {
+	errBoom := errors.New("boom")
+	repo := bitbucket.Repo{ProjectKey: "PRJ", Repo: "my-repo"}
+
+	type want struct {
+		err   error
+		calls int
+	}
+
+	cases := map[string]struct {
+		checker *Checker
+		err     error
+		// after is how long after the first check the second one is made
+		after time.Duration
+		want  want
+	}{
+		"NilChecker": {
+			want: want{calls: 0},
+		},
+		"Passed": {
+			checker: New(time.Minute, nil),
+			after:   30 * time.Second,
+			want:    want{calls: 1},
+		},
+		"PassedExpired": {
+			checker: New(time.Minute, nil),
+			after:   time.Minute,
+			want:    want{calls: 2},
+		},
+		"Forbidden": {
+			checker: New(time.Minute, nil),
+			err:     errors.Wrap(bitbucket.ErrForbidden, "403"),
+			want: want{
+				err:   errors.Wrapf(ErrInsufficientPermissions, errRepoAdmin, "PRJ", "my-repo"),
+				calls: 2,
+			},
+		},
+		"OtherErrorIgnored": {
+			checker: New(time.Minute, nil),
+			err:     errBoom,
+			want:    want{calls: 2},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			now := time.Unix(0, 0)
+			if tc.checker != nil {
+				tc.checker.clock = clock.ClockFn(func() time.Time { return now })
+			}
+			calls := 0
+			client := &fake.MockPermissionClient{
+				MockCheckRepositoryAdmin: func(_ context.Context, r bitbucket.Repo) error {
+					if diff := cmp.Diff(repo, r); diff != "" {
+						t.Errorf("CheckRepositoryAdmin(...): -want, +got:\n%s", diff)
+					}
+					calls++
+					return tc.err
+				},
+			}
+
+			for i := 0; i < 2; i++ {
+				err := tc.checker.RepoAdmin(context.Background(), client, "default", repo)
+				if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+					t.Errorf("RepoAdmin(...): -want error, +got error:\n%s", diff)
+				}
+				now = now.Add(tc.after)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("RepoAdmin(...): -want calls, +got calls:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRepoAdminPerProviderConfig(t *testing.T) {
+	repo := bitbucket.Repo{ProjectKey: "PRJ", Repo: "my-repo"}
+	calls := 0
+	client := &fake.MockPermissionClient{
+		MockCheckRepositoryAdmin: func(_ context.Context, _ bitbucket.Repo) error {
+			calls++
+			return nil
+		},
+	}
+
+	c := New(time.Minute, clock.Fixed(time.Unix(0, 0)))
+	for _, pc := range []string{"a", "b", "a"} {
+		if err := c.RepoAdmin(context.Background(), client, pc, repo); err != nil {
+			t.Fatalf("RepoAdmin(...): %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("RepoAdmin(...): want 2 checks, got %d", calls)
+	}
+}
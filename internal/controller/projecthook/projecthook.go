@@ -0,0 +1,265 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projecthook
+
+import (
+	"context"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/projecthook/v1alpha1"
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/apierror"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/conditions"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/config"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/configcache"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/deletionprotection"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/dependents"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/drift"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/filter"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/managementpolicy"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/setup"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/features"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/metrics"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+const (
+	errNotProjectHook = "managed resource is not a ProjectHook custom resource"
+	errTrackPCUsage   = "cannot track ProviderConfig usage"
+	errGetPC          = "cannot get ProviderConfig"
+
+	errSettings       = "cannot decode the settings of the project hook"
+	errGetFailed      = "cannot get project hook from bitbucket API"
+	errGetSettings    = "cannot get project hook settings from bitbucket API"
+	errEnableFailed   = "cannot enable project hook with bitbucket API"
+	errSettingsFailed = "cannot set project hook settings with bitbucket API"
+	errDisableFailed  = "cannot disable project hook with bitbucket API"
+)
+
+// Setup adds a controller that reconciles ProjectHook managed resources.
+func Setup(mgr ctrl.Manager, o setup.Options) error {
+	name := managed.ControllerName(v1alpha1.ProjectHookGroupKind)
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	var conn managed.ExternalConnecter = deletionprotection.NewConnecter(conditions.NewConnecter(&connector{
+		kube:         mgr.GetClient(),
+		recorder:     recorder,
+		usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+		newServiceFn: clients.NewProjectHookClient,
+		httpLog:      o.HTTPLogger(name),
+		clientLog:    o.ClientLogger(name),
+		warnFields:   o.WarnUnknownFields,
+		configs:      o.ConfigCache,
+	}))
+	conn = managementpolicy.NewConnecter(conn, o.Features.Enabled(features.EnableManagementPolicies))
+
+	errs := apierror.NewTracker()
+	r := managed.NewReconciler(errs.Manager(mgr),
+		resource.ManagedKind(v1alpha1.ProjectHookGroupVersionKind),
+		managed.WithExternalConnecter(errs.NewConnecter(conn)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(o.Timeout),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(recorder))
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.ProjectHook{}, builder.WithPredicates(filter.Changes()))
+	b, err := dependents.Watch(mgr, b, o, &v1alpha1.ProjectHook{}, func() resource.ManagedList { return &v1alpha1.ProjectHookList{} }, nil)
+	if err != nil {
+		return err
+	}
+	return b.Complete(o.Reconciler(mgr, v1alpha1.ProjectHookGroupVersionKind, r))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube         client.Client
+	usage        resource.Tracker
+	recorder     event.Recorder
+	newServiceFn func(clients.Config) bitbucket.ProjectHookClientAPI
+	httpLog      logging.Logger
+	clientLog    logging.Logger
+	warnFields   bool
+	configs      *configcache.Cache
+}
+
+// Connect typically produces an ExternalClient by:
+// 1. Tracking that the managed resource is using a ProviderConfig.
+// 2. Getting the managed resource's ProviderConfig.
+// 3. Getting the credentials specified by the ProviderConfig.
+// 4. Using the credentials to form a client.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.ProjectHook)
+	if !ok {
+		return nil, errors.New(errNotProjectHook)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	pc := &apisv1alpha1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	cfg, err := c.configs.ClientConfig(ctx, c.kube, pc, config.ClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	cfg.HTTPLogger = c.httpLog
+	cfg.Logger = c.clientLog
+	cfg.WarnUnknownFields = c.warnFields
+	return &external{service: c.newServiceFn(cfg), recorder: c.recorder}, nil
+}
+
+// An external enables a repository hook for a project and keeps its
+// settings. Hooks always exist in Bitbucket once their plugin is installed,
+// so a project hook exists while it is enabled, and deleting it disables the
+// hook.
+type external struct {
+	service  bitbucket.ProjectHookClientAPI
+	recorder event.Recorder
+}
+
+// hookSettings are compared to tell whether the settings of a hook drifted,
+// so that the drifted fields are named after them
+type hookSettings struct {
+	Settings bitbucket.HookSettings
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.ProjectHook)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotProjectHook)
+	}
+
+	hook, err := c.service.GetProjectHook(ctx, cr.ProjectKey(), cr.Spec.ForProvider.HookKey)
+	if err != nil {
+		// A missing plugin cannot be enabled, but it is disabled already
+		if errors.Is(err, bitbucket.ErrNotFound) && meta.WasDeleted(cr) {
+			return managed.ExternalObservation{}, nil
+		}
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetFailed)
+	}
+	cr.Status.AtProvider = v1alpha1.ProjectHookObservation{
+		Name:       hook.Name,
+		Type:       hook.Type,
+		Version:    hook.Version,
+		Enabled:    hook.Enabled,
+		Configured: hook.Configured,
+	}
+
+	if !hook.Enabled {
+		return managed.ExternalObservation{}, nil
+	}
+
+	settings, err := cr.Settings()
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSettings)
+	}
+	// Settings which are not managed are left alone
+	if settings == nil {
+		return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+	}
+
+	got, err := c.service.GetProjectHookSettings(ctx, cr.ProjectKey(), cr.Spec.ForProvider.HookKey)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetSettings)
+	}
+	want := hookSettings{Settings: settings}
+	diff := cmp.Diff(want, hookSettings{Settings: got}, cmpopts.EquateEmpty())
+	if diff != "" {
+		fields := drift.Fields(want, hookSettings{Settings: got}, cmpopts.EquateEmpty())
+		metrics.RecordDrift(v1alpha1.ProjectHookKind, fields)
+		c.recorder.Event(cr, drift.Event(fields))
+	}
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: diff == "",
+		Diff:             diff,
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.ProjectHook)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotProjectHook)
+	}
+
+	settings, err := cr.Settings()
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errSettings)
+	}
+	hook, err := c.service.EnableProjectHook(ctx, cr.ProjectKey(), cr.Spec.ForProvider.HookKey, settings)
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errEnableFailed)
+	}
+	cr.Status.AtProvider.Enabled = hook.Enabled
+	cr.Status.AtProvider.Configured = hook.Configured
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.ProjectHook)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotProjectHook)
+	}
+
+	settings, err := cr.Settings()
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errSettings)
+	}
+	if settings == nil {
+		return managed.ExternalUpdate{}, nil
+	}
+	if err := c.service.SetProjectHookSettings(ctx, cr.ProjectKey(), cr.Spec.ForProvider.HookKey, settings); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errSettingsFailed)
+	}
+	cr.Status.AtProvider.Configured = true
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.ProjectHook)
+	if !ok {
+		return errors.New(errNotProjectHook)
+	}
+
+	if err := c.service.DisableProjectHook(ctx, cr.ProjectKey(), cr.Spec.ForProvider.HookKey); err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+		return errors.Wrap(err, errDisableFailed)
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projecthook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/projecthook/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket/fake"
+)
+
+const hookKey = "com.atlassian.bitbucket.server.bitbucket-bundled-hooks:force-push-hook"
+
+type resourceModifier func(*v1alpha1.ProjectHook)
+
+func withSettings(raw string) resourceModifier {
+	return func(r *v1alpha1.ProjectHook) { r.Spec.ForProvider.Settings = &runtime.RawExtension{Raw: []byte(raw)} }
+}
+
+func withObservation(o v1alpha1.ProjectHookObservation) resourceModifier {
+	return func(r *v1alpha1.ProjectHook) { r.Status.AtProvider = o }
+}
+
+func withDeletionTimestamp() resourceModifier {
+	return func(r *v1alpha1.ProjectHook) { r.SetDeletionTimestamp(&metav1.Time{Time: time.Unix(1, 0)}) }
+}
+
+func instance(rm ...resourceModifier) *v1alpha1.ProjectHook {
+	r := &v1alpha1.ProjectHook{}
+	r.Spec.ForProvider = v1alpha1.ProjectHookParameters{ProjectKey: "prj", HookKey: hookKey}
+	for _, m := range rm {
+		m(r)
+	}
+	return r
+}
+
+var _ managed.ExternalConnecter = &connector{}
+var _ managed.ExternalClient = &external{}
+
+var observed = v1alpha1.ProjectHookObservation{
+	Name:       "Reject Force Push",
+	Type:       bitbucket.HookTypePreReceive,
+	Version:    "7.21.0",
+	Enabled:    true,
+	Configured: true,
+}
+
+func hook(enabled bool) bitbucket.ProjectHook {
+	return bitbucket.ProjectHook{
+		Key:        hookKey,
+		Name:       observed.Name,
+		Type:       observed.Type,
+		Version:    observed.Version,
+		Enabled:    enabled,
+		Configured: true,
+	}
+}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+	disabled := observed
+	disabled.Enabled = false
+
+	type want struct {
+		cr  *v1alpha1.ProjectHook
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		cr          *v1alpha1.ProjectHook
+		hook        bitbucket.ProjectHook
+		err         error
+		settings    bitbucket.HookSettings
+		settingsErr error
+		want        want
+	}{
+		"Disabled": {
+			cr:   instance(),
+			hook: hook(false),
+			want: want{
+				cr: instance(withObservation(disabled)),
+				o:  managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"EnabledWithoutSettings": {
+			cr:   instance(),
+			hook: hook(true),
+			want: want{
+				cr: instance(withObservation(observed)),
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"SettingsUpToDate": {
+			cr:       instance(withSettings(`{"references":"refs/heads/main"}`)),
+			hook:     hook(true),
+			settings: bitbucket.HookSettings{"references": "refs/heads/main"},
+			want: want{
+				cr: instance(withSettings(`{"references":"refs/heads/main"}`), withObservation(observed)),
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"SettingsDrifted": {
+			cr:       instance(withSettings(`{"references":"refs/heads/main"}`)),
+			hook:     hook(true),
+			settings: bitbucket.HookSettings{"references": "refs/heads/*"},
+			want: want{
+				cr: instance(withSettings(`{"references":"refs/heads/main"}`), withObservation(observed)),
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+			},
+		},
+		"InvalidSettings": {
+			cr:   instance(withSettings(`[]`)),
+			hook: hook(true),
+			want: want{
+				cr:  instance(withSettings(`[]`), withObservation(observed)),
+				err: errors.Wrap(errors.New("json: cannot unmarshal array into Go value of type bitbucket.HookSettings"), errSettings),
+			},
+		},
+		"GetSettingsFailed": {
+			cr:          instance(withSettings(`{}`)),
+			hook:        hook(true),
+			settingsErr: errBoom,
+			want: want{
+				cr:  instance(withSettings(`{}`), withObservation(observed)),
+				err: errors.Wrap(errBoom, errGetSettings),
+			},
+		},
+		"PluginGoneWhileDeleting": {
+			cr:  instance(withDeletionTimestamp()),
+			err: errors.Wrap(bitbucket.ErrNotFound, "404"),
+			want: want{
+				cr: instance(withDeletionTimestamp()),
+				o:  managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"GetFailed": {
+			cr:  instance(),
+			err: errBoom,
+			want: want{
+				cr:  instance(),
+				err: errors.Wrap(errBoom, errGetFailed),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{
+				service: &fake.MockProjectHookClient{
+					MockGetProjectHook: func(_ context.Context, projectKey, key string) (bitbucket.ProjectHook, error) {
+						if projectKey != "PRJ" || key != hookKey {
+							t.Errorf("GetProjectHook(...): unexpected hook %s of project %s", key, projectKey)
+						}
+						return tc.hook, tc.err
+					},
+					MockGetProjectHookSettings: func(_ context.Context, _, _ string) (bitbucket.HookSettings, error) {
+						return tc.settings, tc.settingsErr
+					},
+				},
+				recorder: event.NewNopRecorder(),
+			}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Observe(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.o.ResourceExists, got.ResourceExists); diff != "" {
+				t.Errorf("Observe(...): -want exists, +got exists:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.o.ResourceUpToDate, got.ResourceUpToDate); diff != "" {
+				t.Errorf("Observe(...): -want up to date, +got up to date:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.cr); diff != "" {
+				t.Errorf("Observe(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		settings bitbucket.HookSettings
+		err      error
+	}
+
+	cases := map[string]struct {
+		cr   *v1alpha1.ProjectHook
+		err  error
+		want want
+	}{
+		"EnabledWithoutSettings": {
+			cr: instance(),
+		},
+		"EnabledWithSettings": {
+			cr:   instance(withSettings(`{"references":"refs/heads/main"}`)),
+			want: want{settings: bitbucket.HookSettings{"references": "refs/heads/main"}},
+		},
+		"EnableFailed": {
+			cr:   instance(),
+			err:  errBoom,
+			want: want{err: errors.Wrap(errBoom, errEnableFailed)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var settings bitbucket.HookSettings
+			e := &external{
+				service: &fake.MockProjectHookClient{
+					MockEnableProjectHook: func(_ context.Context, _, _ string, s bitbucket.HookSettings) (bitbucket.ProjectHook, error) {
+						settings = s
+						return hook(true), tc.err
+					},
+				},
+				recorder: event.NewNopRecorder(),
+			}
+			_, err := e.Create(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Create(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.settings, settings); diff != "" {
+				t.Errorf("Create(...): -want settings, +got settings:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		calls int
+		err   error
+	}
+
+	cases := map[string]struct {
+		cr   *v1alpha1.ProjectHook
+		err  error
+		want want
+	}{
+		"SettingsUnmanaged": {
+			cr: instance(),
+		},
+		"SettingsReplaced": {
+			cr:   instance(withSettings(`{"references":"refs/heads/main"}`)),
+			want: want{calls: 1},
+		},
+		"SetFailed": {
+			cr:   instance(withSettings(`{}`)),
+			err:  errBoom,
+			want: want{calls: 1, err: errors.Wrap(errBoom, errSettingsFailed)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			e := &external{
+				service: &fake.MockProjectHookClient{
+					MockSetProjectHookSettings: func(_ context.Context, _, _ string, _ bitbucket.HookSettings) error {
+						calls++
+						return tc.err
+					},
+				},
+				recorder: event.NewNopRecorder(),
+			}
+			_, err := e.Update(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Update(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("Update(...): -want calls, +got calls:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		calls int
+		err   error
+	}
+
+	cases := map[string]struct {
+		cr   *v1alpha1.ProjectHook
+		err  error
+		want want
+	}{
+		"Disabled": {
+			cr:   instance(),
+			want: want{calls: 1},
+		},
+		"ProjectGone": {
+			cr:   instance(),
+			err:  errors.Wrap(bitbucket.ErrNotFound, "404"),
+			want: want{calls: 1},
+		},
+		"DisableFailed": {
+			cr:   instance(),
+			err:  errBoom,
+			want: want{calls: 1, err: errors.Wrap(errBoom, errDisableFailed)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			e := &external{
+				service: &fake.MockProjectHookClient{
+					MockDisableProjectHook: func(_ context.Context, _, _ string) error {
+						calls++
+						return tc.err
+					},
+				},
+				recorder: event.NewNopRecorder(),
+			}
+			err := e.Delete(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Delete(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("Delete(...): -want calls, +got calls:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package receiver receives the events Bitbucket webhooks send and
+// reconciles the managed resources of the repository of an event right away,
+// instead of at their next poll.
+package receiver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+const (
+	// Path the receiver serves the events at
+	Path = "/events"
+
+	headerEventKey  = "X-Event-Key"
+	headerSignature = "X-Hub-Signature"
+
+	// maxBody is the largest event accepted. Events of pushes with many
+	// changes are the largest ones.
+	maxBody = 1 << 20
+	// eventBuffer is the number of events of each kind of managed resources
+	// that may wait for their controller.
+	eventBuffer = 1024
+	// shutdownTimeout bounds the time to finish the requests in flight
+	shutdownTimeout = 10 * time.Second
+
+	errList = "cannot list %s"
+)
+
+// An Option configures a Receiver
+type Option func(*Receiver)
+
+// WithSecret sets the secret the webhooks sign their events with. Events
+// without a valid signature are rejected.
+func WithSecret(secret string) Option {
+	return func(r *Receiver) {
+		r.secret = []byte(secret)
+	}
+}
+
+// WithInsecure accepts events without verifying their signature when no
+// secret is set. Without a secret, all events are rejected otherwise.
+func WithInsecure() Option {
+	return func(r *Receiver) {
+		r.insecure = true
+	}
+}
+
+// A Receiver is an HTTP handler of the events of Bitbucket webhooks. It
+// enqueues the managed resources of the repository of an event for
+// reconcile.
+type Receiver struct {
+	kube     client.Reader
+	log      logging.Logger
+	secret   []byte
+	insecure bool
+
+	mu    sync.RWMutex
+	kinds []kind
+}
+
+// kind of managed resources with the channel of their controller
+type kind struct {
+	list   resource.ManagedList
+	events chan event.GenericEvent
+}
+
+// repoResource is a managed resource of a repository
+type repoResource interface {
+	resource.Managed
+	Repo() bitbucket.Repo
+}
+
+// New returns a Receiver finding the managed resources of repositories with
+// the supplied client.
+func New(kube client.Reader, log logging.Logger, o ...Option) *Receiver {
+	r := &Receiver{kube: kube, log: log}
+	for _, fn := range o {
+		fn(r)
+	}
+	return r
+}
+
+// Register a kind of managed resources by the type of their list, and return
+// the source of the events of the managed resources of the kind, which
+// belong to the repository of a received event. The items of the list must
+// have a Repo method.
+func (r *Receiver) Register(list resource.ManagedList) source.Source {
+	k := kind{list: list, events: make(chan event.GenericEvent, eventBuffer)}
+	r.mu.Lock()
+	r.kinds = append(r.kinds, k)
+	r.mu.Unlock()
+	return &source.Channel{Source: k.events}
+}
+
+// ServeHTTP enqueues the managed resources of the repositories of the event.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBody))
+	if err != nil {
+		http.Error(w, "cannot read event", http.StatusBadRequest)
+		return
+	}
+	if !r.verify(req.Header.Get(headerSignature), body) {
+		r.log.Debug("Rejecting event with invalid signature", "event", req.Header.Get(headerEventKey))
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	var e payload
+	if err := json.Unmarshal(body, &e); err != nil {
+		http.Error(w, "cannot decode event", http.StatusBadRequest)
+		return
+	}
+	key := e.EventKey
+	if key == "" {
+		key = req.Header.Get(headerEventKey)
+	}
+
+	for _, repo := range e.repos() {
+		n, err := r.enqueue(req.Context(), repo)
+		if err != nil {
+			r.log.Info("Cannot enqueue managed resources of event", "event", key, "project", repo.ProjectKey, "repo", repo.Repo, "error", err)
+			http.Error(w, "cannot enqueue managed resources", http.StatusServiceUnavailable)
+			return
+		}
+		r.log.Debug("Received event", "event", key, "project", repo.ProjectKey, "repo", repo.Repo, "enqueued", n)
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// verify returns whether the signature of the body is valid, or no secret is
+// set and the receiver is insecure.
+func (r *Receiver) verify(signature string, body []byte) bool {
+	if len(r.secret) == 0 {
+		return r.insecure
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	mac := hmac.New(sha256.New, r.secret)
+	mac.Write(body) // nolint:errcheck
+	return hmac.Equal(sig, mac.Sum(nil))
+}
+
+// enqueue sends the managed resources of all kinds which belong to the
+// repository to their controllers, and returns their number.
+func (r *Receiver) enqueue(ctx context.Context, repo bitbucket.Repo) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	n := 0
+	for _, k := range r.kinds {
+		l := k.list.DeepCopyObject().(resource.ManagedList)
+		if err := r.kube.List(ctx, l); err != nil {
+			return n, errors.Wrapf(err, errList, l.GetObjectKind().GroupVersionKind().Kind)
+		}
+		for _, mg := range l.GetItems() {
+			rr, ok := mg.(repoResource)
+			if !ok || !sameRepo(rr.Repo(), repo) {
+				continue
+			}
+			select {
+			case k.events <- event.GenericEvent{Object: mg}:
+				n++
+			case <-ctx.Done():
+				return n, ctx.Err()
+			}
+		}
+	}
+	return n, nil
+}
+
+// sameRepo compares project keys case-insensitively like Bitbucket does.
+func sameRepo(a, b bitbucket.Repo) bool {
+	return strings.EqualFold(a.ProjectKey, b.ProjectKey) && a.Repo == b.Repo
+}
+
+// Start serves the events at the address until the context is done.
+func (r *Receiver) Start(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, r)
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+	r.log.Info("Receiving Bitbucket events", "address", addr, "path", Path)
+
+	select {
+	case err := <-errs:
+		return errors.Wrap(err, "cannot serve events")
+	case <-ctx.Done():
+	}
+	sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(sctx)
+}
+
+// payload holds the repositories of the events of Bitbucket webhooks.
+type payload struct {
+	EventKey   string      `json:"eventKey"`
+	Repository *repository `json:"repository"`
+	// Old and New are the repository before and after repo:modified
+	Old         *repository `json:"old"`
+	New         *repository `json:"new"`
+	PullRequest *struct {
+		ToRef struct {
+			Repository *repository `json:"repository"`
+		} `json:"toRef"`
+	} `json:"pullRequest"`
+}
+
+type repository struct {
+	Slug    string `json:"slug"`
+	Project struct {
+		Key string `json:"key"`
+	} `json:"project"`
+}
+
+// repos returns the distinct repositories of the event, none for events like
+// diagnostics:ping which concern no repository.
+func (p payload) repos() []bitbucket.Repo {
+	candidates := []*repository{p.Repository, p.Old, p.New}
+	if p.PullRequest != nil {
+		candidates = append(candidates, p.PullRequest.ToRef.Repository)
+	}
+	var ret []bitbucket.Repo
+	for _, c := range candidates {
+		if c == nil || c.Slug == "" || c.Project.Key == "" {
+			continue
+		}
+		repo := bitbucket.Repo{ProjectKey: c.Project.Key, Repo: c.Slug}
+		dup := false
+		for _, r := range ret {
+			dup = dup || r == repo
+		}
+		if !dup {
+			ret = append(ret, repo)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package receiver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	webhookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+)
+
+func webhook(name, projectKey, repoName string) webhookv1alpha1.Webhook {
+	cr := webhookv1alpha1.Webhook{}
+	cr.SetName(name)
+	cr.Spec.ForProvider.ProjectKey = projectKey
+	cr.Spec.ForProvider.RepoName = repoName
+	return cr
+}
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body)) // nolint:errcheck
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestServeHTTP(t *testing.T) {
+	errBoom := errors.New("boom")
+	refsChanged := `{"eventKey":"repo:refs_changed","repository":{"slug":"my-repo","project":{"key":"PRJ"}},"changes":[]}`
+
+	kube := &test.MockClient{
+		MockList: func(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+			list.(*webhookv1alpha1.WebhookList).Items = []webhookv1alpha1.Webhook{
+				webhook("match", "prj", "My Repo"),
+				webhook("other-repo", "PRJ", "Other"),
+				webhook("other-project", "OTHER", "My Repo"),
+			}
+			return nil
+		},
+	}
+
+	type want struct {
+		status   int
+		enqueued []string
+	}
+
+	cases := map[string]struct {
+		kube      client.Reader
+		o         []Option
+		method    string
+		signature string
+		body      string
+		want      want
+	}{
+		"RefsChanged": {
+			kube: kube,
+			o:    []Option{WithInsecure()},
+			body: refsChanged,
+			want: want{status: http.StatusAccepted, enqueued: []string{"match"}},
+		},
+		"PullRequest": {
+			kube: kube,
+			o:    []Option{WithInsecure()},
+			body: `{"eventKey":"pr:merged","pullRequest":{"toRef":{"repository":{"slug":"my-repo","project":{"key":"PRJ"}}}}}`,
+			want: want{status: http.StatusAccepted, enqueued: []string{"match"}},
+		},
+		"Renamed": {
+			kube: kube,
+			o:    []Option{WithInsecure()},
+			body: `{"eventKey":"repo:modified","old":{"slug":"old","project":{"key":"PRJ"}},"new":{"slug":"my-repo","project":{"key":"PRJ"}}}`,
+			want: want{status: http.StatusAccepted, enqueued: []string{"match"}},
+		},
+		"Ping": {
+			kube: kube,
+			o:    []Option{WithInsecure()},
+			body: `{"test":true}`,
+			want: want{status: http.StatusAccepted},
+		},
+		"ValidSignature": {
+			kube:      kube,
+			o:         []Option{WithSecret("s3cr3t")},
+			signature: sign("s3cr3t", refsChanged),
+			body:      refsChanged,
+			want:      want{status: http.StatusAccepted, enqueued: []string{"match"}},
+		},
+		"InvalidSignature": {
+			kube:      kube,
+			o:         []Option{WithSecret("s3cr3t")},
+			signature: sign("other", refsChanged),
+			body:      refsChanged,
+			want:      want{status: http.StatusUnauthorized},
+		},
+		"MissingSignature": {
+			kube: kube,
+			o:    []Option{WithSecret("s3cr3t")},
+			body: refsChanged,
+			want: want{status: http.StatusUnauthorized},
+		},
+		"NoSecret": {
+			kube: kube,
+			body: refsChanged,
+			want: want{status: http.StatusUnauthorized},
+		},
+		"InvalidBody": {
+			kube: kube,
+			o:    []Option{WithInsecure()},
+			body: `{`,
+			want: want{status: http.StatusBadRequest},
+		},
+		"ListFailed": {
+			kube: &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+			o:    []Option{WithInsecure()},
+			body: refsChanged,
+			want: want{status: http.StatusServiceUnavailable},
+		},
+		"Get": {
+			kube:   kube,
+			o:      []Option{WithInsecure()},
+			method: http.MethodGet,
+			want:   want{status: http.StatusMethodNotAllowed},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := New(tc.kube, logging.NewNopLogger(), tc.o...)
+			r.Register(&webhookv1alpha1.WebhookList{})
+
+			method := tc.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req := httptest.NewRequest(method, Path, strings.NewReader(tc.body))
+			if tc.signature != "" {
+				req.Header.Set(headerSignature, tc.signature)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			var enqueued []string
+			for len(r.kinds[0].events) > 0 {
+				e := <-r.kinds[0].events
+				enqueued = append(enqueued, e.Object.GetName())
+			}
+			if diff := cmp.Diff(tc.want, want{status: w.Code, enqueued: enqueued}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("ServeHTTP(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serverinfo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis/serverinfo/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket/fake"
+)
+
+var _ managed.ExternalConnecter = &connector{}
+var _ managed.ExternalClient = &external{}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		o   v1alpha1.ServerInfoObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		info bitbucket.ServerInfo
+		err  error
+		want want
+	}{
+		"Observed": {
+			info: bitbucket.ServerInfo{Version: "7.21.0", BuildNumber: "7021000", DisplayName: "Bitbucket"},
+			want: want{o: v1alpha1.ServerInfoObservation{
+				Version:     "7.21.0",
+				BuildNumber: "7021000",
+				DisplayName: "Bitbucket",
+			}},
+		},
+		"GetFailed": {
+			err:  errBoom,
+			want: want{err: errors.Wrap(errBoom, errGetFailed)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{service: &fake.MockServerInfoClient{
+				MockGetServerInfo: func(_ context.Context) (bitbucket.ServerInfo, error) {
+					return tc.info, tc.err
+				},
+			}}
+			cr := &v1alpha1.ServerInfo{}
+			got, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("Observe(...): -want error, +got error:\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if !got.ResourceExists || !got.ResourceUpToDate {
+				t.Errorf("Observe(...): want existing and up to date, got %+v", got)
+			}
+			if diff := cmp.Diff(tc.want.o, cr.Status.AtProvider); diff != "" {
+				t.Errorf("Observe(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
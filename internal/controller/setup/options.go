@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package setup contains the options shared by all controllers of the
+// provider.
+package setup
+
+import (
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/configcache"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/listcache"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/preflight"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/receiver"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/throttle"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/features"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/generate"
+)
+
+// Options configure the controllers of the provider.
+type Options struct {
+	// Logger used by the controllers.
+	Logger logging.Logger
+
+	// GlobalRateLimiter limits the rate of reconciles across all
+	// controllers.
+	GlobalRateLimiter workqueue.RateLimiter
+
+	// MaxConcurrentReconciles is the maximum number of concurrent
+	// reconciles of each controller.
+	MaxConcurrentReconciles int
+
+	// PollInterval at which managed resources are checked for drift from
+	// their desired state.
+	PollInterval time.Duration
+
+	// PollJitter is the longest random delay added to the polls of managed
+	// resources and to their first reconcile after a start, so that they
+	// are not all observed at once. No delay if zero.
+	PollJitter time.Duration
+
+	// Timeout of a single reconcile of a managed resource, including all
+	// requests to the Bitbucket API.
+	Timeout time.Duration
+
+	// DebugHTTP logs all requests to and responses of the Bitbucket API with
+	// secrets redacted.
+	DebugHTTP bool
+
+	// WarnUnknownFields logs the fields of responses of the Bitbucket API
+	// which the provider doesn't know, e.g. after an upgrade of Bitbucket.
+	WarnUnknownFields bool
+
+	// WebhookDeliveries exports the recent deliveries of every webhook as
+	// metrics, which takes one more request per observation of a webhook.
+	WebhookDeliveries bool
+
+	// Throttle holds back the reconciles of all controllers while the
+	// Bitbucket server of a ProviderConfig throttles requests or cannot be
+	// reached.
+	Throttle *throttle.Gate
+
+	// Receiver of the events of Bitbucket webhooks, which reconciles the
+	// managed resources of a repository when it changes. Nil if events
+	// are not received.
+	Receiver *receiver.Receiver
+
+	// ListCache observes the webhooks and access keys of a repository with
+	// one cached list request instead of one request per managed resource.
+	// Nil if every managed resource is observed with its own request.
+	ListCache *listcache.Cache
+
+	// ConfigCache keeps the client configuration of ProviderConfigs,
+	// including their credentials, between reconciles. Nil if it is read
+	// on every reconcile.
+	ConfigCache *configcache.Cache
+
+	// Preflight checks that the credentials of a ProviderConfig have the
+	// permissions a managed resource needs when connecting. Nil if they
+	// are not checked.
+	Preflight *preflight.Checker
+
+	// Passwords generates the secrets of webhooks which don't specify one.
+	Passwords generate.Passwords
+
+	// Keys generates the key pairs of access keys which don't specify a
+	// public key.
+	Keys generate.Keys
+
+	// Features enabled by feature gates.
+	Features *features.Flags
+}
+
+// ForControllerRuntime returns the controller-runtime options of a
+// controller.
+func (o Options) ForControllerRuntime() controller.Options {
+	return controller.Options{
+		MaxConcurrentReconciles: o.MaxConcurrentReconciles,
+		RateLimiter:             ratelimiter.NewDefaultManagedRateLimiter(o.GlobalRateLimiter),
+	}
+}
+
+// HTTPLogger returns the logger of the requests to the Bitbucket API of the
+// named controller, nil if they are not logged.
+func (o Options) HTTPLogger(controller string) logging.Logger {
+	if !o.DebugHTTP {
+		return nil
+	}
+	return o.Logger.WithValues("controller", controller)
+}
+
+// ClientLogger returns the logger of the Bitbucket API client of the named
+// controller.
+func (o Options) ClientLogger(controller string) logging.Logger {
+	return o.Logger.WithValues("controller", controller)
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package setup
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/jitter"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/pause"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/throttle"
+)
+
+// Reconciler wraps the reconciler of the managed resources of the supplied
+// kind with the reconcilers all controllers share, outermost first: the
+// jitter of polls, pausing and throttling. Each of them gets the managed
+// resource from the cache of the manager, not from the API server.
+func (o Options) Reconciler(m ctrl.Manager, gvk schema.GroupVersionKind, r reconcile.Reconciler) reconcile.Reconciler {
+	of := resource.ManagedKind(gvk)
+	return jitter.NewReconciler(m, of, o.PollJitter, pause.NewReconciler(m, of, throttle.NewReconciler(m, of, o.Throttle, r)))
+}
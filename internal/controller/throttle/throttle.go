@@ -0,0 +1,210 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package throttle holds back the reconciles of managed resources while
+// their Bitbucket server throttles requests, is in maintenance or cannot be
+// reached.
+package throttle
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clock"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/metrics"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+const (
+	// minUnreachableBackoff is the delay after the server could not be
+	// reached for the first time. It doubles with every further attempt.
+	minUnreachableBackoff = 10 * time.Second
+	// maxUnreachableBackoff is the longest delay between attempts to reach
+	// the server.
+	maxUnreachableBackoff = 5 * time.Minute
+)
+
+// A Gate holds back the reconciles of all managed resources of a
+// ProviderConfig until its server accepts requests again. It is shared by
+// all controllers. A nil Gate never holds back reconciles.
+type Gate struct {
+	mu     sync.Mutex
+	closed map[string]closed
+	clock  clock.Clock
+}
+
+type closed struct {
+	until time.Time
+	// attempts to reach the server since it was last reachable
+	attempts int
+}
+
+// NewGate returns an open Gate telling the time by the clock.
+func NewGate(clk clock.Clock) *Gate {
+	return &Gate{closed: map[string]closed{}, clock: clk}
+}
+
+func unreachableBackoff(attempts int) time.Duration {
+	d := minUnreachableBackoff
+	for i := 1; i < attempts && d < maxUnreachableBackoff; i++ {
+		d *= 2
+	}
+	if d > maxUnreachableBackoff {
+		return maxUnreachableBackoff
+	}
+	return d
+}
+
+// wait returns how long the reconciles of the ProviderConfig are held back.
+func (g *Gate) wait(pc string) time.Duration {
+	if g == nil {
+		return 0
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.closed[pc].until.Sub(g.clock.Now())
+}
+
+// throttled holds back the reconciles of the ProviderConfig for at least d.
+func (g *Gate) throttled(pc string, d time.Duration) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c := g.closed[pc]
+	if until := g.clock.Now().Add(d); until.After(c.until) {
+		c.until = until
+	}
+	g.closed[pc] = c
+}
+
+// unreachable holds back the reconciles of the ProviderConfig for a backoff
+// growing with every failed attempt and returns it.
+func (g *Gate) unreachable(pc string) time.Duration {
+	if g == nil {
+		return 0
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c := g.closed[pc]
+	// Reconciles which started before the gate was closed by another one
+	// do not count as further attempts.
+	if d := c.until.Sub(g.clock.Now()); d > 0 {
+		return d
+	}
+	c.attempts++
+	d := unreachableBackoff(c.attempts)
+	c.until = g.clock.Now().Add(d)
+	g.closed[pc] = c
+	return d
+}
+
+// reachable resets the backoff of the ProviderConfig.
+func (g *Gate) reachable(pc string) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if c, ok := g.closed[pc]; ok && !c.until.After(g.clock.Now()) {
+		delete(g.closed, pc)
+	}
+}
+
+// retryAfter returns the delay the server asked for if the response was
+// throttled or the server is in maintenance.
+func retryAfter(md bitbucket.ResponseMetadata) (time.Duration, bool) {
+	if md.RetryAfter <= 0 {
+		return 0, false
+	}
+	switch md.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return md.RetryAfter, true
+	}
+	return 0, false
+}
+
+// A Reconciler requeues managed resources after the delay the server asked
+// for, or after a backoff shared by all managed resources of a
+// ProviderConfig when the server cannot be reached, instead of the backoff
+// of each managed resource.
+type Reconciler struct {
+	client     client.Client
+	newManaged func() resource.Managed
+	gate       *Gate
+	reconciler reconcile.Reconciler
+}
+
+// NewReconciler wraps the supplied reconciler of managed resources of the
+// supplied kind so that the reconciles of all managed resources of a
+// ProviderConfig are held back while its server does not accept requests.
+func NewReconciler(m ctrl.Manager, of resource.ManagedKind, g *Gate, r reconcile.Reconciler) *Reconciler {
+	nm := func() resource.Managed {
+		return resource.MustCreateObject(schema.GroupVersionKind(of), m.GetScheme()).(resource.Managed)
+	}
+	return &Reconciler{client: m.GetClient(), newManaged: nm, gate: g, reconciler: r}
+}
+
+// Reconcile a managed resource unless its server does not accept requests.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	pc := r.providerConfig(ctx, req)
+	if d := r.gate.wait(pc); d > 0 {
+		return reconcile.Result{RequeueAfter: d}, nil
+	}
+
+	ctx, rec := bitbucket.WithMetadataRecorder(ctx)
+	res, err := r.reconciler.Reconcile(ctx, req)
+	md, ok := rec.Last()
+	if !ok {
+		return res, err
+	}
+	metrics.RecordResponse(pc, md)
+	if d, ok := retryAfter(md); ok {
+		r.gate.throttled(pc, d)
+		return reconcile.Result{RequeueAfter: d}, err
+	}
+	if md.StatusCode == 0 {
+		if d := r.gate.unreachable(pc); d > 0 {
+			return reconcile.Result{RequeueAfter: d}, err
+		}
+		return res, err
+	}
+	r.gate.reachable(pc)
+	return res, err
+}
+
+// providerConfig returns the name of the ProviderConfig of the managed
+// resource, or an empty name if it cannot be read.
+func (r *Reconciler) providerConfig(ctx context.Context, req reconcile.Request) string {
+	mg := r.newManaged()
+	if err := r.client.Get(ctx, req.NamespacedName, mg); err != nil {
+		return ""
+	}
+	if ref := mg.GetProviderConfigReference(); ref != nil {
+		return ref.Name
+	}
+	return ""
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package throttle
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	webhookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clock"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+func TestReconcile(t *testing.T) {
+	now := time.Unix(1000, 0)
+	pollInterval := reconcile.Result{RequeueAfter: time.Minute}
+
+	type want struct {
+		result     reconcile.Result
+		reconciled bool
+		closed     map[string]closed
+	}
+
+	cases := map[string]struct {
+		closed    map[string]closed
+		responses []bitbucket.ResponseMetadata
+		want      want
+	}{
+		"NoRequests": {
+			want: want{result: pollInterval, reconciled: true, closed: map[string]closed{}},
+		},
+		"Success": {
+			responses: []bitbucket.ResponseMetadata{{StatusCode: http.StatusOK}},
+			want:      want{result: pollInterval, reconciled: true, closed: map[string]closed{}},
+		},
+		"RateLimited": {
+			responses: []bitbucket.ResponseMetadata{{StatusCode: http.StatusTooManyRequests, RetryAfter: 30 * time.Second}},
+			want: want{
+				result:     reconcile.Result{RequeueAfter: 30 * time.Second},
+				reconciled: true,
+				closed:     map[string]closed{"example": {until: now.Add(30 * time.Second)}},
+			},
+		},
+		"Maintenance": {
+			responses: []bitbucket.ResponseMetadata{
+				{StatusCode: http.StatusOK},
+				{StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Hour},
+			},
+			want: want{
+				result:     reconcile.Result{RequeueAfter: time.Hour},
+				reconciled: true,
+				closed:     map[string]closed{"example": {until: now.Add(time.Hour)}},
+			},
+		},
+		"ServerErrorWithoutRetryAfter": {
+			responses: []bitbucket.ResponseMetadata{{StatusCode: http.StatusServiceUnavailable}},
+			want:      want{result: pollInterval, reconciled: true, closed: map[string]closed{}},
+		},
+		"Unreachable": {
+			responses: []bitbucket.ResponseMetadata{{}},
+			want: want{
+				result:     reconcile.Result{RequeueAfter: 10 * time.Second},
+				reconciled: true,
+				closed:     map[string]closed{"example": {until: now.Add(10 * time.Second), attempts: 1}},
+			},
+		},
+		"StillUnreachable": {
+			closed:    map[string]closed{"example": {until: now, attempts: 3}},
+			responses: []bitbucket.ResponseMetadata{{}},
+			want: want{
+				result:     reconcile.Result{RequeueAfter: 80 * time.Second},
+				reconciled: true,
+				closed:     map[string]closed{"example": {until: now.Add(80 * time.Second), attempts: 4}},
+			},
+		},
+		"ReachableAgain": {
+			closed:    map[string]closed{"example": {until: now, attempts: 3}},
+			responses: []bitbucket.ResponseMetadata{{StatusCode: http.StatusOK}},
+			want:      want{result: pollInterval, reconciled: true, closed: map[string]closed{}},
+		},
+		"GateClosed": {
+			closed: map[string]closed{"example": {until: now.Add(10 * time.Second)}},
+			want: want{
+				result: reconcile.Result{RequeueAfter: 10 * time.Second},
+				closed: map[string]closed{"example": {until: now.Add(10 * time.Second)}},
+			},
+		},
+		"GateOfOtherProviderConfigClosed": {
+			closed:    map[string]closed{"other": {until: now.Add(10 * time.Second)}},
+			responses: []bitbucket.ResponseMetadata{{StatusCode: http.StatusOK}},
+			want: want{
+				result:     pollInterval,
+				reconciled: true,
+				closed:     map[string]closed{"other": {until: now.Add(10 * time.Second)}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := NewGate(clock.Fixed(now))
+			if tc.closed != nil {
+				g.closed = tc.closed
+			}
+
+			reconciled := false
+			r := &Reconciler{
+				client: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						obj.(resource.Managed).SetProviderConfigReference(&xpv1.Reference{Name: "example"})
+						return nil
+					}),
+				},
+				newManaged: func() resource.Managed { return &webhookv1alpha1.Webhook{} },
+				gate:       g,
+				reconciler: reconcile.Func(func(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+					reconciled = true
+					for _, md := range tc.responses {
+						bitbucket.MetadataRecorderFrom(ctx).Record(md)
+					}
+					return pollInterval, nil
+				}),
+			}
+
+			got, err := r.Reconcile(context.Background(), reconcile.Request{})
+			if err != nil {
+				t.Fatalf("Reconcile(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("Reconcile(...): -want, +got\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.reconciled, reconciled); diff != "" {
+				t.Errorf("Reconcile(...): -want, +got\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.closed, g.closed, cmp.AllowUnexported(closed{})); diff != "" {
+				t.Errorf("Reconcile(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnreachableBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  10 * time.Second,
+		2:  20 * time.Second,
+		5:  160 * time.Second,
+		6:  5 * time.Minute,
+		50: 5 * time.Minute,
+	}
+	for attempts, want := range cases {
+		if got := unreachableBackoff(attempts); got != want {
+			t.Errorf("unreachableBackoff(%d): want %v, got %v", attempts, want, got)
+		}
+	}
+}
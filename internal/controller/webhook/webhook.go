@@ -18,70 +18,123 @@ package webhook
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/base64"
+	"crypto/sha256"
 	"fmt"
-	"strconv"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
-	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
-	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
 	"github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
-	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clients"
-	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/apierror"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/conditions"
 	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/config"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/configcache"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/deletionprotection"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/dependents"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/drift"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/externalname"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/filter"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/lateinit"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/listcache"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/managementpolicy"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/moved"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/orphan"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/preflight"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/setup"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/features"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/generate"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/metrics"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
 )
 
 const (
 	errNotWebhook   = "managed resource is not a Webhook custom resource"
 	errTrackPCUsage = "cannot track ProviderConfig usage"
 	errGetPC        = "cannot get ProviderConfig"
-	errGetCreds     = "cannot get credentials"
 
 	errGetFailed    = "cannot get webhook from bitbucket API"
 	errDeleteFailed = "cannot delete webhook from bitbucket API"
 	errCreateFailed = "cannot create webhook with bitbucket API"
 	errUpdateFailed = "cannot update webhook with bitbucket API"
+	errListFailed   = "cannot list webhooks with bitbucket API"
+	errURLTemplate  = "cannot render the URL template of the webhook"
+	errURLSecret    = "cannot get the URL of the webhook from its secret"
+	errURLSecretKey = "secret has no key %s"
 )
 
 // Setup adds a controller that reconciles Webhook managed resources.
-func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter) error {
+func Setup(mgr ctrl.Manager, o setup.Options) error {
 	name := managed.ControllerName(v1alpha1.WebhookGroupKind)
-
-	o := controller.Options{
-		RateLimiter: ratelimiter.NewDefaultManagedRateLimiter(rl),
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	c := &connector{
+		kube:         mgr.GetClient(),
+		log:          o.Logger,
+		recorder:     recorder,
+		usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+		newServiceFn: clients.NewWebhookClient,
+		httpLog:      o.HTTPLogger(name),
+		clientLog:    o.ClientLogger(name),
+		warnFields:   o.WarnUnknownFields,
+		configs:      o.ConfigCache,
+		cache:        o.ListCache,
+		passwords:    o.Passwords,
+		deliveries:   o.WebhookDeliveries,
+
+		preflight:       o.Preflight,
+		newPermissionFn: clients.NewPermissionClient,
 	}
+	var conn managed.ExternalConnecter = deletionprotection.NewConnecter(conditions.NewConnecter(c))
+	conn = managementpolicy.NewConnecter(conn, o.Features.Enabled(features.EnableManagementPolicies))
 
-	r := managed.NewReconciler(mgr,
+	errs := apierror.NewTracker()
+	r := managed.NewReconciler(errs.Manager(mgr),
 		resource.ManagedKind(v1alpha1.WebhookGroupVersionKind),
-		managed.WithExternalConnecter(&connector{
-			kube:         mgr.GetClient(),
-			log:          l,
-			usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
-			newServiceFn: clients.NewWebhookClient}),
-		managed.WithLogger(l.WithValues("controller", name)),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
-
-	return ctrl.NewControllerManagedBy(mgr).
+		managed.WithExternalConnecter(errs.NewConnecter(conn)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(o.Timeout),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(recorder))
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		Named(name).
-		WithOptions(o).
-		For(&v1alpha1.Webhook{}).
-		Complete(r)
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Webhook{}, builder.WithPredicates(filter.Changes()))
+	b, err := dependents.Watch(mgr, b, o, &v1alpha1.Webhook{}, func() resource.ManagedList { return &v1alpha1.WebhookList{} }, urlSecret)
+	if err != nil {
+		return err
+	}
+	if o.Receiver != nil {
+		b = b.Watches(o.Receiver.Register(&v1alpha1.WebhookList{}), &handler.EnqueueRequestForObject{})
+	}
+	return b.Complete(o.Reconciler(mgr, v1alpha1.WebhookGroupVersionKind, orphan.NewReconciler(mgr, resource.ManagedKind(v1alpha1.WebhookGroupVersionKind), c, recorder, r)))
+}
+
+// urlSecret returns the key of the Secret with the URL of the webhook, if
+// any.
+func urlSecret(mg resource.Managed) []string {
+	ref := mg.(*v1alpha1.Webhook).Spec.ForProvider.Webhook.URLSecretRef
+	if ref == nil {
+		return nil
+	}
+	return []string{dependents.SecretKey(ref.Namespace, ref.Name)}
 }
 
 // A connector is expected to produce an ExternalClient when its Connect method
@@ -90,7 +143,20 @@ type connector struct {
 	kube         client.Client
 	usage        resource.Tracker
 	log          logging.Logger
+	recorder     event.Recorder
 	newServiceFn func(clients.Config) bitbucket.WebhookClientAPI
+	httpLog      logging.Logger
+	clientLog    logging.Logger
+	warnFields   bool
+	configs      *configcache.Cache
+	cache        *listcache.Cache
+	passwords    generate.Passwords
+	deliveries   bool
+
+	// preflight checks the permissions of the credentials on the
+	// repository before the webhook is observed or changed
+	preflight       *preflight.Checker
+	newPermissionFn func(clients.Config) bitbucket.PermissionClientAPI
 }
 
 // Connect typically produces an ExternalClient by:
@@ -113,19 +179,19 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 		return nil, errors.Wrap(err, errGetPC)
 	}
 
-	cd := pc.Spec.Credentials
-	data, err := resource.CommonCredentialExtractor(ctx, cd.Source, c.kube, cd.CommonCredentialSelectors)
+	cfg, err := c.configs.ClientConfig(ctx, c.kube, pc, config.ClientConfig)
 	if err != nil {
-		return nil, errors.Wrap(err, errGetCreds)
+		return nil, err
 	}
+	cfg.HTTPLogger = c.httpLog
+	cfg.Logger = c.clientLog
+	cfg.WarnUnknownFields = c.warnFields
+	if err := c.preflight.RepoAdmin(ctx, c.newPermissionFn(cfg), pc.GetName(), cr.Repo()); err != nil {
+		return nil, err
+	}
+	svc := c.cache.Webhooks(c.newServiceFn(cfg), pc.GetName())
 
-	svc := c.newServiceFn(clients.Config{
-		BaseURL:   pc.Spec.BaseURL,
-		Token:     string(data),
-		TLSConfig: config.NewTLSConfig(*pc),
-	})
-
-	return &external{service: svc, log: c.log, pwgen: pwgen}, nil
+	return &external{service: svc, kube: c.kube, log: c.log, recorder: c.recorder, passwords: c.passwords, deliveries: c.deliveries}, nil
 }
 
 // An ExternalClient observes, then either creates, updates, or deletes an
@@ -133,9 +199,18 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 type external struct {
 	// A 'client' used to connect to the external resource API. In practice this
 	// would be something like an AWS SDK client.
-	service bitbucket.WebhookClientAPI
-	log     logging.Logger
-	pwgen   func() (string, error)
+	service   bitbucket.WebhookClientAPI
+	kube      client.Client
+	log       logging.Logger
+	recorder  event.Recorder
+	passwords generate.Passwords
+
+	// deliveries exports the recent deliveries of the webhook as metrics
+	deliveries bool
+
+	// observed is the webhook as got by Observe, which Update reuses
+	// instead of getting it again in the same reconcile
+	observed *bitbucket.Webhook
 }
 
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
@@ -148,35 +223,57 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, nil
 	}
 
-	externalName := meta.GetExternalName(cr)
-	id, err := strconv.Atoi(externalName)
+	// An external name which is no ID is an error, unless the webhook is
+	// not created yet, since reporting the webhook as missing would create
+	// a duplicate.
+	id, err := externalname.ID(cr)
 	if err != nil {
-		return managed.ExternalObservation{}, nil // nolint // This is ok as it does not exists
+		if externalname.Pending(cr) {
+			return managed.ExternalObservation{}, nil
+		}
+		return managed.ExternalObservation{}, err
 	}
 
 	hook, err := c.service.GetWebhook(ctx, cr.Repo(), id)
 	if err != nil {
 		if errors.Is(err, bitbucket.ErrNotFound) {
-			return managed.ExternalObservation{}, nil
+			return managed.ExternalObservation{}, c.checkRepository(ctx, cr)
 		}
 		return managed.ExternalObservation{}, errors.Wrap(err, errGetFailed)
 	}
 
-	cr.Status.SetConditions(xpv1.Available())
-
-	crBeforeLateInit := cr.DeepCopy()
-	if cr.Spec.ForProvider.Webhook.Configuration == nil {
-		cr.Spec.ForProvider.Webhook.Configuration = &v1alpha1.BitbucketWebhookConfiguration{}
+	c.observed = &hook
+	observe(cr, hook)
+	if c.deliveries {
+		c.recordDeliveries(ctx, cr, id)
 	}
-	cr.Spec.ForProvider.Webhook.Configuration.Secret = lateInitializeString(cr.Spec.ForProvider.Webhook.Configuration.Secret, hook.Configuration.Secret)
-	resourceLateInitialized := !cmp.Equal(cr.Spec.ForProvider, crBeforeLateInit.Spec.ForProvider)
-
-	cr.Status.AtProvider.ID = hook.ID
+	c.observeRepository(ctx, cr)
 
 	ignoreEventOrder := cmpopts.SortSlices(func(a, b string) bool { return a < b })
-	ignoreID := cmpopts.IgnoreFields(bitbucket.Webhook{}, "ID")
+	ignore := cmpopts.IgnoreFields(bitbucket.Webhook{}, observedOnly...)
+
+	// A secret from initProvider is neither late initialized nor kept in
+	// sync, so that it can be rotated in Bitbucket.
+	resourceLateInitialized := false
+	ignoreSecret := cmp.Options{}
+	if cr.InitSecret() != "" {
+		ignoreSecret = cmp.Options{cmpopts.IgnoreFields(bitbucket.WebhookConfiguration{}, "Secret")}
+	} else {
+		resourceLateInitialized = lateInitialize(&cr.Spec.ForProvider.Webhook, hook)
+	}
 
-	diff := cmp.Diff(cr.Webhook(), hook, ignoreEventOrder, ignoreID)
+	want, err := c.desired(ctx, cr)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	got := hook
+	got.Configuration.Extra = onlyKeys(hook.Configuration.Extra, want.Configuration.Extra)
+	diff := cmp.Diff(want, got, ignoreEventOrder, ignore, ignoreSecret, redactSecret)
+	if diff != "" {
+		fields := drift.Fields(want, got, ignoreEventOrder, ignore, ignoreSecret)
+		metrics.RecordDrift(v1alpha1.WebhookKind, fields)
+		c.recorder.Event(cr, drift.Event(fields))
+	}
 
 	return managed.ExternalObservation{
 		// Return false when the external resource does not exist. This lets
@@ -199,26 +296,102 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	}, nil
 }
 
-func pwgen() (string, error) {
-	b := make([]byte, 20)
-	_, err := rand.Read(b)
+// onlyKeys returns the options of the configuration with the keys of the
+// wanted options, nil if there are none, so that options set in Bitbucket
+// but not in the spec don't count as drift.
+func onlyKeys(options, wanted map[string]string) map[string]string {
+	var ret map[string]string
+	for k := range wanted {
+		v, ok := options[k]
+		if !ok {
+			continue
+		}
+		if ret == nil {
+			ret = map[string]string{}
+		}
+		ret[k] = v
+	}
+	return ret
+}
+
+// observedOnly are the fields of a webhook which are set by the server
+var observedOnly = []string{"ID", "Active", "CreatedDate", "UpdatedDate"}
+
+// observe records the fields set by the server in the status of the
+// webhook.
+func observe(cr *v1alpha1.Webhook, hook bitbucket.Webhook) {
+	cr.Status.AtProvider.ID = hook.ID
+	cr.Status.AtProvider.Active = hook.Active
+	cr.Status.AtProvider.CreatedAt = timestamp(hook.CreatedDate)
+	cr.Status.AtProvider.UpdatedAt = timestamp(hook.UpdatedDate)
+}
+
+// timestamp returns the time of the milliseconds since the epoch, nil for
+// zero.
+func timestamp(ms int64) *metav1.Time {
+	if ms == 0 {
+		return nil
+	}
+	t := metav1.NewTime(time.Unix(0, ms*int64(time.Millisecond)))
+	return &t
+}
+
+// desired returns the webhook as configured by the managed resource, with
+// its URL rendered from the URL template or read from the URL secret.
+func (c *external) desired(ctx context.Context, cr *v1alpha1.Webhook) (bitbucket.Webhook, error) {
+	hook := cr.Webhook()
+	if ref := cr.Spec.ForProvider.Webhook.URLSecretRef; ref != nil {
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return bitbucket.Webhook{}, errors.Wrap(err, errURLSecret)
+		}
+		url, ok := s.Data[ref.Key]
+		if !ok {
+			return bitbucket.Webhook{}, errors.Wrap(errors.Errorf(errURLSecretKey, ref.Key), errURLSecret)
+		}
+		hook.URL = string(url)
+		return hook, nil
+	}
+	url, err := cr.WebhookURL()
 	if err != nil {
-		return "", err
+		return bitbucket.Webhook{}, errors.Wrap(err, errURLTemplate)
 	}
-	return base64.StdEncoding.EncodeToString(b), nil
+	hook.URL = url
+	return hook, nil
 }
 
+// redactSecret hides the webhook secret in the diff of Observe, which ends
+// up in the logs. Secrets are replaced by a short hash so that a changed
+// secret still shows up in the diff.
+var redactSecret = cmp.FilterPath(func(p cmp.Path) bool {
+	sf, ok := p.Last().(cmp.StructField)
+	return ok && sf.Name() == "Secret"
+}, cmp.Transformer("RedactSecret", func(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(secret))
+	return fmt.Sprintf("<redacted %x>", sum[:4])
+}))
+
 func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
 	cr, ok := mg.(*v1alpha1.Webhook)
 	if !ok {
 		return managed.ExternalCreation{}, errors.New(errNotWebhook)
 	}
 
-	cr.Status.SetConditions(xpv1.Creating())
-
-	hook := cr.Webhook()
+	hook, err := c.desired(ctx, cr)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	if hook.Configuration.Secret == "" {
+		hook.Configuration.Secret = cr.InitSecret()
+	}
+	if c := cr.Spec.InitProvider.Configuration; c != nil && hook.Configuration.Extra == nil {
+		hook.Configuration.Extra = c.Extra
+	}
 	if hook.Configuration.Secret == "" {
-		secret, err := c.pwgen()
+		secret, err := c.passwords.Password()
 		if err != nil {
 			return managed.ExternalCreation{}, errors.Wrap(err, "could not generate random password")
 		}
@@ -226,21 +399,22 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		hook.Configuration.Secret = secret
 	}
 
+	hook.Configuration.Extra = withCreatedFor(hook.Configuration.Extra, cr)
+
 	key, err := c.service.CreateWebhook(ctx, cr.Repo(), hook)
 	if err != nil {
 		return managed.ExternalCreation{}, errors.Wrap(err, errCreateFailed)
 	}
 
 	meta.SetExternalName(cr, fmt.Sprint(key.ID))
-	cr.Status.SetConditions(xpv1.Available())
-
-	//	cr.Status.AtProvider.ID = key.ID TODO do we want this?
+	observe(cr, key)
 
 	return managed.ExternalCreation{
 		// Optionally return any details that may be required to connect to the
 		// external resource. These will be stored as the connection secret.
 		ConnectionDetails: managed.ConnectionDetails{
-			"secret": []byte(hook.Configuration.Secret),
+			v1alpha1.ConnectionSecretKey: []byte(hook.Configuration.Secret),
+			v1alpha1.ConnectionURLKey:    []byte(hook.URL),
 		},
 		ExternalNameAssigned: true,
 	}, nil
@@ -252,35 +426,186 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalUpdate{}, errors.New(errNotWebhook)
 	}
 
-	id, _ := strconv.Atoi(meta.GetExternalName(cr))
-	if _, err := c.service.UpdateWebhook(ctx, cr.Repo(), id, cr.Webhook()); err != nil {
+	id, err := externalname.ID(cr)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	hook, err := c.desired(ctx, cr)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	if cr.InitSecret() != "" {
+		// Keep the secret, which may have been rotated since the webhook
+		// was created.
+		current, err := c.current(ctx, cr, id)
+		if err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+		hook.Configuration.Secret = current.Configuration.Secret
+	}
+	if _, err := c.service.UpdateWebhook(ctx, cr.Repo(), id, hook); err != nil {
 		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateFailed)
 	}
 
-	cr.Status.SetConditions(xpv1.Available())
-
 	return managed.ExternalUpdate{}, nil
 }
 
+// current returns the webhook as observed in this reconcile, or gets it if
+// it was not observed.
+func (c *external) current(ctx context.Context, cr *v1alpha1.Webhook, id int) (bitbucket.Webhook, error) {
+	if c.observed != nil && c.observed.ID == id {
+		return *c.observed, nil
+	}
+	hook, err := c.service.GetWebhook(ctx, cr.Repo(), id)
+	if err != nil {
+		return bitbucket.Webhook{}, errors.Wrap(err, errGetFailed)
+	}
+	return hook, nil
+}
+
 func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 	cr, ok := mg.(*v1alpha1.Webhook)
 	if !ok {
 		return errors.New(errNotWebhook)
 	}
 
-	cr.Status.SetConditions(xpv1.Deleting())
-
-	id, _ := strconv.Atoi(meta.GetExternalName(cr)) // TODO err
-	if err := c.service.DeleteWebhook(ctx, cr.Repo(), id); err != nil {
+	// A webhook which was never created has nothing to delete, while any
+	// other external name which is no ID must not drop the finalizer and
+	// leave the webhook behind.
+	id, err := externalname.ID(cr)
+	if err != nil {
+		if externalname.Pending(cr) {
+			return nil
+		}
+		return err
+	}
+	if err := c.service.DeleteWebhook(ctx, cr.Repo(), id); err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
 		return errors.Wrap(err, errDeleteFailed)
 	}
 
+	metrics.DeleteWebhookDeliveries(cr.GetName(), cr.Repo())
 	return nil
 }
 
-func lateInitializeString(s string, from string) string {
-	if from == "" {
-		return s
+// clockSkew is how far the clocks of the provider and Bitbucket may differ
+const clockSkew = time.Minute
+
+// configCreatedFor is the option of the configuration of a webhook which
+// records the UID of the managed resource it was created for, so that Cleanup
+// only deletes webhooks the provider created. Updates drop the option, which
+// is only needed until the external name is recorded.
+const configCreatedFor = "crossplaneCreatedFor"
+
+// withCreatedFor returns a copy of the options of a configuration which
+// records that the webhook is created for the managed resource.
+func withCreatedFor(extra map[string]string, cr *v1alpha1.Webhook) map[string]string {
+	ret := make(map[string]string, len(extra)+1)
+	for k, v := range extra {
+		ret[k] = v
+	}
+	ret[configCreatedFor] = string(cr.GetUID())
+	return ret
+}
+
+// Cleanup deletes the webhooks with the name and URL of the managed resource
+// which were created for it since the supplied time, which a create whose
+// external name was not recorded left behind. Webhooks with the same name and
+// URL which were not created for the managed resource are kept.
+func (c *connector) Cleanup(ctx context.Context, mg resource.Managed, since time.Time) (int, error) {
+	cr, ok := mg.(*v1alpha1.Webhook)
+	if !ok {
+		return 0, errors.New(errNotWebhook)
+	}
+	ec, err := c.Connect(ctx, cr)
+	if err != nil {
+		return 0, err
+	}
+	return ec.(*external).cleanup(ctx, cr, since)
+}
+
+func (c *external) cleanup(ctx context.Context, cr *v1alpha1.Webhook, since time.Time) (int, error) {
+	want, err := c.desired(ctx, cr)
+	if err != nil {
+		return 0, err
+	}
+	hooks, err := c.service.ListWebhooks(ctx, cr.Repo(), bitbucket.WebhookFilter{})
+	if errors.Is(err, bitbucket.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, errListFailed)
+	}
+	n := 0
+	for _, h := range hooks {
+		created := timestamp(h.CreatedDate)
+		if h.Name != want.Name || h.URL != want.URL || created == nil || created.Time.Before(since.Add(-clockSkew)) {
+			continue
+		}
+		if cr.GetUID() == "" || h.Configuration.Extra[configCreatedFor] != string(cr.GetUID()) {
+			continue
+		}
+		if err := c.service.DeleteWebhook(ctx, cr.Repo(), h.ID); err != nil && !errors.Is(err, bitbucket.ErrNotFound) {
+			return n, errors.Wrap(err, errDeleteFailed)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// recordDeliveries exports the counts of the recent deliveries of the
+// webhook. The counts are only informational, so failing to get them does
+// not fail the observation.
+func (c *external) recordDeliveries(ctx context.Context, cr *v1alpha1.Webhook, id int) {
+	s, err := c.service.GetWebhookStatistics(ctx, cr.Repo(), id)
+	if err != nil {
+		c.log.Debug("Cannot get webhook statistics", "name", cr.GetName(), "error", err)
+		return
+	}
+	metrics.SetWebhookDeliveries(cr.GetName(), cr.Repo(), s)
+}
+
+// observeRepository records the repository of the webhook in its status
+// unless it was recorded before. The repository is only informational, so
+// failing to get it does not fail the observation.
+func (c *external) observeRepository(ctx context.Context, cr *v1alpha1.Webhook) {
+	if cr.Status.AtProvider.Repository != nil {
+		return
+	}
+	repo, err := c.service.GetRepository(ctx, cr.Repo())
+	if err != nil {
+		c.log.Debug("Cannot get repository", "name", cr.GetName(), "error", err)
+		return
+	}
+	if repo.ID == 0 {
+		return
+	}
+	cr.Status.AtProvider.Repository = &v1alpha1.RepositoryObservation{
+		ID:           repo.ID,
+		Slug:         repo.Slug,
+		HTTPCloneURL: repo.CloneURLs["http"],
+		SSHCloneURL:  repo.CloneURLs["ssh"],
+	}
+}
+
+// checkRepository returns an error if the repository the webhook was created
+// in was renamed, moved or deleted, instead of creating the webhook again.
+// Webhooks being deleted are not checked, so that they can be deleted.
+func (c *external) checkRepository(ctx context.Context, cr *v1alpha1.Webhook) error {
+	r := cr.Status.AtProvider.Repository
+	if r == nil || meta.WasDeleted(cr) {
+		return nil
+	}
+	return moved.Check(ctx, c.service, cr.Repo(), r.ID)
+}
+
+// lateInitialize fills the unset optional fields of the webhook from the
+// observed webhook. It returns true if any field was set.
+func lateInitialize(in *v1alpha1.BitbucketWebhook, hook bitbucket.Webhook) bool {
+	if hook.Configuration.Secret == "" {
+		return false
+	}
+	if in.Configuration == nil {
+		in.Configuration = &v1alpha1.BitbucketWebhookConfiguration{}
 	}
-	return from
+	return lateinit.String(&in.Configuration.Secret, hook.Configuration.Secret)
 }
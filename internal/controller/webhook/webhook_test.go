@@ -19,48 +19,116 @@ package webhook
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
-	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clients/bitbucket"
-	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clients/bitbucket/fake"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/drift"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/externalname"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/moved"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/generate"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/metrics"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket/fake"
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	xpfake "github.com/crossplane/crossplane-runtime/pkg/resource/fake"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 )
 
 type resourceModifier func(*v1alpha1.Webhook)
 
-func withConditions(c ...xpv1.Condition) resourceModifier {
-	return func(r *v1alpha1.Webhook) { r.Status.ConditionedStatus.Conditions = c }
-}
-
 func withSecret(secret string) resourceModifier {
 	return func(r *v1alpha1.Webhook) { r.Spec.ForProvider.Webhook.Configuration.Secret = secret }
 }
 
+func withExtra(extra map[string]string) resourceModifier {
+	return func(r *v1alpha1.Webhook) { r.Spec.ForProvider.Webhook.Configuration.Extra = extra }
+}
+
+func withoutConfiguration() resourceModifier {
+	return func(r *v1alpha1.Webhook) { r.Spec.ForProvider.Webhook.Configuration = nil }
+}
+
+func withDeletionPolicy(p xpv1.DeletionPolicy) resourceModifier {
+	return func(r *v1alpha1.Webhook) { r.SetDeletionPolicy(p) }
+}
+
+func withInitSecret(secret string) resourceModifier {
+	return func(r *v1alpha1.Webhook) {
+		r.Spec.InitProvider.Configuration = &v1alpha1.BitbucketWebhookConfiguration{Secret: secret}
+	}
+}
+
+func withExternalNameString(name string) resourceModifier {
+	return func(r *v1alpha1.Webhook) { meta.SetExternalName(r, name) }
+}
+
+func withName(name string) resourceModifier {
+	return func(r *v1alpha1.Webhook) { r.SetName(name) }
+}
+
 func withExternalName(id int) resourceModifier {
 	return func(r *v1alpha1.Webhook) { meta.SetExternalName(r, fmt.Sprint(id)) }
 }
 
+func withObservedID(id int) resourceModifier {
+	return func(r *v1alpha1.Webhook) { r.Status.AtProvider.ID = id }
+}
+
+func withRepository(id int) resourceModifier {
+	return func(r *v1alpha1.Webhook) {
+		r.Status.AtProvider.Repository = &v1alpha1.RepositoryObservation{ID: id}
+	}
+}
+
 func withURL(url string) resourceModifier {
 	return func(r *v1alpha1.Webhook) { r.Spec.ForProvider.Webhook.URL = url }
 }
 
+func withURLTemplate(t string) resourceModifier {
+	return func(r *v1alpha1.Webhook) {
+		r.Spec.ForProvider.Webhook.URL = ""
+		r.Spec.ForProvider.Webhook.URLTemplate = t
+	}
+}
+
+func withURLSecretRef() resourceModifier {
+	return func(r *v1alpha1.Webhook) {
+		r.Spec.ForProvider.Webhook.URL = ""
+		r.Spec.ForProvider.Webhook.URLSecretRef = &xpv1.SecretKeySelector{
+			SecretReference: xpv1.SecretReference{Namespace: namespace, Name: "receiver"},
+			Key:             "url",
+		}
+	}
+}
+
 const (
 	namespace = "cool-namespace"
 
 	connectionSecretName = "cool-connection-secret"
+
+	uid = "cool-uid"
 )
 
 func instance(rm ...resourceModifier) *v1alpha1.Webhook {
 	r := &v1alpha1.Webhook{
+		ObjectMeta: metav1.ObjectMeta{UID: uid},
 		Spec: v1alpha1.WebhookSpec{
 			ResourceSpec: xpv1.ResourceSpec{
 				WriteConnectionSecretToReference: &xpv1.SecretReference{
@@ -94,6 +162,19 @@ func instance(rm ...resourceModifier) *v1alpha1.Webhook {
 	return r
 }
 
+// An eventRecorder records the events of the resources.
+type eventRecorder struct {
+	events []event.Event
+}
+
+func (r *eventRecorder) Event(_ runtime.Object, e event.Event) {
+	r.events = append(r.events, e)
+}
+
+func (r *eventRecorder) WithAnnotations(_ ...string) event.Recorder {
+	return r
+}
+
 var _ managed.ExternalClient = &external{}
 var _ managed.ExternalConnecter = &connector{}
 
@@ -103,9 +184,10 @@ func TestObserve(t *testing.T) {
 		r  bitbucket.WebhookClientAPI
 	}
 	type want struct {
-		cr  *v1alpha1.Webhook
-		o   managed.ExternalObservation
-		err error
+		cr     *v1alpha1.Webhook
+		o      managed.ExternalObservation
+		err    error
+		events []event.Event
 	}
 
 	errorBoom := errors.New("error")
@@ -124,7 +206,7 @@ func TestObserve(t *testing.T) {
 				},
 			},
 			want: want{
-				cr: instance(withExternalName(99), withConditions(xpv1.Available())),
+				cr: instance(withExternalName(99)),
 				o: managed.ExternalObservation{
 					ResourceExists:    true,
 					ResourceUpToDate:  true,
@@ -144,12 +226,122 @@ func TestObserve(t *testing.T) {
 				},
 			},
 			want: want{
-				cr: instance(withExternalName(99), withConditions(xpv1.Available())),
+				cr: instance(withExternalName(99)),
 				o: managed.ExternalObservation{
 					ResourceExists:    true,
 					ResourceUpToDate:  false,
 					ConnectionDetails: managed.ConnectionDetails{},
 				},
+				events: []event.Event{drift.Event([]string{"URL"})},
+			},
+		},
+		"ExtraUpToDate": {
+			args: args{
+				cr: instance(withExternalName(99), withExtra(map[string]string{"algorithm": "sha256"})),
+				r: &fake.MockWebhookClient{
+					MockGetWebhook: func(_ context.Context, repo bitbucket.Repo, id int) (result bitbucket.Webhook, err error) {
+						return instance(withExtra(map[string]string{"algorithm": "sha256", "createdBy": "bb"})).Webhook(), nil
+					},
+				},
+			},
+			want: want{
+				cr: instance(withExternalName(99), withExtra(map[string]string{"algorithm": "sha256"})),
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  true,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+			},
+		},
+		"ExtraNotUpToDate": {
+			args: args{
+				cr: instance(withExternalName(99), withExtra(map[string]string{"algorithm": "sha256"})),
+				r: &fake.MockWebhookClient{
+					MockGetWebhook: func(_ context.Context, repo bitbucket.Repo, id int) (result bitbucket.Webhook, err error) {
+						return instance(withExtra(map[string]string{"createdBy": "bb"})).Webhook(), nil
+					},
+				},
+			},
+			want: want{
+				cr: instance(withExternalName(99), withExtra(map[string]string{"algorithm": "sha256"})),
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  false,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+				events: []event.Event{drift.Event([]string{"Configuration.Extra"})},
+			},
+		},
+		"LateInitialized": {
+			args: args{
+				cr: instance(withExternalName(99), withoutConfiguration()),
+				r: &fake.MockWebhookClient{
+					MockGetWebhook: func(_ context.Context, repo bitbucket.Repo, id int) (result bitbucket.Webhook, err error) {
+						return instance(withExternalName(99)).Webhook(), nil
+					},
+				},
+			},
+			want: want{
+				cr: instance(withExternalName(99)),
+				o: managed.ExternalObservation{
+					ResourceExists:          true,
+					ResourceUpToDate:        true,
+					ResourceLateInitialized: true,
+					ConnectionDetails:       managed.ConnectionDetails{},
+				},
+			},
+		},
+		"InitSecretRotated": {
+			args: args{
+				cr: instance(withExternalName(99), withoutConfiguration(), withInitSecret("123")),
+				r: &fake.MockWebhookClient{
+					MockGetWebhook: func(_ context.Context, repo bitbucket.Repo, id int) (result bitbucket.Webhook, err error) {
+						return instance(withSecret("rotated")).Webhook(), nil
+					},
+				},
+			},
+			want: want{
+				cr: instance(withExternalName(99), withoutConfiguration(), withInitSecret("123")),
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  true,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+			},
+		},
+		"NoSecretObserved": {
+			args: args{
+				cr: instance(withExternalName(99), withoutConfiguration()),
+				r: &fake.MockWebhookClient{
+					MockGetWebhook: func(_ context.Context, repo bitbucket.Repo, id int) (result bitbucket.Webhook, err error) {
+						return instance(withoutConfiguration()).Webhook(), nil
+					},
+				},
+			},
+			want: want{
+				cr: instance(withExternalName(99), withoutConfiguration()),
+				o: managed.ExternalObservation{
+					ResourceExists:    true,
+					ResourceUpToDate:  true,
+					ConnectionDetails: managed.ConnectionDetails{},
+				},
+			},
+		},
+		"NotAnID": {
+			args: args{
+				cr: instance(withExternalNameString("ci-hook")),
+			},
+			want: want{
+				cr:  instance(withExternalNameString("ci-hook")),
+				err: errors.Wrapf(externalname.ErrInvalid, "%q is not an ID", "ci-hook"),
+			},
+		},
+		"NotCreatedYet": {
+			args: args{
+				cr: instance(withName("ci-hook"), withExternalNameString("ci-hook")),
+			},
+			want: want{
+				cr: instance(withName("ci-hook"), withExternalNameString("ci-hook")),
 			},
 		},
 		"NoExternalName": {
@@ -193,13 +385,54 @@ func TestObserve(t *testing.T) {
 				},
 			},
 		},
+		"NotFoundInSameRepository": {
+			args: args{
+				cr: instance(withExternalName(99), withRepository(5)),
+				r: &fake.MockWebhookClient{
+					MockGetWebhook: func(_ context.Context, _ bitbucket.Repo, _ int) (bitbucket.Webhook, error) {
+						return bitbucket.Webhook{}, bitbucket.ErrNotFound
+					},
+					MockGetRepository: func(_ context.Context, repo bitbucket.Repo) (bitbucket.Repository, error) {
+						return bitbucket.Repository{ID: 5, Slug: repo.Repo}, nil
+					},
+				},
+			},
+			want: want{
+				cr: instance(withExternalName(99), withRepository(5)),
+				o: managed.ExternalObservation{
+					ResourceExists: false,
+				},
+			},
+		},
+		"RepositoryGone": {
+			args: args{
+				cr: instance(withExternalName(99), withRepository(5)),
+				r: &fake.MockWebhookClient{
+					MockGetWebhook: func(_ context.Context, _ bitbucket.Repo, _ int) (bitbucket.Webhook, error) {
+						return bitbucket.Webhook{}, bitbucket.ErrNotFound
+					},
+					MockGetRepository: func(_ context.Context, _ bitbucket.Repo) (bitbucket.Repository, error) {
+						return bitbucket.Repository{}, bitbucket.ErrNotFound
+					},
+					MockListRepositories: func(_ context.Context, _ string) ([]bitbucket.Repository, error) {
+						return nil, nil
+					},
+				},
+			},
+			want: want{
+				cr:  instance(withExternalName(99), withRepository(5)),
+				err: errors.Wrap(moved.ErrGone, "repository 5 at PROJ/repo"),
+			},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
+			recorder := &eventRecorder{}
 			e := external{
-				service: tc.r,
-				log:     logging.NewNopLogger(),
+				service:  tc.r,
+				recorder: recorder,
+				log:      logging.NewNopLogger(),
 			}
 			o, err := e.Observe(context.Background(), tc.args.cr)
 			if diff := cmp.Diff(tc.want.cr, tc.args.cr); diff != "" {
@@ -211,10 +444,161 @@ func TestObserve(t *testing.T) {
 			if diff := cmp.Diff(tc.want.o, o, cmpopts.IgnoreFields(o, "Diff")); diff != "" {
 				t.Errorf("Observe(...): -want, +got\n%s", diff)
 			}
+			if diff := cmp.Diff(tc.want.events, recorder.events, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Observe(...): -want events, +got events\n%s", diff)
+			}
+			drifted := tc.want.o.ResourceExists && !tc.want.o.ResourceUpToDate
+			if diff := cmp.Diff(drifted, o.Diff != ""); diff != "" {
+				t.Errorf("Observe(...): -want drift in diff, +got drift in diff\n%s", diff)
+			}
 		})
 	}
 }
 
+func TestObserveRedactsSecret(t *testing.T) {
+	e := external{
+		service: &fake.MockWebhookClient{
+			MockGetWebhook: func(_ context.Context, repo bitbucket.Repo, id int) (result bitbucket.Webhook, err error) {
+				return instance(withSecret("observed-secret")).Webhook(), nil
+			},
+		},
+		log:      logging.NewNopLogger(),
+		recorder: event.NewNopRecorder(),
+	}
+	o, err := e.Observe(context.Background(), instance(withExternalName(99), withSecret("desired-secret")))
+	if err != nil {
+		t.Fatalf("Observe(...): %v", err)
+	}
+	if o.ResourceUpToDate {
+		t.Errorf("Observe(...): want a changed secret to not be up to date")
+	}
+	for _, secret := range []string{"observed-secret", "desired-secret"} {
+		if strings.Contains(o.Diff, secret) {
+			t.Errorf("Observe(...): diff contains secret %q:\n%s", secret, o.Diff)
+		}
+	}
+}
+
+func TestObserveRecordsDeliveries(t *testing.T) {
+	cr := instance(withExternalName(99))
+	e := external{
+		service: &fake.MockWebhookClient{
+			MockGetWebhook: func(_ context.Context, repo bitbucket.Repo, id int) (result bitbucket.Webhook, err error) {
+				return instance().Webhook(), nil
+			},
+			MockGetWebhookStatistics: func(_ context.Context, repo bitbucket.Repo, id int) (result bitbucket.WebhookStatistics, err error) {
+				return bitbucket.WebhookStatistics{Successes: 10, Failures: 2, Errors: 1}, nil
+			},
+		},
+		log:        logging.NewNopLogger(),
+		recorder:   event.NewNopRecorder(),
+		deliveries: true,
+	}
+	if _, err := e.Observe(context.Background(), cr); err != nil {
+		t.Fatalf("Observe(...): %v", err)
+	}
+
+	want := map[string]float64{metrics.OutcomeSuccess: 10, metrics.OutcomeFailure: 2, metrics.OutcomeError: 1}
+	for outcome, count := range want {
+		got := testutil.ToFloat64(metrics.WebhookDeliveries.WithLabelValues(cr.GetName(), cr.Repo().ProjectKey, cr.Repo().Repo, outcome))
+		if got != count {
+			t.Errorf("Observe(...): want %v %s deliveries, got %v", count, outcome, got)
+		}
+	}
+}
+
+func TestObserveRecordsDrift(t *testing.T) {
+	e := external{
+		service: &fake.MockWebhookClient{
+			MockGetWebhook: func(_ context.Context, repo bitbucket.Repo, id int) (result bitbucket.Webhook, err error) {
+				return instance(withURL("https://changed.example.com")).Webhook(), nil
+			},
+		},
+		log:      logging.NewNopLogger(),
+		recorder: event.NewNopRecorder(),
+	}
+	drifted := metrics.DriftDetected.WithLabelValues(v1alpha1.WebhookKind, "URL")
+	before := testutil.ToFloat64(drifted)
+	if _, err := e.Observe(context.Background(), instance(withExternalName(99))); err != nil {
+		t.Fatalf("Observe(...): %v", err)
+	}
+	if got := testutil.ToFloat64(drifted) - before; got != 1 {
+		t.Errorf("Observe(...): want 1 drift of the URL, got %v", got)
+	}
+}
+
+func TestObserveRecordsServerFields(t *testing.T) {
+	active := true
+	cr := instance(withExternalName(99))
+	e := external{
+		service: &fake.MockWebhookClient{
+			MockGetWebhook: func(_ context.Context, repo bitbucket.Repo, id int) (result bitbucket.Webhook, err error) {
+				hook := instance().Webhook()
+				hook.ID = id
+				hook.Active = &active
+				hook.CreatedDate = 1600000000000
+				hook.UpdatedDate = 1600000001500
+				return hook, nil
+			},
+			MockGetRepository: func(_ context.Context, repo bitbucket.Repo) (bitbucket.Repository, error) {
+				return bitbucket.Repository{}, nil
+			},
+		},
+		log:      logging.NewNopLogger(),
+		recorder: event.NewNopRecorder(),
+	}
+	o, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("Observe(...): %v", err)
+	}
+	if !o.ResourceUpToDate {
+		t.Errorf("Observe(...): fields set by the server must not be drift, got diff %s", o.Diff)
+	}
+
+	created := metav1.NewTime(time.Unix(1600000000, 0))
+	updated := metav1.NewTime(time.Unix(1600000001, 500*int64(time.Millisecond)))
+	want := v1alpha1.WebhookObservation{ID: 99, Active: &active, CreatedAt: &created, UpdatedAt: &updated}
+	if diff := cmp.Diff(want, cr.Status.AtProvider); diff != "" {
+		t.Errorf("Observe(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestObserveRecordsRepository(t *testing.T) {
+	cr := instance(withExternalName(99))
+	e := external{
+		service: &fake.MockWebhookClient{
+			MockGetWebhook: func(_ context.Context, repo bitbucket.Repo, id int) (result bitbucket.Webhook, err error) {
+				return instance().Webhook(), nil
+			},
+			MockGetRepository: func(_ context.Context, repo bitbucket.Repo) (bitbucket.Repository, error) {
+				return bitbucket.Repository{
+					ID:   7,
+					Slug: repo.Repo,
+					CloneURLs: map[string]string{
+						"http": "https://bitbucket.example.com/scm/proj/repo.git",
+						"ssh":  "ssh://git@bitbucket.example.com:7999/proj/repo.git",
+					},
+				}, nil
+			},
+		},
+		log:      logging.NewNopLogger(),
+		recorder: event.NewNopRecorder(),
+	}
+	if _, err := e.Observe(context.Background(), cr); err != nil {
+		t.Fatalf("Observe(...): %v", err)
+	}
+
+	want := &v1alpha1.RepositoryObservation{
+		ID:           7,
+		Slug:         "repo",
+		HTTPCloneURL: "https://bitbucket.example.com/scm/proj/repo.git",
+		SSHCloneURL:  "ssh://git@bitbucket.example.com:7999/proj/repo.git",
+	}
+	if diff := cmp.Diff(want, cr.Status.AtProvider.Repository); diff != "" {
+		t.Errorf("Observe(...): -want, +got\n%s", diff)
+	}
+}
+
 func TestCreate(t *testing.T) {
 	type args struct {
 		cr *v1alpha1.Webhook
@@ -244,11 +628,54 @@ func TestCreate(t *testing.T) {
 				},
 			},
 			want: want{
-				cr: instance(withConditions(xpv1.Available()), withExternalName(22)),
+				cr: instance(withExternalName(22), withObservedID(22)),
 				o: managed.ExternalCreation{
 					ExternalNameAssigned: true,
 					ConnectionDetails: managed.ConnectionDetails{
-						"secret": []byte(instance().Webhook().Configuration.Secret),
+						v1alpha1.ConnectionSecretKey: []byte(instance().Webhook().Configuration.Secret),
+						v1alpha1.ConnectionURLKey:    []byte(instance().Webhook().URL),
+					},
+				},
+			},
+		},
+		"SuccessfulURLTemplate": {
+			args: args{
+				cr: instance(withURLTemplate("https://ci.example.com/{{ .ProjectKey }}/{{ .RepoSlug }}")),
+				r: &fake.MockWebhookClient{
+					MockCreateWebhook: func(_ context.Context, repo bitbucket.Repo, hook bitbucket.Webhook) (result bitbucket.Webhook, err error) {
+						hook.ID = 22
+						return hook, nil
+					},
+				},
+			},
+			want: want{
+				cr: instance(withExternalName(22), withObservedID(22), withURLTemplate("https://ci.example.com/{{ .ProjectKey }}/{{ .RepoSlug }}")),
+				o: managed.ExternalCreation{
+					ExternalNameAssigned: true,
+					ConnectionDetails: managed.ConnectionDetails{
+						v1alpha1.ConnectionSecretKey: []byte(instance().Webhook().Configuration.Secret),
+						v1alpha1.ConnectionURLKey:    []byte("https://ci.example.com/proj/repo"),
+					},
+				},
+			},
+		},
+		"SuccessfulURLSecret": {
+			args: args{
+				cr: instance(withURLSecretRef()),
+				r: &fake.MockWebhookClient{
+					MockCreateWebhook: func(_ context.Context, repo bitbucket.Repo, hook bitbucket.Webhook) (result bitbucket.Webhook, err error) {
+						hook.ID = 22
+						return hook, nil
+					},
+				},
+			},
+			want: want{
+				cr: instance(withExternalName(22), withObservedID(22), withURLSecretRef()),
+				o: managed.ExternalCreation{
+					ExternalNameAssigned: true,
+					ConnectionDetails: managed.ConnectionDetails{
+						v1alpha1.ConnectionSecretKey: []byte(instance().Webhook().Configuration.Secret),
+						v1alpha1.ConnectionURLKey:    []byte("https://receiver.example.com"),
 					},
 				},
 			},
@@ -264,11 +691,57 @@ func TestCreate(t *testing.T) {
 				},
 			},
 			want: want{
-				cr: instance(withConditions(xpv1.Available()), withExternalName(22), withSecret("")),
+				cr: instance(withExternalName(22), withObservedID(22), withSecret("")),
+				o: managed.ExternalCreation{
+					ExternalNameAssigned: true,
+					ConnectionDetails: managed.ConnectionDetails{
+						v1alpha1.ConnectionSecretKey: mockSecret,
+						v1alpha1.ConnectionURLKey:    []byte(instance().Webhook().URL),
+					},
+				},
+			},
+		},
+		"SuccessfulExtra": {
+			args: args{
+				cr: instance(withExtra(map[string]string{"algorithm": "sha256"})),
+				r: &fake.MockWebhookClient{
+					MockCreateWebhook: func(_ context.Context, repo bitbucket.Repo, hook bitbucket.Webhook) (result bitbucket.Webhook, err error) {
+						if diff := cmp.Diff(map[string]string{"algorithm": "sha256", configCreatedFor: uid}, hook.Configuration.Extra); diff != "" {
+							t.Errorf("CreateWebhook(...): -want extra, +got extra:\n%s", diff)
+						}
+						hook.ID = 22
+						return hook, nil
+					},
+				},
+			},
+			want: want{
+				cr: instance(withExternalName(22), withObservedID(22), withExtra(map[string]string{"algorithm": "sha256"})),
+				o: managed.ExternalCreation{
+					ExternalNameAssigned: true,
+					ConnectionDetails: managed.ConnectionDetails{
+						v1alpha1.ConnectionSecretKey: []byte(instance().Webhook().Configuration.Secret),
+						v1alpha1.ConnectionURLKey:    []byte(instance().Webhook().URL),
+					},
+				},
+			},
+		},
+		"SuccessfulInitSecret": {
+			args: args{
+				cr: instance(withoutConfiguration(), withInitSecret("init")),
+				r: &fake.MockWebhookClient{
+					MockCreateWebhook: func(_ context.Context, repo bitbucket.Repo, hook bitbucket.Webhook) (result bitbucket.Webhook, err error) {
+						hook.ID = 22
+						return hook, nil
+					},
+				},
+			},
+			want: want{
+				cr: instance(withExternalName(22), withObservedID(22), withoutConfiguration(), withInitSecret("init")),
 				o: managed.ExternalCreation{
 					ExternalNameAssigned: true,
 					ConnectionDetails: managed.ConnectionDetails{
-						"secret": mockSecret,
+						v1alpha1.ConnectionSecretKey: []byte("init"),
+						v1alpha1.ConnectionURLKey:    []byte(instance().Webhook().URL),
 					},
 				},
 			},
@@ -283,7 +756,7 @@ func TestCreate(t *testing.T) {
 				},
 			},
 			want: want{
-				cr:  instance(withConditions(xpv1.Creating())),
+				cr:  instance(),
 				o:   managed.ExternalCreation{},
 				err: errors.Wrap(errorBoom, errCreateFailed),
 			},
@@ -294,7 +767,16 @@ func TestCreate(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			e := external{
 				service: tc.r,
-				pwgen:   func() (string, error) { return string(mockSecret), nil },
+				kube: &test.MockClient{
+					MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+						if key.Namespace != namespace || key.Name != "receiver" {
+							t.Errorf("Create(...): unexpected secret %v", key)
+						}
+						obj.(*corev1.Secret).Data = map[string][]byte{"url": []byte("https://receiver.example.com")}
+						return nil
+					},
+				},
+				passwords: generate.PasswordsFn(func() (string, error) { return string(mockSecret), nil }),
 			}
 			o, err := e.Create(context.Background(), tc.args.cr)
 			if diff := cmp.Diff(tc.want.cr, tc.args.cr); diff != "" {
@@ -312,8 +794,9 @@ func TestCreate(t *testing.T) {
 
 func TestUpdate(t *testing.T) {
 	type args struct {
-		cr *v1alpha1.Webhook
-		r  bitbucket.WebhookClientAPI
+		cr       *v1alpha1.Webhook
+		r        bitbucket.WebhookClientAPI
+		observed *bitbucket.Webhook
 	}
 	type want struct {
 		cr  *v1alpha1.Webhook
@@ -332,9 +815,6 @@ func TestUpdate(t *testing.T) {
 			args: args{
 				cr: instance(withExternalName(99), withURL(newURL)),
 				r: &fake.MockWebhookClient{
-					MockGetWebhook: func(_ context.Context, repo bitbucket.Repo, id int) (result bitbucket.Webhook, err error) {
-						return instance().Webhook(), nil
-					},
 					MockUpdateWebhook: func(_ context.Context, repo bitbucket.Repo, id int, hook bitbucket.Webhook) (result bitbucket.Webhook, err error) {
 						if hook.URL != newURL {
 							t.Errorf("Update not called with desired URL")
@@ -344,17 +824,59 @@ func TestUpdate(t *testing.T) {
 				},
 			},
 			want: want{
-				cr: instance(withExternalName(99), withURL(newURL), withConditions(xpv1.Available())),
+				cr: instance(withExternalName(99), withURL(newURL)),
 				o:  managed.ExternalUpdate{},
 			},
 		},
-		"Failed": {
+		"KeepsRotatedInitSecret": {
 			args: args{
-				cr: instance(withExternalName(99), withURL(newURL)),
+				cr: instance(withExternalName(99), withoutConfiguration(), withInitSecret("123"), withURL(newURL)),
+				r: &fake.MockWebhookClient{
+					MockGetWebhook: func(_ context.Context, repo bitbucket.Repo, id int) (result bitbucket.Webhook, err error) {
+						return instance(withSecret("rotated")).Webhook(), nil
+					},
+					MockUpdateWebhook: func(_ context.Context, repo bitbucket.Repo, id int, hook bitbucket.Webhook) (result bitbucket.Webhook, err error) {
+						if hook.Configuration.Secret != "rotated" {
+							t.Errorf("Update not called with the rotated secret")
+						}
+						return hook, nil
+					},
+				},
+			},
+			want: want{
+				cr: instance(withExternalName(99), withoutConfiguration(), withInitSecret("123"), withURL(newURL)),
+				o:  managed.ExternalUpdate{},
+			},
+		},
+		"KeepsObservedInitSecret": {
+			args: args{
+				cr: instance(withExternalName(99), withoutConfiguration(), withInitSecret("123"), withURL(newURL)),
 				r: &fake.MockWebhookClient{
 					MockGetWebhook: func(_ context.Context, repo bitbucket.Repo, id int) (result bitbucket.Webhook, err error) {
-						return instance().Webhook(), nil
+						return bitbucket.Webhook{}, errorBoom
 					},
+					MockUpdateWebhook: func(_ context.Context, repo bitbucket.Repo, id int, hook bitbucket.Webhook) (result bitbucket.Webhook, err error) {
+						if hook.Configuration.Secret != "rotated" {
+							t.Errorf("Update not called with the observed secret")
+						}
+						return hook, nil
+					},
+				},
+				observed: func() *bitbucket.Webhook {
+					h := instance(withSecret("rotated")).Webhook()
+					h.ID = 99
+					return &h
+				}(),
+			},
+			want: want{
+				cr: instance(withExternalName(99), withoutConfiguration(), withInitSecret("123"), withURL(newURL)),
+				o:  managed.ExternalUpdate{},
+			},
+		},
+		"Failed": {
+			args: args{
+				cr: instance(withExternalName(99), withURL(newURL)),
+				r: &fake.MockWebhookClient{
 					MockUpdateWebhook: func(_ context.Context, repo bitbucket.Repo, id int, hook bitbucket.Webhook) (result bitbucket.Webhook, err error) {
 						return bitbucket.Webhook{}, errorBoom
 					},
@@ -366,25 +888,22 @@ func TestUpdate(t *testing.T) {
 				err: errors.Wrap(errorBoom, errUpdateFailed),
 			},
 		},
-
-		/*		"NoExternalName": {
-					args: args{
-						cr: instance(),
-					},
-					want: want{
-						cr: instance(),
-						o: managed.ExternalObservation{
-							ResourceExists: false,
-						},
-					},
-				},
-			},*/
+		"InvalidExternalName": {
+			args: args{
+				cr: instance(),
+			},
+			want: want{
+				cr:  instance(),
+				err: errors.Wrapf(externalname.ErrInvalid, "%q is not an ID", ""),
+			},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			e := external{
-				service: tc.r,
+				service:  tc.r,
+				observed: tc.args.observed,
 			}
 			o, err := e.Update(context.Background(), tc.args.cr)
 			if diff := cmp.Diff(tc.want.cr, tc.args.cr); diff != "" {
@@ -429,20 +948,47 @@ func TestDelete(t *testing.T) {
 				},
 			},
 			want: want{
-				cr: instance(withExternalName(99), withConditions(xpv1.Deleting())), // TODO clear external name?
+				cr: instance(withExternalName(99)),
 			},
 		},
-		/*		"NoExternalName": {
-				args: args{
-					cr: instance(),
-				},
-				want: want{
-					cr: instance(),
-					o: managed.ExternalObservation{
-						ResourceExists: false,
+		"NoExternalName": {
+			args: args{
+				cr: instance(),
+			},
+			want: want{
+				cr: instance(),
+			},
+		},
+		"PendingExternalName": {
+			args: args{
+				cr: instance(withName("ci-hook"), withExternalNameString("ci-hook")),
+			},
+			want: want{
+				cr: instance(withName("ci-hook"), withExternalNameString("ci-hook")),
+			},
+		},
+		"InvalidExternalName": {
+			args: args{
+				cr: instance(withExternalNameString("ci-hook")),
+			},
+			want: want{
+				cr:  instance(withExternalNameString("ci-hook")),
+				err: errors.Wrapf(externalname.ErrInvalid, "%q is not an ID", "ci-hook"),
+			},
+		},
+		"NotFound": {
+			args: args{
+				cr: instance(withExternalName(99)),
+				r: &fake.MockWebhookClient{
+					MockDeleteWebhook: func(_ context.Context, repo bitbucket.Repo, id int) error {
+						return bitbucket.ErrNotFound
 					},
 				},
-			},*/
+			},
+			want: want{
+				cr: instance(withExternalName(99)),
+			},
+		},
 		"DeleteFailed": {
 			args: args{
 				cr: instance(withExternalName(99)),
@@ -456,7 +1002,7 @@ func TestDelete(t *testing.T) {
 				},
 			},
 			want: want{
-				cr:  instance(withExternalName(99), withConditions(xpv1.Deleting())),
+				cr:  instance(withExternalName(99)),
 				err: errors.Wrap(errorBoom, errDeleteFailed),
 			},
 		},
@@ -477,3 +1023,173 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestDeletionPolicy(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := v1alpha1.SchemeBuilder.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted := func(r *v1alpha1.Webhook) {
+		ts := metav1.Unix(1, 0)
+		r.SetDeletionTimestamp(&ts)
+	}
+
+	type want struct {
+		deleteCalled     bool
+		finalizerRemoved bool
+	}
+
+	cases := map[string]struct {
+		cr   *v1alpha1.Webhook
+		want want
+	}{
+		"Orphan": {
+			cr:   instance(withExternalName(99), withDeletionPolicy(xpv1.DeletionOrphan), deleted),
+			want: want{finalizerRemoved: true},
+		},
+		"Delete": {
+			cr:   instance(withExternalName(99), withDeletionPolicy(xpv1.DeletionDelete), deleted),
+			want: want{deleteCalled: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := want{}
+			svc := &fake.MockWebhookClient{
+				MockGetWebhook: func(_ context.Context, repo bitbucket.Repo, id int) (bitbucket.Webhook, error) {
+					return tc.cr.Webhook(), nil
+				},
+				MockDeleteWebhook: func(_ context.Context, repo bitbucket.Repo, id int) error {
+					got.deleteCalled = true
+					return nil
+				},
+			}
+			kube := &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+					tc.cr.DeepCopyInto(o.(*v1alpha1.Webhook))
+					return nil
+				}),
+				MockUpdate:       test.NewMockUpdateFn(nil),
+				MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+			}
+			r := managed.NewReconciler(&xpfake.Manager{Client: kube, Scheme: s},
+				resource.ManagedKind(v1alpha1.WebhookGroupVersionKind),
+				managed.WithExternalConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+					return &external{service: svc, log: logging.NewNopLogger(), recorder: event.NewNopRecorder()}, nil
+				})),
+				managed.WithFinalizer(resource.FinalizerFns{
+					AddFinalizerFn: func(_ context.Context, _ resource.Object) error { return nil },
+					RemoveFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						got.finalizerRemoved = true
+						return nil
+					},
+				}))
+
+			if _, err := r.Reconcile(context.Background(), reconcile.Request{}); err != nil {
+				t.Fatalf("Reconcile(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("Reconcile(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	errBoom := errors.New("boom")
+	since := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	ms := func(t time.Time) int64 { return t.UnixNano() / int64(time.Millisecond) }
+
+	createdFor := func(uid string) bitbucket.WebhookConfiguration {
+		return bitbucket.WebhookConfiguration{Extra: map[string]string{configCreatedFor: uid}}
+	}
+	hooks := []bitbucket.Webhook{
+		{ID: 1, Name: "name", URL: "https://example.com", CreatedDate: ms(since.Add(-time.Hour)), Configuration: createdFor(uid)},
+		{ID: 2, Name: "other", URL: "https://example.com", CreatedDate: ms(since.Add(time.Second)), Configuration: createdFor(uid)},
+		{ID: 3, Name: "name", URL: "https://example.com/other", CreatedDate: ms(since.Add(time.Second)), Configuration: createdFor(uid)},
+		{ID: 4, Name: "name", URL: "https://example.com", CreatedDate: ms(since.Add(time.Second)), Configuration: createdFor(uid)},
+		{ID: 5, Name: "name", URL: "https://example.com", CreatedDate: ms(since.Add(-30 * time.Second)), Configuration: createdFor(uid)},
+		{ID: 6, Name: "name", URL: "https://example.com", CreatedDate: ms(since.Add(time.Second))},
+		{ID: 7, Name: "name", URL: "https://example.com", CreatedDate: ms(since.Add(time.Second)), Configuration: createdFor("other-uid")},
+	}
+
+	type want struct {
+		n       int
+		err     error
+		deleted []int
+	}
+
+	cases := map[string]struct {
+		cr     *v1alpha1.Webhook
+		list   func(context.Context, bitbucket.Repo, bitbucket.WebhookFilter) ([]bitbucket.Webhook, error)
+		delete error
+		want   want
+	}{
+		"DeletesCreatedSince": {
+			list: func(_ context.Context, _ bitbucket.Repo, _ bitbucket.WebhookFilter) ([]bitbucket.Webhook, error) {
+				return hooks, nil
+			},
+			want: want{n: 2, deleted: []int{4, 5}},
+		},
+		"NoUID": {
+			cr: func() *v1alpha1.Webhook {
+				cr := instance()
+				cr.SetUID("")
+				return cr
+			}(),
+			list: func(_ context.Context, _ bitbucket.Repo, _ bitbucket.WebhookFilter) ([]bitbucket.Webhook, error) {
+				return hooks, nil
+			},
+		},
+		"RepositoryNotFound": {
+			list: func(_ context.Context, _ bitbucket.Repo, _ bitbucket.WebhookFilter) ([]bitbucket.Webhook, error) {
+				return nil, bitbucket.ErrNotFound
+			},
+		},
+		"ListFailed": {
+			list: func(_ context.Context, _ bitbucket.Repo, _ bitbucket.WebhookFilter) ([]bitbucket.Webhook, error) {
+				return nil, errBoom
+			},
+			want: want{err: errors.Wrap(errBoom, errListFailed)},
+		},
+		"DeleteFailed": {
+			list: func(_ context.Context, _ bitbucket.Repo, _ bitbucket.WebhookFilter) ([]bitbucket.Webhook, error) {
+				return hooks, nil
+			},
+			delete: errBoom,
+			want:   want{err: errors.Wrap(errBoom, errDeleteFailed), deleted: []int{4}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var deleted []int
+			e := external{
+				service: &fake.MockWebhookClient{
+					MockListWebhooks: tc.list,
+					MockDeleteWebhook: func(_ context.Context, _ bitbucket.Repo, id int) error {
+						deleted = append(deleted, id)
+						return tc.delete
+					},
+				},
+				log: logging.NewNopLogger(),
+			}
+			cr := tc.cr
+			if cr == nil {
+				cr = instance()
+			}
+			n, err := e.cleanup(context.Background(), cr, since)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("cleanup(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.n, n); diff != "" {
+				t.Errorf("cleanup(...): -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.deleted, deleted); diff != "" {
+				t.Errorf("cleanup(...): -want deleted, +got deleted:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package features contains the feature gates of the provider.
+package features
+
+import "sync"
+
+// A Flag gates a feature of the provider.
+type Flag string
+
+// Feature flags.
+const (
+	// EnableManagementPolicies restricts the operations performed on
+	// external resources to spec.managementPolicies.
+	EnableManagementPolicies Flag = "EnableManagementPolicies"
+)
+
+// Flags of enabled features. The zero value and a nil *Flags have no
+// features enabled.
+type Flags struct {
+	m  map[Flag]bool
+	mu sync.RWMutex
+}
+
+// Enable a feature.
+func (f *Flags) Enable(flag Flag) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.m == nil {
+		f.m = map[Flag]bool{}
+	}
+	f.m[flag] = true
+}
+
+// Enabled returns true if the feature is enabled.
+func (f *Flags) Enabled(flag Flag) bool {
+	if f == nil {
+		return false
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.m[flag]
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package generate provides the generation of secrets and key pairs to the
+// controllers as interfaces, so that tests can make it deterministic.
+package generate
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/pem"
+
+	"github.com/mikesmitty/edkey"
+	"golang.org/x/crypto/ssh"
+)
+
+// Passwords generates random secrets
+type Passwords interface {
+	Password() (string, error)
+}
+
+// A PasswordsFn is a function that satisfies the Passwords interface
+type PasswordsFn func() (string, error)
+
+// Password returns the result of calling the function
+func (fn PasswordsFn) Password() (string, error) {
+	return fn()
+}
+
+// Keys generates SSH key pairs
+type Keys interface {
+	// SSHKey returns the public key in authorized_keys format and the PEM
+	// encoded private key
+	SSHKey() (publicKey string, privateKey []byte, err error)
+}
+
+// A KeysFn is a function that satisfies the Keys interface
+type KeysFn func() (string, []byte, error)
+
+// SSHKey returns the result of calling the function
+func (fn KeysFn) SSHKey() (string, []byte, error) {
+	return fn()
+}
+
+// RandomPasswords generates base64 encoded passwords of 20 random bytes
+var RandomPasswords Passwords = PasswordsFn(randomPassword)
+
+// ED25519Keys generates ed25519 key pairs in OpenSSH format
+var ED25519Keys Keys = KeysFn(ed25519Key)
+
+func randomPassword() (string, error) {
+	b := make([]byte, 20)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+func ed25519Key() (string, []byte, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", nil, err
+	}
+	privateBytes := edkey.MarshalED25519PrivateKey(privateKey)
+
+	// generate encoded private key pem
+	privateKeyPEM := &pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: privateBytes}
+	var private bytes.Buffer
+	if err := pem.Encode(&private, privateKeyPEM); err != nil {
+		return "", nil, err
+	}
+
+	// generate ssh authorized public key
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return "", nil, err
+	}
+	authorizedKey := ssh.MarshalAuthorizedKey(sshPublicKey)
+	return string(authorizedKey), private.Bytes(), nil
+}
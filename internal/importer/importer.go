@@ -0,0 +1,256 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package importer generates managed resources for the webhooks and access
+// keys of an existing Bitbucket server, to bring them under the management
+// of the provider.
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	accesskeyv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/accesskey/v1alpha1"
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	webhookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/externalname"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+const (
+	errListProjects = "cannot list projects"
+	errListRepos    = "cannot list repositories of project %s"
+	errListWebhooks = "cannot list webhooks of repository %s/%s"
+	errListKeys     = "cannot list access keys of repository %s/%s"
+	errToYAML       = "cannot convert %s %s to YAML"
+)
+
+// Client is the Bitbucket API the importer walks
+type Client interface {
+	bitbucket.ProjectClientAPI
+	bitbucket.WebhookClientAPI
+	bitbucket.KeyClientAPI
+}
+
+// An Option configures an Importer
+type Option func(*Importer)
+
+// WithProviderConfig sets the ProviderConfig the managed resources refer to.
+// Defaults to default.
+func WithProviderConfig(name string) Option {
+	return func(i *Importer) {
+		i.providerConfig = name
+	}
+}
+
+// WithObserveOnly sets the management policies of the managed resources to
+// Observe, so that the provider only watches the imported resources.
+func WithObserveOnly() Option {
+	return func(i *Importer) {
+		i.observeOnly = true
+	}
+}
+
+// Importer generates managed resources for existing external resources
+type Importer struct {
+	client         Client
+	log            logging.Logger
+	providerConfig string
+	observeOnly    bool
+}
+
+// NewImporter returns an Importer reading from the Bitbucket client
+func NewImporter(c Client, log logging.Logger, o ...Option) *Importer {
+	i := &Importer{client: c, log: log, providerConfig: "default"}
+	for _, fn := range o {
+		fn(i)
+	}
+	return i
+}
+
+// Import returns managed resources with external names for the webhooks and
+// access keys in all repositories of the projects, or of all projects the
+// credentials can see if none are given.
+func (i *Importer) Import(ctx context.Context, projects ...string) ([]resource.Managed, error) {
+	if len(projects) == 0 {
+		all, err := i.client.ListProjects(ctx, bitbucket.ProjectFilter{})
+		if err != nil {
+			return nil, errors.Wrap(err, errListProjects)
+		}
+		for _, p := range all {
+			projects = append(projects, p.Key)
+		}
+	}
+
+	var ret []resource.Managed
+	for _, p := range projects {
+		repos, err := i.client.ListRepositories(ctx, p)
+		if err != nil {
+			return nil, errors.Wrapf(err, errListRepos, p)
+		}
+		for _, r := range repos {
+			mgs, err := i.importRepo(ctx, p, r)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, mgs...)
+		}
+	}
+	return ret, nil
+}
+
+func (i *Importer) importRepo(ctx context.Context, projectKey string, r bitbucket.Repository) ([]resource.Managed, error) {
+	repo := bitbucket.Repo{ProjectKey: projectKey, Repo: r.Slug}
+	var ret []resource.Managed
+
+	hooks, err := i.client.ListWebhooks(ctx, repo, bitbucket.WebhookFilter{})
+	if err != nil {
+		return nil, errors.Wrapf(err, errListWebhooks, projectKey, r.Slug)
+	}
+	for _, h := range hooks {
+		if cr := i.webhook(projectKey, r, h); cr != nil {
+			ret = append(ret, cr)
+		}
+	}
+
+	keys, err := i.client.ListAccessKeys(ctx, repo, bitbucket.AccessKeyFilter{})
+	if err != nil {
+		return nil, errors.Wrapf(err, errListKeys, projectKey, r.Slug)
+	}
+	for _, k := range keys {
+		ret = append(ret, i.accessKey(projectKey, r, k))
+	}
+	return ret, nil
+}
+
+// supportedEvents are the events the Webhook kind accepts
+var supportedEvents = map[string]bool{
+	string(webhookv1alpha1.EventRepoRefsChanged): true,
+	"repo:modified": true,
+}
+
+// webhook returns a Webhook for the webhook, or nil if it subscribes to
+// events the Webhook kind doesn't support.
+func (i *Importer) webhook(projectKey string, r bitbucket.Repository, h bitbucket.Webhook) *webhookv1alpha1.Webhook {
+	events := make([]webhookv1alpha1.Event, 0, len(h.Events))
+	for _, e := range h.Events {
+		if !supportedEvents[e] {
+			i.log.Info("Skipping webhook with unsupported event", "project", projectKey, "repo", r.Slug, "id", h.ID, "event", e)
+			return nil
+		}
+		events = append(events, webhookv1alpha1.Event(e))
+	}
+
+	cr := &webhookv1alpha1.Webhook{}
+	cr.SetGroupVersionKind(webhookv1alpha1.WebhookGroupVersionKind)
+	cr.SetName(objectName(projectKey, r.Slug, "webhook", strconv.Itoa(h.ID)))
+	meta.SetExternalName(cr, strconv.Itoa(h.ID))
+	cr.Spec.ForProvider = webhookv1alpha1.WebhookParameters{
+		ProjectKey: projectKey,
+		RepoName:   r.Name,
+		RepoSlug:   repoSlug(r),
+		Webhook: webhookv1alpha1.BitbucketWebhook{
+			Name:   h.Name,
+			Events: events,
+			URL:    h.URL,
+		},
+	}
+	if h.Configuration.Secret != "" {
+		cr.Spec.ForProvider.Webhook.Configuration = &webhookv1alpha1.BitbucketWebhookConfiguration{Secret: h.Configuration.Secret}
+	}
+	cr.SetProviderConfigReference(&xpv1.Reference{Name: i.providerConfig})
+	if i.observeOnly {
+		cr.Spec.ManagementPolicies = apisv1alpha1.ManagementPolicies{apisv1alpha1.ManagementActionObserve}
+	}
+	return cr
+}
+
+func (i *Importer) accessKey(projectKey string, r bitbucket.Repository, k bitbucket.AccessKey) *accesskeyv1alpha1.AccessKey {
+	cr := &accesskeyv1alpha1.AccessKey{}
+	cr.SetGroupVersionKind(accesskeyv1alpha1.AccessKeyGroupVersionKind)
+	cr.SetName(objectName(projectKey, r.Slug, "accesskey", strconv.Itoa(k.ID)))
+	meta.SetExternalName(cr, externalname.RepoName(projectKey, r.Slug, k.ID))
+	cr.Spec.ForProvider = accesskeyv1alpha1.AccessKeyParameters{
+		ProjectKey: projectKey,
+		RepoName:   r.Name,
+		RepoSlug:   repoSlug(r),
+		PublicKey: accesskeyv1alpha1.PublicKey{
+			Label:      k.Label,
+			Key:        k.Key,
+			Permission: k.Permission,
+		},
+	}
+	cr.SetProviderConfigReference(&xpv1.Reference{Name: i.providerConfig})
+	if i.observeOnly {
+		cr.Spec.ManagementPolicies = apisv1alpha1.ManagementPolicies{apisv1alpha1.ManagementActionObserve}
+	}
+	return cr
+}
+
+// repoSlug returns the slug of the repository if it can't be derived from
+// its name, e.g. because the repository was renamed.
+func repoSlug(r bitbucket.Repository) string {
+	if bitbucket.RepoSlug(r.Name) == r.Slug {
+		return ""
+	}
+	return r.Slug
+}
+
+var notName = regexp.MustCompile(`[^a-z0-9]+`)
+
+// objectName joins the parts to a valid object name, e.g. prj-my-repo for
+// PRJ and my_repo.
+func objectName(parts ...string) string {
+	return strings.Trim(notName.ReplaceAllString(strings.ToLower(strings.Join(parts, "-")), "-"), "-")
+}
+
+// Write writes the managed resources as YAML documents without status.
+func Write(w io.Writer, mgs []resource.Managed) error {
+	for _, mg := range mgs {
+		gvk := mg.GetObjectKind().GroupVersionKind()
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(mg)
+		if err != nil {
+			return errors.Wrapf(err, errToYAML, gvk.Kind, mg.GetName())
+		}
+		unstructured.RemoveNestedField(obj, "status")
+		unstructured.RemoveNestedField(obj, "metadata", "creationTimestamp")
+		if ip, _, _ := unstructured.NestedMap(obj, "spec", "initProvider"); len(ip) == 0 {
+			unstructured.RemoveNestedField(obj, "spec", "initProvider")
+		}
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return errors.Wrapf(err, errToYAML, gvk.Kind, mg.GetName())
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket/fake"
+)
+
+type fakeClient struct {
+	*fake.MockProjectClient
+	*fake.MockWebhookClient
+	*fake.MockKeyClient
+}
+
+func newFakeClient() fakeClient {
+	repo := bitbucket.Repo{ProjectKey: "PRJ", Repo: "legacy"}
+	return fakeClient{
+		MockProjectClient: &fake.MockProjectClient{
+			MockListProjects: func(_ context.Context, _ bitbucket.ProjectFilter) ([]bitbucket.Project, error) {
+				return []bitbucket.Project{{Key: "PRJ"}}, nil
+			},
+		},
+		MockWebhookClient: &fake.MockWebhookClient{
+			MockListRepositories: func(_ context.Context, projectKey string) ([]bitbucket.Repository, error) {
+				return []bitbucket.Repository{{ID: 1, Slug: "legacy", Name: "My Repo", ProjectKey: projectKey}}, nil
+			},
+			MockListWebhooks: func(_ context.Context, r bitbucket.Repo, _ bitbucket.WebhookFilter) ([]bitbucket.Webhook, error) {
+				if r != repo {
+					return nil, errors.Errorf("unexpected repo %+v", r)
+				}
+				return []bitbucket.Webhook{
+					{ID: 3, Name: "ci", Events: []string{"repo:refs_changed"}, URL: "https://ci.example.com/hook"},
+					{ID: 4, Name: "pr", Events: []string{"pr:opened"}, URL: "https://ci.example.com/pr"},
+				}, nil
+			},
+		},
+		MockKeyClient: &fake.MockKeyClient{
+			MockListAccessKeys: func(_ context.Context, r bitbucket.Repo, _ bitbucket.AccessKeyFilter) ([]bitbucket.AccessKey, error) {
+				return []bitbucket.AccessKey{{ID: 7, Key: "ssh-rsa AAAA", Label: "deploy", Permission: bitbucket.PermissionRepoRead}}, nil
+			},
+		},
+	}
+}
+
+func TestImport(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		yaml string
+		err  error
+	}
+
+	cases := map[string]struct {
+		client   func() fakeClient
+		o        []Option
+		projects []string
+		want     want
+	}{
+		"AllProjects": {
+			client: newFakeClient,
+			want: want{yaml: `---
+apiVersion: webhook.bitbucket-server.crossplane.io/v1alpha1
+kind: Webhook
+metadata:
+  annotations:
+    crossplane.io/external-name: "3"
+  name: prj-legacy-webhook-3
+spec:
+  forProvider:
+    projectKey: PRJ
+    repoName: My Repo
+    repoSlug: legacy
+    webhook:
+      events:
+      - repo:refs_changed
+      name: ci
+      url: https://ci.example.com/hook
+  providerConfigRef:
+    name: default
+---
+apiVersion: accesskey.bitbucket-server.crossplane.io/v1alpha1
+kind: AccessKey
+metadata:
+  annotations:
+    crossplane.io/external-name: PRJ/legacy/7
+  name: prj-legacy-accesskey-7
+spec:
+  forProvider:
+    projectKey: PRJ
+    publicKey:
+      key: ssh-rsa AAAA
+      label: deploy
+      permission: REPO_READ
+    repoName: My Repo
+    repoSlug: legacy
+  providerConfigRef:
+    name: default
+`},
+		},
+		"ObserveOnly": {
+			client: func() fakeClient {
+				c := newFakeClient()
+				c.MockListWebhooks = func(_ context.Context, _ bitbucket.Repo, _ bitbucket.WebhookFilter) ([]bitbucket.Webhook, error) {
+					return nil, nil
+				}
+				return c
+			},
+			o:        []Option{WithProviderConfig("bitbucket"), WithObserveOnly()},
+			projects: []string{"PRJ"},
+			want: want{yaml: `---
+apiVersion: accesskey.bitbucket-server.crossplane.io/v1alpha1
+kind: AccessKey
+metadata:
+  annotations:
+    crossplane.io/external-name: PRJ/legacy/7
+  name: prj-legacy-accesskey-7
+spec:
+  forProvider:
+    projectKey: PRJ
+    publicKey:
+      key: ssh-rsa AAAA
+      label: deploy
+      permission: REPO_READ
+    repoName: My Repo
+    repoSlug: legacy
+  managementPolicies:
+  - Observe
+  providerConfigRef:
+    name: bitbucket
+`},
+		},
+		"ListFailed": {
+			client: func() fakeClient {
+				c := newFakeClient()
+				c.MockListRepositories = func(_ context.Context, _ string) ([]bitbucket.Repository, error) { return nil, errBoom }
+				return c
+			},
+			want: want{err: errors.Wrapf(errBoom, errListRepos, "PRJ")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mgs, err := NewImporter(tc.client(), logging.NewNopLogger(), tc.o...).Import(context.Background(), tc.projects...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("Import(...): -want error, +got error:\n%s", diff)
+			}
+			var b bytes.Buffer
+			if err := Write(&b, mgs); err != nil {
+				t.Fatalf("Write(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want.yaml, b.String()); diff != "" {
+				t.Errorf("Write(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestObjectName(t *testing.T) {
+	if diff := cmp.Diff("user-my-repo-webhook-1", objectName("~USER", "my_repo.", "webhook", "1")); diff != "" {
+		t.Errorf("objectName(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics contains the Prometheus metrics of the provider. They are
+// served with the metrics of the controller-runtime.
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+const namespace = "bitbucket_server"
+
+// Outcomes of webhook deliveries.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeError   = "error"
+)
+
+// WebhookDeliveries is the number of recent deliveries of each Webhook by
+// outcome, as reported by the statistics of the webhook in Bitbucket.
+var WebhookDeliveries = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "webhook_deliveries",
+	Help:      "Recent deliveries of a webhook by outcome: success, failure (error status) or error (no response).",
+}, []string{"webhook", "project", "repo", "outcome"})
+
+// ReconcileRateLimit is the configuration of the global rate limiter of
+// reconciles, the average rate per second and the burst.
+var ReconcileRateLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "reconcile_rate_limit",
+	Help:      "Configuration of the global rate limiter of reconciles: the rate per second and the burst.",
+}, []string{"setting"})
+
+// ReconcileRateLimitDelay is the delay of the last requeue by the global
+// rate limiter of reconciles. It is zero while tokens are available.
+var ReconcileRateLimitDelay = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "reconcile_rate_limit_delay_seconds",
+	Help:      "Delay of the last requeue by the global rate limiter of reconciles, zero while tokens are available.",
+})
+
+// ReconcilesThrottled counts the requeues delayed by the global rate
+// limiter of reconciles.
+var ReconcilesThrottled = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "reconciles_throttled_total",
+	Help:      "Requeues delayed by the global rate limiter of reconciles because no tokens were available.",
+})
+
+// ServerRateLimit is the rate limit Bitbucket reported in the headers of
+// the last response of each ProviderConfig.
+var ServerRateLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "api_rate_limit_tokens",
+	Help:      "Rate limit reported by Bitbucket in the last response: the size of the token bucket (limit) and the remaining tokens.",
+}, []string{"provider_config", "tokens"})
+
+// ServerThrottledResponses counts the 429 responses of Bitbucket.
+var ServerThrottledResponses = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "api_throttled_responses_total",
+	Help:      "Requests rejected by Bitbucket with 429 Too Many Requests.",
+}, []string{"provider_config"})
+
+// APILastSuccess is the time of the last successful response of the
+// Bitbucket server of each ProviderConfig.
+var APILastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "api_last_success_timestamp_seconds",
+	Help:      "Unix time of the last successful response of Bitbucket.",
+}, []string{"provider_config"})
+
+// APIConsecutiveFailures is the number of failed reconciles since the last
+// successful response of the Bitbucket server of each ProviderConfig.
+var APIConsecutiveFailures = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "api_consecutive_failures",
+	Help:      "Reconciles whose last request failed since the last successful response of Bitbucket: no response, 401, 429 or 5xx.",
+}, []string{"provider_config"})
+
+// ServerInfo is 1 for the version of the Bitbucket server of each
+// ProviderConfig.
+var ServerInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "server_info",
+	Help:      "Version of the Bitbucket server of a ProviderConfig, always 1.",
+}, []string{"provider_config", "version"})
+
+// DriftDetected counts the observations of external resources which drifted
+// from the desired state, by kind and the drifted fields.
+var DriftDetected = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "drift_detected_total",
+	Help:      "Observations of external resources which drifted from the desired state, by kind and the comma separated drifted fields.",
+}, []string{"kind", "reason"})
+
+// serverVersions are the versions in ServerInfo, to remove the series of a
+// version once the server was upgraded.
+var serverVersions = struct {
+	sync.Mutex
+	m map[string]string
+}{m: map[string]string{}}
+
+func init() {
+	metrics.Registry.MustRegister(
+		WebhookDeliveries,
+		ReconcileRateLimit,
+		ReconcileRateLimitDelay,
+		ReconcilesThrottled,
+		ServerRateLimit,
+		ServerThrottledResponses,
+		APILastSuccess,
+		APIConsecutiveFailures,
+		ServerInfo,
+		DriftDetected,
+	)
+}
+
+// NewRateLimiter instruments the global rate limiter of reconciles.
+func NewRateLimiter(l *workqueue.BucketRateLimiter) workqueue.RateLimiter {
+	ReconcileRateLimit.WithLabelValues("rate").Set(float64(l.Limit()))
+	ReconcileRateLimit.WithLabelValues("burst").Set(float64(l.Burst()))
+	return &rateLimiter{RateLimiter: l}
+}
+
+type rateLimiter struct {
+	workqueue.RateLimiter
+}
+
+// When implements workqueue.RateLimiter
+func (r *rateLimiter) When(item interface{}) time.Duration {
+	d := r.RateLimiter.When(item)
+	ReconcileRateLimitDelay.Set(d.Seconds())
+	if d > 0 {
+		ReconcilesThrottled.Inc()
+	}
+	return d
+}
+
+// RecordResponse records the outcome and the rate limiting of a response of
+// the Bitbucket server of the ProviderConfig.
+func RecordResponse(pc string, md bitbucket.ResponseMetadata) {
+	if md.StatusCode == http.StatusTooManyRequests {
+		ServerThrottledResponses.WithLabelValues(pc).Inc()
+	}
+	if failed(md) {
+		APIConsecutiveFailures.WithLabelValues(pc).Inc()
+	} else {
+		APILastSuccess.WithLabelValues(pc).SetToCurrentTime()
+		APIConsecutiveFailures.WithLabelValues(pc).Set(0)
+	}
+	if md.RateLimit != nil {
+		ServerRateLimit.WithLabelValues(pc, "limit").Set(float64(md.RateLimit.Limit))
+		ServerRateLimit.WithLabelValues(pc, "remaining").Set(float64(md.RateLimit.Remaining))
+	}
+}
+
+// failed returns whether the response shows that the provider cannot work
+// with the server. Other client errors such as 404 are expected responses.
+func failed(md bitbucket.ResponseMetadata) bool {
+	switch md.StatusCode {
+	case 0, http.StatusUnauthorized, http.StatusTooManyRequests:
+		return true
+	}
+	return md.StatusCode >= http.StatusInternalServerError
+}
+
+// SetServerVersion sets the version of the Bitbucket server of the
+// ProviderConfig.
+func SetServerVersion(pc, version string) {
+	serverVersions.Lock()
+	defer serverVersions.Unlock()
+
+	if old, ok := serverVersions.m[pc]; ok && old != version {
+		ServerInfo.DeleteLabelValues(pc, old)
+	}
+	serverVersions.m[pc] = version
+	ServerInfo.WithLabelValues(pc, version).Set(1)
+}
+
+// DeleteProviderConfig removes the series of the deleted ProviderConfig.
+func DeleteProviderConfig(pc string) {
+	serverVersions.Lock()
+	defer serverVersions.Unlock()
+
+	if v, ok := serverVersions.m[pc]; ok {
+		ServerInfo.DeleteLabelValues(pc, v)
+		delete(serverVersions.m, pc)
+	}
+	APILastSuccess.DeleteLabelValues(pc)
+	APIConsecutiveFailures.DeleteLabelValues(pc)
+	ServerThrottledResponses.DeleteLabelValues(pc)
+	ServerRateLimit.DeleteLabelValues(pc, "limit")
+	ServerRateLimit.DeleteLabelValues(pc, "remaining")
+}
+
+// SetWebhookDeliveries sets the delivery counts of the named Webhook.
+func SetWebhookDeliveries(name string, repo bitbucket.Repo, s bitbucket.WebhookStatistics) {
+	WebhookDeliveries.WithLabelValues(name, repo.ProjectKey, repo.Repo, OutcomeSuccess).Set(float64(s.Successes))
+	WebhookDeliveries.WithLabelValues(name, repo.ProjectKey, repo.Repo, OutcomeFailure).Set(float64(s.Failures))
+	WebhookDeliveries.WithLabelValues(name, repo.ProjectKey, repo.Repo, OutcomeError).Set(float64(s.Errors))
+}
+
+// DeleteWebhookDeliveries removes the delivery counts of the named Webhook.
+func DeleteWebhookDeliveries(name string, repo bitbucket.Repo) {
+	for _, o := range []string{OutcomeSuccess, OutcomeFailure, OutcomeError} {
+		WebhookDeliveries.DeleteLabelValues(name, repo.ProjectKey, repo.Repo, o)
+	}
+}
+
+// RecordDrift counts an observation of an external resource of the kind
+// which drifted in the supplied fields.
+func RecordDrift(kind string, fields []string) {
+	DriftDetected.WithLabelValues(kind, strings.Join(fields, ",")).Inc()
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := NewRateLimiter(&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(1, 2)})
+	if got := testutil.ToFloat64(ReconcileRateLimit.WithLabelValues("burst")); got != 2 {
+		t.Errorf("NewRateLimiter(...): want burst 2, got %v", got)
+	}
+
+	before := testutil.ToFloat64(ReconcilesThrottled)
+	for i := 0; i < 3; i++ {
+		l.When(i)
+	}
+	if got := testutil.ToFloat64(ReconcilesThrottled) - before; got != 1 {
+		t.Errorf("When(...): want 1 throttled requeue after the burst, got %v", got)
+	}
+	if got := testutil.ToFloat64(ReconcileRateLimitDelay); got <= 0 {
+		t.Errorf("When(...): want a delay after the burst, got %v", got)
+	}
+}
+
+func TestRecordResponse(t *testing.T) {
+	RecordResponse("example", bitbucket.ResponseMetadata{StatusCode: http.StatusOK, RateLimit: &bitbucket.RateLimit{Limit: 60, Remaining: 42}})
+	RecordResponse("example", bitbucket.ResponseMetadata{StatusCode: http.StatusTooManyRequests})
+
+	if got := testutil.ToFloat64(ServerRateLimit.WithLabelValues("example", "remaining")); got != 42 {
+		t.Errorf("RecordResponse(...): want 42 remaining tokens, got %v", got)
+	}
+	if got := testutil.ToFloat64(ServerThrottledResponses.WithLabelValues("example")); got != 1 {
+		t.Errorf("RecordResponse(...): want 1 throttled response, got %v", got)
+	}
+}
+
+func TestRecordResponseFailures(t *testing.T) {
+	RecordResponse("failing", bitbucket.ResponseMetadata{})
+	RecordResponse("failing", bitbucket.ResponseMetadata{StatusCode: http.StatusBadGateway})
+	if got := testutil.ToFloat64(APIConsecutiveFailures.WithLabelValues("failing")); got != 2 {
+		t.Errorf("RecordResponse(...): want 2 consecutive failures, got %v", got)
+	}
+
+	RecordResponse("failing", bitbucket.ResponseMetadata{StatusCode: http.StatusNotFound})
+	if got := testutil.ToFloat64(APIConsecutiveFailures.WithLabelValues("failing")); got != 0 {
+		t.Errorf("RecordResponse(...): want failures to be reset by a 404, got %v", got)
+	}
+	if got := testutil.ToFloat64(APILastSuccess.WithLabelValues("failing")); got <= 0 {
+		t.Errorf("RecordResponse(...): want the time of the last success, got %v", got)
+	}
+}
+
+func TestSetServerVersion(t *testing.T) {
+	SetServerVersion("upgraded", "7.21.0")
+	SetServerVersion("upgraded", "8.9.0")
+	if got := testutil.CollectAndCount(ServerInfo); got != 1 {
+		t.Errorf("SetServerVersion(...): want 1 version, got %v", got)
+	}
+
+	DeleteProviderConfig("upgraded")
+	if got := testutil.CollectAndCount(ServerInfo); got != 0 {
+		t.Errorf("DeleteProviderConfig(...): want no version, got %v", got)
+	}
+}
+
+func TestRecordDrift(t *testing.T) {
+	RecordDrift("Webhook", []string{"Events", "URL"})
+	RecordDrift("Webhook", []string{"Events", "URL"})
+	if got := testutil.ToFloat64(DriftDetected.WithLabelValues("Webhook", "Events,URL")); got != 2 {
+		t.Errorf("RecordDrift(...): want 2 drifts, got %v", got)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	accesskeyv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/accesskey/v1alpha1"
+	webhookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/externalname"
+)
+
+const (
+	errListAccessKeys  = "cannot list AccessKeys"
+	errListWebhooks    = "cannot list Webhooks"
+	errUpdateAccessKey = "cannot rewrite the external name of AccessKey %q"
+	errInvalidExtNames = "%d resources have invalid external names"
+)
+
+// MigrateExternalNames rewrites the external names of AccessKeys which are
+// bare IDs to PROJECT/repo/ID, and checks that the external names of all
+// Webhooks and AccessKeys identify their external resource. It returns an
+// error if any external name is invalid, after logging each of them.
+func (m *Migrator) MigrateExternalNames(ctx context.Context) error {
+	invalid, err := m.migrateAccessKeys(ctx)
+	if err != nil {
+		return err
+	}
+	n, err := m.checkWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	if invalid += n; invalid > 0 {
+		return errors.Errorf(errInvalidExtNames, invalid)
+	}
+	return nil
+}
+
+// migrateAccessKeys rewrites the bare IDs of AccessKeys and returns the
+// number of invalid external names.
+func (m *Migrator) migrateAccessKeys(ctx context.Context) (int, error) {
+	invalid, migrated := 0, 0
+	cont := ""
+	for {
+		l := &accesskeyv1alpha1.AccessKeyList{}
+		if err := m.client.List(ctx, l, client.Limit(pageSize), client.Continue(cont)); err != nil {
+			return invalid, errors.Wrap(err, errListAccessKeys)
+		}
+		for i := range l.Items {
+			cr := &l.Items[i]
+			name := meta.GetExternalName(cr)
+			id, ok, err := externalname.RepoID(name, cr.Spec.ForProvider.ProjectKey, cr.Spec.ForProvider.RepoName, cr.RepoSlug())
+			switch {
+			case !ok && err == nil && notCreated(cr):
+				continue
+			case !ok:
+				m.log.Info("Invalid external name", "kind", accesskeyv1alpha1.AccessKeyKind, "name", cr.GetName(), "externalName", name)
+				invalid++
+				continue
+			}
+
+			want := externalname.RepoName(cr.Repo().ProjectKey, cr.RepoSlug(), id)
+			if name == want {
+				continue
+			}
+			meta.SetExternalName(cr, want)
+			if err := resource.IgnoreNotFound(m.client.Update(ctx, cr)); err != nil {
+				return invalid, errors.Wrapf(err, errUpdateAccessKey, cr.GetName())
+			}
+			m.log.Debug("Rewrote external name", "kind", accesskeyv1alpha1.AccessKeyKind, "name", cr.GetName(), "from", name, "to", want)
+			migrated++
+		}
+		if cont = l.GetContinue(); cont == "" {
+			m.log.Info("Migrated external names", "kind", accesskeyv1alpha1.AccessKeyKind, "objects", migrated)
+			return invalid, nil
+		}
+	}
+}
+
+// checkWebhooks returns the number of Webhooks whose external name is not
+// an ID.
+func (m *Migrator) checkWebhooks(ctx context.Context) (int, error) {
+	invalid := 0
+	cont := ""
+	for {
+		l := &webhookv1alpha1.WebhookList{}
+		if err := m.client.List(ctx, l, client.Limit(pageSize), client.Continue(cont)); err != nil {
+			return invalid, errors.Wrap(err, errListWebhooks)
+		}
+		for i := range l.Items {
+			cr := &l.Items[i]
+			if _, err := externalname.ID(cr); err != nil && !notCreated(cr) {
+				m.log.Info("Invalid external name", "kind", webhookv1alpha1.WebhookKind, "name", cr.GetName(), "externalName", meta.GetExternalName(cr))
+				invalid++
+			}
+		}
+		if cont = l.GetContinue(); cont == "" {
+			return invalid, nil
+		}
+	}
+}
+
+// notCreated returns whether the external name is unset or the name of the
+// object, which the managed reconciler sets until the external resource is
+// created.
+func notCreated(o metav1.Object) bool {
+	name := meta.GetExternalName(o)
+	return name == "" || name == o.GetName()
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	accesskeyv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/accesskey/v1alpha1"
+	webhookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+)
+
+func accessKey(name, externalName string) accesskeyv1alpha1.AccessKey {
+	a := accesskeyv1alpha1.AccessKey{}
+	a.SetName(name)
+	a.Spec.ForProvider.ProjectKey = "PROJ"
+	a.Spec.ForProvider.RepoName = "My Repo"
+	meta.SetExternalName(&a, externalName)
+	return a
+}
+
+func typedWebhook(name, externalName string) webhookv1alpha1.Webhook {
+	w := webhookv1alpha1.Webhook{}
+	w.SetName(name)
+	meta.SetExternalName(&w, externalName)
+	return w
+}
+
+func TestMigrateExternalNames(t *testing.T) {
+	type want struct {
+		err     error
+		updated map[string]string
+	}
+
+	cases := map[string]struct {
+		keys  []accesskeyv1alpha1.AccessKey
+		hooks []webhookv1alpha1.Webhook
+		want  want
+	}{
+		"Migrated": {
+			keys: []accesskeyv1alpha1.AccessKey{
+				accessKey("legacy", "42"),
+				accessKey("migrated", "PROJ/my-repo/43"),
+				accessKey("by-name", "PROJ/My Repo/44"),
+				accessKey("new", "new"),
+			},
+			hooks: []webhookv1alpha1.Webhook{typedWebhook("hook", "7"), typedWebhook("new", "new")},
+			want: want{
+				updated: map[string]string{
+					"legacy":  "PROJ/my-repo/42",
+					"by-name": "PROJ/my-repo/44",
+				},
+			},
+		},
+		"Invalid": {
+			keys: []accesskeyv1alpha1.AccessKey{
+				accessKey("other-repo", "PROJ/other/42"),
+				accessKey("typo", "cool-key"),
+			},
+			hooks: []webhookv1alpha1.Webhook{typedWebhook("hook", "PROJ/my-repo/7")},
+			want: want{
+				err:     errors.Errorf(errInvalidExtNames, 3),
+				updated: map[string]string{},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			updated := map[string]string{}
+			c := &test.MockClient{
+				MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+					switch l := obj.(type) {
+					case *accesskeyv1alpha1.AccessKeyList:
+						l.Items = tc.keys
+					case *webhookv1alpha1.WebhookList:
+						l.Items = tc.hooks
+					}
+					return nil
+				},
+				MockUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+					updated[obj.GetName()] = meta.GetExternalName(obj)
+					return nil
+				},
+			}
+
+			err := NewMigrator(c, logging.NewNopLogger()).MigrateExternalNames(context.Background())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("MigrateExternalNames(...): -want, +got\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.updated, updated); diff != "" {
+				t.Errorf("MigrateExternalNames(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
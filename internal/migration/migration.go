@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package migration rewrites the stored objects of the custom resources of
+// the provider in their current storage version, so that versions which are
+// no longer served can be removed from their CRDs.
+package migration
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// Group is the suffix of the API groups of the provider.
+const Group = "bitbucket-server.crossplane.io"
+
+const (
+	// pageSize is the number of objects listed at once.
+	pageSize = 100
+
+	errListCRDs             = "cannot list CustomResourceDefinitions"
+	errGetCRD               = "cannot get CustomResourceDefinition %q"
+	errNoStorageVersion     = "CustomResourceDefinition %q has no storage version"
+	errListObjects          = "cannot list %s"
+	errMigrateObject        = "cannot rewrite %s %q"
+	errUpdateStoredVersions = "cannot update the stored versions of CustomResourceDefinition %q"
+)
+
+var crdKind = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+// A Migrator rewrites stored objects in the storage version of their CRD.
+type Migrator struct {
+	client client.Client
+	log    logging.Logger
+}
+
+// NewMigrator returns a Migrator using the supplied client.
+func NewMigrator(c client.Client, l logging.Logger) *Migrator {
+	return &Migrator{client: c, log: l}
+}
+
+// MigrateAll migrates the CRDs of all API groups of the provider.
+func (m *Migrator) MigrateAll(ctx context.Context) error {
+	l := &unstructured.UnstructuredList{}
+	l.SetGroupVersionKind(crdKind.GroupVersion().WithKind(crdKind.Kind + "List"))
+	if err := m.client.List(ctx, l); err != nil {
+		return errors.Wrap(err, errListCRDs)
+	}
+	for i := range l.Items {
+		group, _, _ := unstructured.NestedString(l.Items[i].Object, "spec", "group")
+		if group != Group && !strings.HasSuffix(group, "."+Group) {
+			continue
+		}
+		if err := m.Migrate(ctx, l.Items[i].GetName()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Migrate rewrites all objects of the named CRD in its storage version and
+// then records the storage version as the only stored version of the CRD.
+func (m *Migrator) Migrate(ctx context.Context, name string) error {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdKind)
+	if err := m.client.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+		return errors.Wrapf(err, errGetCRD, name)
+	}
+
+	storage, ok := storageVersion(crd)
+	if !ok {
+		return errors.Errorf(errNoStorageVersion, name)
+	}
+	stored, _, _ := unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+	if len(stored) == 1 && stored[0] == storage {
+		m.log.Debug("Nothing to migrate", "crd", name, "version", storage)
+		return nil
+	}
+
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	listKind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "listKind")
+	if listKind == "" {
+		listKind = kind + "List"
+	}
+	gv := schema.GroupVersion{Group: group, Version: storage}
+
+	n, err := m.rewrite(ctx, gv.WithKind(kind), gv.WithKind(listKind))
+	if err != nil {
+		return err
+	}
+
+	if err := unstructured.SetNestedStringSlice(crd.Object, []string{storage}, "status", "storedVersions"); err != nil {
+		return errors.Wrapf(err, errUpdateStoredVersions, name)
+	}
+	if err := m.client.Status().Update(ctx, crd); err != nil {
+		return errors.Wrapf(err, errUpdateStoredVersions, name)
+	}
+	m.log.Info("Migrated stored objects", "crd", name, "version", storage, "from", stored, "objects", n)
+	return nil
+}
+
+// rewrite updates every object of the kind without changes, which makes the
+// API server store it in the storage version.
+func (m *Migrator) rewrite(ctx context.Context, kind, listKind schema.GroupVersionKind) (int, error) {
+	n := 0
+	cont := ""
+	for {
+		l := &unstructured.UnstructuredList{}
+		l.SetGroupVersionKind(listKind)
+		if err := m.client.List(ctx, l, client.Limit(pageSize), client.Continue(cont)); err != nil {
+			return n, errors.Wrapf(err, errListObjects, listKind.Kind)
+		}
+		for i := range l.Items {
+			o := &l.Items[i]
+			o.SetGroupVersionKind(kind)
+			if err := m.rewriteObject(ctx, o); err != nil {
+				return n, errors.Wrapf(err, errMigrateObject, kind.Kind, o.GetName())
+			}
+			n++
+		}
+		if cont = l.GetContinue(); cont == "" {
+			return n, nil
+		}
+	}
+}
+
+func (m *Migrator) rewriteObject(ctx context.Context, o *unstructured.Unstructured) error {
+	err := m.client.Update(ctx, o)
+	if !kerrors.IsConflict(err) {
+		return resource.IgnoreNotFound(err)
+	}
+	// The object changed since it was listed, so it is re-read and written
+	// again until it is stored without a conflict.
+	return resource.IgnoreNotFound(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := m.client.Get(ctx, client.ObjectKeyFromObject(o), o); err != nil {
+			return err
+		}
+		return m.client.Update(ctx, o)
+	}))
+}
+
+// storageVersion returns the version of the CRD objects are stored in.
+func storageVersion(crd *unstructured.Unstructured) (string, bool) {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range versions {
+		v, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if storage, _ := v["storage"].(bool); storage {
+			name, ok := v["name"].(string)
+			return name, ok
+		}
+	}
+	return "", false
+}
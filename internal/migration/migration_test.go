@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+const crdName = "webhooks.webhook.bitbucket-server.crossplane.io"
+
+func crd(stored ...interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"group": "webhook.bitbucket-server.crossplane.io",
+			"names": map[string]interface{}{"kind": "Webhook", "listKind": "WebhookList"},
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1alpha1", "storage": false},
+				map[string]interface{}{"name": "v1beta1", "storage": true},
+			},
+		},
+		"status": map[string]interface{}{"storedVersions": stored},
+	}
+}
+
+func webhook(name string) unstructured.Unstructured {
+	u := unstructured.Unstructured{}
+	u.SetName(name)
+	return u
+}
+
+func TestMigrate(t *testing.T) {
+	errBoom := errors.New("boom")
+	conflict := kerrors.NewConflict(schema.GroupResource{}, "b", errBoom)
+
+	type want struct {
+		err       error
+		updated   []string
+		crdStored []string
+	}
+
+	cases := map[string]struct {
+		crd   map[string]interface{}
+		pages [][]unstructured.Unstructured
+		// conflicts is the number of conflicts of the first update
+		conflicts int
+		want      want
+	}{
+		"AlreadyMigrated": {
+			crd:  crd("v1beta1"),
+			want: want{},
+		},
+		"Migrated": {
+			crd:   crd("v1alpha1", "v1beta1"),
+			pages: [][]unstructured.Unstructured{{webhook("a"), webhook("b")}, {webhook("c")}},
+			want: want{
+				updated:   []string{"a", "b", "c"},
+				crdStored: []string{"v1beta1"},
+			},
+		},
+		"Conflict": {
+			crd:       crd("v1alpha1", "v1beta1"),
+			pages:     [][]unstructured.Unstructured{{webhook("a")}},
+			conflicts: 1,
+			want: want{
+				updated:   []string{"a", "a"},
+				crdStored: []string{"v1beta1"},
+			},
+		},
+		"NoStorageVersion": {
+			crd: map[string]interface{}{"spec": map[string]interface{}{}},
+			want: want{
+				err: errors.Errorf(errNoStorageVersion, crdName),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var updated, stored []string
+			conflicts := tc.conflicts
+			c := &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					if u, ok := obj.(*unstructured.Unstructured); ok && u.GroupVersionKind() == crdKind {
+						u.Object = tc.crd
+						u.SetGroupVersionKind(crdKind)
+					}
+					return nil
+				},
+				MockList: func(_ context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+					lo := &client.ListOptions{}
+					lo.ApplyOptions(opts)
+					page := 0
+					if lo.Continue != "" {
+						page = 1
+					}
+					l := obj.(*unstructured.UnstructuredList)
+					if l.GroupVersionKind() != (schema.GroupVersionKind{Group: "webhook.bitbucket-server.crossplane.io", Version: "v1beta1", Kind: "WebhookList"}) {
+						t.Errorf("List(...): unexpected kind %v", l.GroupVersionKind())
+					}
+					l.Items = tc.pages[page]
+					if page+1 < len(tc.pages) {
+						l.SetContinue("next")
+					}
+					return nil
+				},
+				MockUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+					updated = append(updated, obj.GetName())
+					if conflicts > 0 {
+						conflicts--
+						return conflict
+					}
+					return nil
+				},
+				MockStatusUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+					stored, _, _ = unstructured.NestedStringSlice(obj.(*unstructured.Unstructured).Object, "status", "storedVersions")
+					return nil
+				},
+			}
+
+			err := NewMigrator(c, logging.NewNopLogger()).Migrate(context.Background(), crdName)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Migrate(...): -want, +got\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.updated, updated); diff != "" {
+				t.Errorf("Migrate(...): -want, +got\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.crdStored, stored); diff != "" {
+				t.Errorf("Migrate(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
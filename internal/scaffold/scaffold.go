@@ -0,0 +1,190 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package scaffold generates the API types, client, fake, REST client,
+// controller and tests of a new kind of managed resources, following the
+// patterns of the existing kinds. The generated code is a starting point:
+// the fields of the kind and the API paths are left to fill in.
+package scaffold
+
+import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	errKind   = "kind %q must be in PascalCase, e.g. BranchRestriction"
+	errGroup  = "group %q must be lower case letters, e.g. branchrestriction"
+	errExists = "%s exists already"
+	errRender = "cannot render template %s"
+	errFormat = "cannot format %s"
+	errWrite  = "cannot write %s"
+)
+
+var (
+	validKind  = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	validGroup = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+)
+
+// A Kind of managed resources to scaffold
+type Kind struct {
+	// Name of the kind in PascalCase, e.g. BranchRestriction
+	Name string
+	// Group of the kind, e.g. branchrestriction for the API group
+	// branchrestriction.bitbucket-server.crossplane.io. Defaults to the
+	// name in lower case.
+	Group string
+}
+
+// data of the templates
+type data struct {
+	Kind
+	// Var is the name in camelCase, e.g. branchRestriction
+	Var string
+	// Words is the name in words, e.g. branch restriction
+	Words string
+}
+
+func newData(k Kind) (data, error) {
+	if !validKind.MatchString(k.Name) {
+		return data{}, errors.Errorf(errKind, k.Name)
+	}
+	if k.Group == "" {
+		k.Group = strings.ToLower(k.Name)
+	}
+	if !validGroup.MatchString(k.Group) {
+		return data{}, errors.Errorf(errGroup, k.Group)
+	}
+	return data{Kind: k, Var: strings.ToLower(k.Name[:1]) + k.Name[1:], Words: words(k.Name)}, nil
+}
+
+// words splits the PascalCase name into lower case words.
+func words(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// DefaultTemplates is the directory of the templates relative to the root of
+// the repository
+const DefaultTemplates = "internal/scaffold/templates"
+
+// files maps the templates to the paths of the files they generate, which
+// are templates themselves
+var files = []struct {
+	template string
+	path     string
+}{
+	{template: "doc.go.tmpl", path: "apis/{{ .Group }}/v1alpha1/doc.go"},
+	{template: "register.go.tmpl", path: "apis/{{ .Group }}/v1alpha1/register.go"},
+	{template: "types.go.tmpl", path: "apis/{{ .Group }}/v1alpha1/types.go"},
+	{template: "validation.go.tmpl", path: "apis/{{ .Group }}/v1alpha1/validation.go"},
+	{template: "client.go.tmpl", path: "pkg/clients/bitbucket/{{ .Group }}.go"},
+	{template: "fake.go.tmpl", path: "pkg/clients/bitbucket/fake/{{ .Group }}.go"},
+	{template: "rest.go.tmpl", path: "pkg/clients/rest/{{ .Group }}.go"},
+	{template: "controller.go.tmpl", path: "internal/controller/{{ .Group }}/{{ .Group }}.go"},
+	{template: "controller_test.go.tmpl", path: "internal/controller/{{ .Group }}/{{ .Group }}_test.go"},
+	{template: "example.yaml.tmpl", path: "examples/{{ .Group }}/{{ .Group }}.yaml"},
+}
+
+// Generate renders the templates in the templates directory for the kind,
+// writes the files below the root of the repository and returns their
+// paths. It writes nothing if any of the files exists.
+func Generate(templates, root string, k Kind) ([]string, error) {
+	d, err := newData(k)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := make(map[string][]byte, len(files))
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		p, err := render(f.path, f.path, d)
+		if err != nil {
+			return nil, err
+		}
+		path := string(p)
+		text, err := ioutil.ReadFile(filepath.Join(templates, f.template))
+		if err != nil {
+			return nil, errors.Wrapf(err, errRender, f.template)
+		}
+		content, err := render(f.template, string(text), d)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(path, ".go") {
+			if content, err = format.Source(content); err != nil {
+				return nil, errors.Wrapf(err, errFormat, path)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(root, path)); err == nil {
+			return nil, errors.Errorf(errExists, path)
+		}
+		rendered[path] = content
+		paths = append(paths, path)
+	}
+
+	for _, p := range paths {
+		full := filepath.Join(root, p)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			return nil, errors.Wrapf(err, errWrite, p)
+		}
+		if err := ioutil.WriteFile(full, rendered[p], 0o644); err != nil { // nolint:gosec
+			return nil, errors.Wrapf(err, errWrite, p)
+		}
+	}
+	return paths, nil
+}
+
+func render(name, text string, d data) ([]byte, error) {
+	t, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, errors.Wrapf(err, errRender, name)
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, d); err != nil {
+		return nil, errors.Wrapf(err, errRender, name)
+	}
+	return b.Bytes(), nil
+}
+
+// NextSteps returns the manual steps to finish the kind after Generate.
+func NextSteps(templates string, k Kind) (string, error) {
+	d, err := newData(k)
+	if err != nil {
+		return "", err
+	}
+	text, err := ioutil.ReadFile(filepath.Join(templates, "next-steps.txt.tmpl"))
+	if err != nil {
+		return "", errors.Wrapf(err, errRender, "next-steps.txt.tmpl")
+	}
+	b, err := render("next-steps.txt.tmpl", string(text), d)
+	return string(b), err
+}
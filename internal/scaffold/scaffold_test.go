@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestGenerate(t *testing.T) {
+	type want struct {
+		paths []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		kind     Kind
+		existing string
+		want     want
+	}{
+		"Generated": {
+			kind: Kind{Name: "BranchRestriction"},
+			want: want{paths: []string{
+				"apis/branchrestriction/v1alpha1/doc.go",
+				"apis/branchrestriction/v1alpha1/register.go",
+				"apis/branchrestriction/v1alpha1/types.go",
+				"apis/branchrestriction/v1alpha1/validation.go",
+				"pkg/clients/bitbucket/branchrestriction.go",
+				"pkg/clients/bitbucket/fake/branchrestriction.go",
+				"pkg/clients/rest/branchrestriction.go",
+				"internal/controller/branchrestriction/branchrestriction.go",
+				"internal/controller/branchrestriction/branchrestriction_test.go",
+				"examples/branchrestriction/branchrestriction.yaml",
+			}},
+		},
+		"Group": {
+			kind: Kind{Name: "DefaultReviewer", Group: "reviewer"},
+			want: want{paths: []string{
+				"apis/reviewer/v1alpha1/doc.go",
+				"apis/reviewer/v1alpha1/register.go",
+				"apis/reviewer/v1alpha1/types.go",
+				"apis/reviewer/v1alpha1/validation.go",
+				"pkg/clients/bitbucket/reviewer.go",
+				"pkg/clients/bitbucket/fake/reviewer.go",
+				"pkg/clients/rest/reviewer.go",
+				"internal/controller/reviewer/reviewer.go",
+				"internal/controller/reviewer/reviewer_test.go",
+				"examples/reviewer/reviewer.yaml",
+			}},
+		},
+		"Exists": {
+			kind:     Kind{Name: "BranchRestriction"},
+			existing: "pkg/clients/rest/branchrestriction.go",
+			want:     want{err: errors.Errorf(errExists, "pkg/clients/rest/branchrestriction.go")},
+		},
+		"InvalidKind": {
+			kind: Kind{Name: "branch_restriction"},
+			want: want{err: errors.Errorf(errKind, "branch_restriction")},
+		},
+		"InvalidGroup": {
+			kind: Kind{Name: "BranchRestriction", Group: "branch-restriction"},
+			want: want{err: errors.Errorf(errGroup, "branch-restriction")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			if tc.existing != "" {
+				p := filepath.Join(root, tc.existing)
+				if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(p, nil, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			paths, err := Generate("templates", root, tc.kind)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("Generate(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.paths, paths); diff != "" {
+				t.Errorf("Generate(...): -want, +got:\n%s", diff)
+			}
+			for _, p := range paths {
+				if !strings.HasSuffix(p, ".go") {
+					continue
+				}
+				if _, err := parser.ParseFile(token.NewFileSet(), filepath.Join(root, p), nil, parser.AllErrors); err != nil {
+					t.Errorf("Generate(...): invalid Go in %s: %v", p, err)
+				}
+			}
+		})
+	}
+}
+
+func TestNextSteps(t *testing.T) {
+	steps, err := NextSteps("templates", Kind{Name: "BranchRestriction"})
+	if err != nil {
+		t.Fatalf("NextSteps(...): %v", err)
+	}
+	for _, want := range []string{"func NewBranchRestrictionClient(c Config) bitbucket.BranchRestrictionClientAPI", "branchrestriction.Setup"} {
+		if !strings.Contains(steps, want) {
+			t.Errorf("NextSteps(...): %q does not contain %q", steps, want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clients creates Bitbucket Server clients from a provider
+// configuration.
+package clients
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/rest"
+)
+
+// Config provides configuration for the bitbucket client
+type Config struct {
+	Token     string
+	BaseURL   string
+	TLSConfig *tls.Config
+	// Timeout of a single request, zero means no timeout
+	Timeout time.Duration
+	// Auth sends the credentials, the Token is sent as bearer token if nil
+	Auth rest.Authenticator
+	// PageConcurrency is the number of pages of a list requested at once,
+	// one after another if less than 2
+	PageConcurrency int
+	// HTTPLogger logs all requests and responses with secrets redacted if
+	// not nil
+	HTTPLogger logging.Logger
+	// Logger receives the structured logs of the client. Nothing is logged
+	// if nil.
+	Logger logging.Logger
+	// WarnUnknownFields logs the fields of responses the client doesn't
+	// know, which hint at a change of the API
+	WarnUnknownFields bool
+}
+
+// NewClient creates new Bitbucket Client with provided base URL and credentials
+func NewClient(c Config) *rest.Client {
+	c.BaseURL = rest.NormalizeBaseURL(c.BaseURL)
+	httpClient := http.Client{
+		Transport: rest.NewCompressionTransport(rest.NewCircuitBreakerTransport(&http.Transport{
+			TLSClientConfig: c.TLSConfig,
+		}, rest.CircuitBreakerFor(c.BaseURL))),
+	}
+	if c.HTTPLogger != nil {
+		httpClient.Transport = rest.NewDebugTransport(httpClient.Transport, c.HTTPLogger)
+	}
+	client := &rest.Client{
+		Token:           c.Token,
+		BaseURL:         c.BaseURL,
+		HTTPClient:      &httpClient,
+		Timeout:         c.Timeout,
+		Auth:            c.Auth,
+		PageConcurrency: c.PageConcurrency,
+		Log:             c.Logger,
+	}
+	if c.WarnUnknownFields {
+		client.UnknownFields = rest.WarnUnknownFields
+	}
+	return client
+}
+
+// NewWebhookClient creates a new client for the webhook api
+func NewWebhookClient(c Config) bitbucket.WebhookClientAPI {
+	return NewClient(c)
+}
+
+// NewAccessKeyClient creates a new client for the access key api
+func NewAccessKeyClient(c Config) bitbucket.KeyClientAPI {
+	return NewClient(c)
+}
+
+// NewRepositoryClient creates a new client for the repository api
+func NewRepositoryClient(c Config) bitbucket.RepositoryClientAPI {
+	return NewClient(c)
+}
+
+// NewPermissionClient creates a new client for checking the permissions of
+// the credentials
+func NewPermissionClient(c Config) bitbucket.PermissionClientAPI {
+	return NewClient(c)
+}
+
+// NewMergeConfigClient creates a new client for the merge strategies of pull
+// requests
+func NewMergeConfigClient(c Config) bitbucket.MergeConfigClientAPI {
+	return NewClient(c)
+}
+
+// NewDefaultPermissionClient creates a new client for the default permissions
+// of projects
+func NewDefaultPermissionClient(c Config) bitbucket.DefaultPermissionClientAPI {
+	return NewClient(c)
+}
+
+// NewLicenseClient creates a new client for the license of the server
+func NewLicenseClient(c Config) bitbucket.LicenseClientAPI {
+	return NewClient(c)
+}
+
+// NewProjectHookClient creates a new client for the repository hooks of
+// projects
+func NewProjectHookClient(c Config) bitbucket.ProjectHookClientAPI {
+	return NewClient(c)
+}
+
+// NewServerInfoClient creates a new client for the version of the server
+func NewServerInfoClient(c Config) bitbucket.ServerInfoClientAPI {
+	return NewClient(c)
+}
+
+// NewLoggerClient creates a new client for the levels of the loggers of the
+// server
+func NewLoggerClient(c Config) bitbucket.LoggerClientAPI {
+	return NewClient(c)
+}
+
+// NewFileClient creates a new client for the repository file api
+func NewFileClient(c Config) bitbucket.FileClientAPI {
+	return NewClient(c)
+}
+
+// NewInventoryClient creates a new client for listing projects and repositories
+func NewInventoryClient(c Config) bitbucket.InventoryClientAPI {
+	return NewClient(c)
+}
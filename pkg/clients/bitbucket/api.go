@@ -0,0 +1,467 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bitbucket defines the Bitbucket Server API used by the provider's
+// controllers. It is public, so other tools can build on the same client
+// instead of rolling their own. Implementations are in package rest, fakes
+// for tests in package fake.
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Repo struct
+type Repo struct {
+	ProjectKey string
+	// Repo is the slug of the repository, which is used in URLs
+	Repo string
+}
+
+var notSlug = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// RepoSlug returns the slug Bitbucket derives from the name of a repository
+// when it is created, e.g. my-repo for "My Repo". Bitbucket keeps the slug
+// when a repository is renamed, so it may differ for renamed repositories.
+func RepoSlug(name string) string {
+	return notSlug.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// ProjectKey returns the key of a project as Bitbucket stores it, e.g. PRJ
+// for prj. Bitbucket matches project keys case-insensitively, but returns them
+// in upper case.
+func ProjectKey(key string) string {
+	return strings.ToUpper(key)
+}
+
+// AccessKeyFilter selects access keys on the server when listing them. The
+// zero value selects all access keys.
+type AccessKeyFilter struct {
+	// Text which the labels or public keys of the access keys contain
+	Text string
+	// Permission of the access keys, e.g. REPO_READ
+	Permission string
+}
+
+// KeyClientAPI is the API for creating/listing/deleting/getting access keys
+type KeyClientAPI interface {
+	CreateAccessKey(ctx context.Context, repo Repo, key AccessKey) (result AccessKey, err error)
+	DeleteAccessKey(ctx context.Context, repo Repo, id int) (err error)
+	GetAccessKey(ctx context.Context, repo Repo, id int) (result AccessKey, err error)
+	ListAccessKeys(ctx context.Context, repo Repo, filter AccessKeyFilter) (result []AccessKey, err error)
+	UpdateAccessKeyPermission(ctx context.Context, repo Repo, id int, permission string) (err error)
+}
+
+// ErrNotFound returned when item is not found
+var ErrNotFound = errors.New("not found")
+
+// ErrConflict returned when the server rejected a change because the entity
+// was modified concurrently, e.g. an outdated version was sent
+var ErrConflict = errors.New("conflict")
+
+// ErrUnauthorized returned when the server rejected the credentials
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrForbidden returned when the credentials lack the permission for a request
+var ErrForbidden = errors.New("forbidden")
+
+// ErrRateLimited returned when the server rejected a request because too many
+// requests were sent
+var ErrRateLimited = errors.New("rate limited")
+
+// ErrServer returned when the server failed to handle a request
+var ErrServer = errors.New("server error")
+
+// ErrUnreachable returned when no response was received from the server,
+// e.g. because it is down or cannot be resolved
+var ErrUnreachable = errors.New("unreachable")
+
+// ErrCircuitOpen returned when requests are not sent because the server has
+// failed repeatedly and the circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open: bitbucket server is failing, not sending request")
+
+const (
+	// PermissionRepoWrite grants read write permissions to the repository
+	PermissionRepoWrite = "REPO_WRITE"
+	// PermissionRepoRead grants read only permissions to the repository
+	PermissionRepoRead = "REPO_READ"
+	// PermissionProjectWrite grants read write permissions to the
+	// repositories of a project
+	PermissionProjectWrite = "PROJECT_WRITE"
+	// PermissionProjectRead grants read only permissions to the
+	// repositories of a project
+	PermissionProjectRead = "PROJECT_READ"
+)
+
+// AccessKey defines the api object for bitbucket server
+type AccessKey struct {
+	// Key is the public ssh key
+	Key string
+	// Label is the text description of the key
+	Label string
+	// ID is the number the access key is given by server
+	ID int
+	// Permission is either PermissionRepoRead or PermissionRepoWrite
+	Permission string
+	// Repository the access key grants access to. It is only set by the
+	// server.
+	Repository *Repository
+}
+
+// Webhook defines the api object for the bitbucket server objet webhook
+type Webhook struct {
+	// ID of the webhook in the server
+	ID int `json:"id"`
+
+	// Name of the webhook
+	Name string `json:"name"`
+
+	// Configuration contains webhook configurations
+	Configuration WebhookConfiguration `json:"configuration"`
+
+	// Events defines for which events the webhook subscribes
+	Events []string `json:"events"`
+
+	// URL is the enpoint that bitbucket server should POST events to
+	URL string `json:"url"`
+
+	// Active is whether the webhook sends events. It is only set by the
+	// server.
+	Active *bool `json:"active,omitempty"`
+
+	// CreatedDate is when the webhook was created in milliseconds since the
+	// epoch. It is only set by the server.
+	CreatedDate int64 `json:"createdDate,omitempty"`
+
+	// UpdatedDate is when the webhook was last updated in milliseconds
+	// since the epoch. It is only set by the server.
+	UpdatedDate int64 `json:"updatedDate,omitempty"`
+}
+
+// WebhookConfiguration is the configuration object of a webhook
+type WebhookConfiguration struct {
+	// Secret defines the authentication key that the bitbucket server HMAC signes the payload
+	Secret string
+	// Extra are the other options of the configuration, which are sent and
+	// received verbatim next to the secret
+	Extra map[string]string
+}
+
+// MarshalJSON sends the extra options next to the secret, which takes
+// precedence over an extra option of the same name.
+func (c WebhookConfiguration) MarshalJSON() ([]byte, error) {
+	m := make(map[string]string, len(c.Extra)+1)
+	for k, v := range c.Extra {
+		m[k] = v
+	}
+	m["secret"] = c.Secret
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON keeps all options but the secret as extra options. Options
+// which are no strings are kept as their JSON.
+func (c *WebhookConfiguration) UnmarshalJSON(data []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*c = WebhookConfiguration{}
+	for k, raw := range m {
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			v = string(raw)
+		}
+		if k == "secret" {
+			c.Secret = v
+			continue
+		}
+		if c.Extra == nil {
+			c.Extra = map[string]string{}
+		}
+		c.Extra[k] = v
+	}
+	return nil
+}
+
+// WebhookFilter selects webhooks on the server when listing them. The zero
+// value selects all webhooks.
+type WebhookFilter struct {
+	// Event the webhooks subscribe to, e.g. repo:refs_changed
+	Event string
+}
+
+// WebhookStatistics counts the recent deliveries of a webhook by outcome
+type WebhookStatistics struct {
+	// Successes are deliveries answered with a success status
+	Successes int `json:"successes"`
+	// Failures are deliveries answered with an error status
+	Failures int `json:"failures"`
+	// Errors are deliveries which got no response, e.g. because of timeouts
+	Errors int `json:"errors"`
+}
+
+// Repository describes a git repository
+type Repository struct {
+	// ID of the repository in the server
+	ID int
+	// Slug of the repository, which is used in URLs
+	Slug string
+	// Name of the repository
+	Name string
+	// ProjectKey of the project of the repository
+	ProjectKey string
+	// CloneURLs are the URLs to clone the repository by protocol, e.g. http
+	// or ssh
+	CloneURLs map[string]string
+}
+
+// RepositoryClientAPI is the API for getting and listing repositories
+type RepositoryClientAPI interface {
+	GetRepository(ctx context.Context, repo Repo) (result Repository, err error)
+	ListRepositories(ctx context.Context, projectKey string) (result []Repository, err error)
+}
+
+// PermissionClientAPI is the API for checking the permissions of the
+// credentials before changing a repository
+type PermissionClientAPI interface {
+	// CheckRepositoryAdmin returns ErrForbidden if the credentials lack the
+	// admin permission on the repository, which webhooks and access keys
+	// require.
+	CheckRepositoryAdmin(ctx context.Context, repo Repo) (err error)
+}
+
+// Scopes of merge configurations
+const (
+	// MergeConfigRepository is a configuration set for a repository
+	MergeConfigRepository = "REPOSITORY"
+	// MergeConfigProject is a configuration set for a project, which its
+	// repositories inherit
+	MergeConfigProject = "PROJECT"
+	// MergeConfigDefault is the configuration of the server, which
+	// projects and repositories without their own inherit
+	MergeConfigDefault = "DEFAULT"
+)
+
+// MergeConfig are the strategies the pull requests of a project or repository
+// may be merged with
+type MergeConfig struct {
+	// Strategies which are enabled by ID, e.g. squash
+	Strategies []string
+	// DefaultStrategy is the ID of the strategy selected when merging
+	DefaultStrategy string
+	// Type is the scope the configuration is set at, e.g.
+	// MergeConfigProject for a repository inheriting the configuration of
+	// its project. It is only set by the server.
+	Type string
+}
+
+// MergeConfigClientAPI is the API for getting and setting the merge
+// strategies of pull requests. A Repo with an empty slug selects the
+// strategies of the project.
+type MergeConfigClientAPI interface {
+	GetMergeConfig(ctx context.Context, repo Repo) (result MergeConfig, err error)
+	SetMergeConfig(ctx context.Context, repo Repo, config MergeConfig) (result MergeConfig, err error)
+	// DeleteMergeConfig removes the strategies set for the project or
+	// repository, so that it inherits them again.
+	DeleteMergeConfig(ctx context.Context, repo Repo) (err error)
+}
+
+// DefaultPermissionClientAPI is the API for the permissions all licensed
+// users have on a project, e.g. PermissionProjectRead
+type DefaultPermissionClientAPI interface {
+	GetDefaultPermission(ctx context.Context, projectKey string, permission string) (permitted bool, err error)
+	SetDefaultPermission(ctx context.Context, projectKey string, permission string, allow bool) (err error)
+}
+
+// License is the license of the server
+type License struct {
+	// MaximumUsers is the number of users the license allows, zero if
+	// UnlimitedUsers
+	MaximumUsers int
+	// UnlimitedUsers is true if the license allows any number of users
+	UnlimitedUsers bool
+	// CurrentUsers is the number of licensed users
+	CurrentUsers int
+	// ExpiryDate is when the license expires, zero if never
+	ExpiryDate time.Time
+	// MaintenanceExpiryDate is when the maintenance of the license
+	// expires, zero if never
+	MaintenanceExpiryDate time.Time
+}
+
+// LicenseClientAPI is the API for the license of the server, which requires
+// the admin permission
+type LicenseClientAPI interface {
+	GetLicense(ctx context.Context) (result License, err error)
+}
+
+// Types of repository hooks
+const (
+	HookTypePreReceive  = "PRE_RECEIVE"
+	HookTypePostReceive = "POST_RECEIVE"
+)
+
+// ProjectHook is a repository hook at the scope of a project, which the
+// repositories of the project inherit unless they configure it themselves
+type ProjectHook struct {
+	// Key of the hook, e.g.
+	// com.atlassian.bitbucket.server.bitbucket-bundled-hooks:force-push-hook
+	Key string
+	// Name of the hook as shown in the project settings
+	Name string
+	// Type of the hook, e.g. HookTypePreReceive
+	Type string
+	// Version of the plugin of the hook
+	Version string
+	// Enabled is true if the hook is enabled for the project
+	Enabled bool
+	// Configured is true if the hook has settings for the project
+	Configured bool
+}
+
+// HookSettings are the settings of a hook. Their fields depend on the hook.
+type HookSettings map[string]interface{}
+
+// ProjectHookClientAPI is the API for the repository hooks of projects,
+// which requires the admin permission on the project
+type ProjectHookClientAPI interface {
+	GetProjectHook(ctx context.Context, projectKey string, hookKey string) (result ProjectHook, err error)
+	// EnableProjectHook enables the hook, with the settings unless they are
+	// nil
+	EnableProjectHook(ctx context.Context, projectKey string, hookKey string, settings HookSettings) (result ProjectHook, err error)
+	DisableProjectHook(ctx context.Context, projectKey string, hookKey string) (err error)
+	// GetProjectHookSettings returns the settings of the hook, nil if it
+	// has none
+	GetProjectHookSettings(ctx context.Context, projectKey string, hookKey string) (result HookSettings, err error)
+	SetProjectHookSettings(ctx context.Context, projectKey string, hookKey string, settings HookSettings) (err error)
+}
+
+// ServerInfo describes the server
+type ServerInfo struct {
+	// Version of the server, e.g. 7.21.0
+	Version string
+	// BuildNumber of the version, e.g. 7021000
+	BuildNumber string
+	// DisplayName of the product, e.g. Bitbucket
+	DisplayName string
+}
+
+// ServerInfoClientAPI is the API for the version of the server, which any
+// user may read
+type ServerInfoClientAPI interface {
+	GetServerInfo(ctx context.Context) (result ServerInfo, err error)
+}
+
+// LoggerClientAPI is the API for the levels of the loggers of the server,
+// e.g. DEBUG, which requires the system admin permission
+type LoggerClientAPI interface {
+	GetLoggerLevel(ctx context.Context, name string) (level string, err error)
+	SetLoggerLevel(ctx context.Context, name string, level string) (err error)
+}
+
+// Project groups repositories
+type Project struct {
+	// Key of the project, e.g. PRJ
+	Key string
+	// Name of the project
+	Name string
+}
+
+// ProjectFilter selects projects on the server when listing them. The zero
+// value selects all projects.
+type ProjectFilter struct {
+	// Name which the names of the projects contain
+	Name string
+	// Permission the credentials have on the projects, e.g. PROJECT_ADMIN
+	Permission string
+}
+
+// ProjectClientAPI is the API for listing projects
+type ProjectClientAPI interface {
+	ListProjects(ctx context.Context, filter ProjectFilter) (result []Project, err error)
+}
+
+// InventoryClientAPI is the API for listing the projects and repositories of a
+// server and the webhooks and access keys of the repositories
+type InventoryClientAPI interface {
+	ProjectClientAPI
+	RepositoryClientAPI
+
+	ListAccessKeys(ctx context.Context, repo Repo, filter AccessKeyFilter) (result []AccessKey, err error)
+	ListWebhooks(ctx context.Context, repo Repo, filter WebhookFilter) (result []Webhook, err error)
+}
+
+// WebhookClientAPI is the API for creating/listing/deleting/getting webhooks
+type WebhookClientAPI interface {
+	RepositoryClientAPI
+
+	CreateWebhook(ctx context.Context, repo Repo, webhook Webhook) (result Webhook, err error)
+	DeleteWebhook(ctx context.Context, repo Repo, id int) (err error)
+	GetWebhook(ctx context.Context, repo Repo, id int) (result Webhook, err error)
+	GetWebhookStatistics(ctx context.Context, repo Repo, id int) (result WebhookStatistics, err error)
+	ListWebhooks(ctx context.Context, repo Repo, filter WebhookFilter) (result []Webhook, err error)
+	UpdateWebhook(ctx context.Context, repo Repo, id int, webhook Webhook) (result Webhook, err error)
+}
+
+// File describes a file or directory in a repository
+type File struct {
+	// Path of the file relative to the repository root
+	Path string
+	// Type is FILE, DIRECTORY or SUBMODULE
+	Type string
+	// Size of the file in bytes
+	Size int64
+	// ContentID is the git object id of the content, which changes with
+	// the content and can be used to detect drift without fetching it
+	ContentID string
+}
+
+// FileCommit describes a change to a single file committed to a branch
+type FileCommit struct {
+	// Path of the file relative to the repository root
+	Path string
+	// Content is the new content of the file
+	Content []byte
+	// Branch the change is committed to
+	Branch string
+	// Message of the commit
+	Message string
+	// SourceCommitID is the commit the change is based on. It is required
+	// when changing an existing file and the commit is rejected if the file
+	// was changed since.
+	SourceCommitID string
+	// SourceBranch is the branch Branch is created from if it doesn't exist
+	SourceBranch string
+}
+
+// Commit describes a git commit
+type Commit struct {
+	ID        string `json:"id"`
+	DisplayID string `json:"displayId"`
+	Message   string `json:"message"`
+}
+
+// FileClientAPI is the API for reading and committing files in repositories
+type FileClientAPI interface {
+	BrowseDirectory(ctx context.Context, repo Repo, path string, at string) (result []File, err error)
+	CommitFile(ctx context.Context, repo Repo, file FileCommit) (result Commit, err error)
+	GetRawFile(ctx context.Context, repo Repo, path string, at string) (content []byte, err error)
+}
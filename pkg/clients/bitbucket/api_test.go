@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRepoSlug(t *testing.T) {
+	cases := map[string]string{
+		"repo":            "repo",
+		"My Repo":         "my-repo",
+		"my_repo.git":     "my_repo.git",
+		"Team  Infra/Ops": "team-infra-ops",
+	}
+
+	for name, want := range cases {
+		if got := RepoSlug(name); got != want {
+			t.Errorf("RepoSlug(%q): want %q, got %q", name, want, got)
+		}
+	}
+}
+
+func TestProjectKey(t *testing.T) {
+	cases := map[string]string{
+		"PRJ":   "PRJ",
+		"prj":   "PRJ",
+		"~user": "~USER",
+	}
+
+	for key, want := range cases {
+		if got := ProjectKey(key); got != want {
+			t.Errorf("ProjectKey(%q): want %q, got %q", key, want, got)
+		}
+	}
+}
+
+func TestWebhookConfigurationJSON(t *testing.T) {
+	cases := map[string]struct {
+		c    WebhookConfiguration
+		json string
+	}{
+		"Secret": {
+			c:    WebhookConfiguration{Secret: "s"},
+			json: `{"secret":"s"}`,
+		},
+		"NoSecret": {
+			c:    WebhookConfiguration{},
+			json: `{"secret":""}`,
+		},
+		"Extra": {
+			c:    WebhookConfiguration{Secret: "s", Extra: map[string]string{"algorithm": "sha256", "createdBy": "bb"}},
+			json: `{"algorithm":"sha256","createdBy":"bb","secret":"s"}`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := json.Marshal(tc.c)
+			if err != nil {
+				t.Fatalf("json.Marshal(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.json, string(got)); diff != "" {
+				t.Errorf("json.Marshal(...): -want, +got:\n%s", diff)
+			}
+			var c WebhookConfiguration
+			if err := json.Unmarshal([]byte(tc.json), &c); err != nil {
+				t.Fatalf("json.Unmarshal(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.c, c); diff != "" {
+				t.Errorf("json.Unmarshal(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWebhookConfigurationUnmarshalNonString(t *testing.T) {
+	var c WebhookConfiguration
+	if err := json.Unmarshal([]byte(`{"secret":null,"retries":3,"headers":{"X-A":"1"}}`), &c); err != nil {
+		t.Fatalf("json.Unmarshal(...): %v", err)
+	}
+	want := WebhookConfiguration{Extra: map[string]string{"retries": "3", "headers": `{"X-A":"1"}`}}
+	if diff := cmp.Diff(want, c); diff != "" {
+		t.Errorf("json.Unmarshal(...): -want, +got:\n%s", diff)
+	}
+}
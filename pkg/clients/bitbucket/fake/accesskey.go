@@ -19,7 +19,7 @@ package fake
 import (
 	"context"
 
-	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
 )
 
 var _ bitbucket.KeyClientAPI = &MockKeyClient{}
@@ -31,7 +31,7 @@ type MockKeyClient struct {
 	MockCreateAccessKey           func(ctx context.Context, repo bitbucket.Repo, key bitbucket.AccessKey) (result bitbucket.AccessKey, err error)
 	MockDeleteAccessKey           func(ctx context.Context, repo bitbucket.Repo, id int) (err error)
 	MockGetAccessKey              func(ctx context.Context, repo bitbucket.Repo, id int) (result bitbucket.AccessKey, err error)
-	MockListAccessKeys            func(ctx context.Context, repo bitbucket.Repo) (result []bitbucket.AccessKey, err error)
+	MockListAccessKeys            func(ctx context.Context, repo bitbucket.Repo, filter bitbucket.AccessKeyFilter) (result []bitbucket.AccessKey, err error)
 	MockUpdateAccessKeyPermission func(ctx context.Context, repo bitbucket.Repo, id int, permission string) (err error)
 }
 
@@ -51,8 +51,8 @@ func (c *MockKeyClient) GetAccessKey(ctx context.Context, repo bitbucket.Repo, i
 }
 
 // ListAccessKeys calls the mock
-func (c *MockKeyClient) ListAccessKeys(ctx context.Context, repo bitbucket.Repo) (result []bitbucket.AccessKey, err error) {
-	return c.MockListAccessKeys(ctx, repo)
+func (c *MockKeyClient) ListAccessKeys(ctx context.Context, repo bitbucket.Repo, filter bitbucket.AccessKeyFilter) (result []bitbucket.AccessKey, err error) {
+	return c.MockListAccessKeys(ctx, repo, filter)
 }
 
 // UpdateAccessKeyPermission calls the mock
@@ -0,0 +1,41 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+var _ bitbucket.DefaultPermissionClientAPI = &MockDefaultPermissionClient{}
+
+// MockDefaultPermissionClient is a fake implementation of DefaultPermissionClientAPI
+type MockDefaultPermissionClient struct {
+	MockGetDefaultPermission func(ctx context.Context, projectKey string, permission string) (permitted bool, err error)
+	MockSetDefaultPermission func(ctx context.Context, projectKey string, permission string, allow bool) (err error)
+}
+
+// GetDefaultPermission calls the mock
+func (c *MockDefaultPermissionClient) GetDefaultPermission(ctx context.Context, projectKey string, permission string) (permitted bool, err error) {
+	return c.MockGetDefaultPermission(ctx, projectKey, permission)
+}
+
+// SetDefaultPermission calls the mock
+func (c *MockDefaultPermissionClient) SetDefaultPermission(ctx context.Context, projectKey string, permission string, allow bool) (err error) {
+	return c.MockSetDefaultPermission(ctx, projectKey, permission, allow)
+}
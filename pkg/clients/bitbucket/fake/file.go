@@ -0,0 +1,49 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+var _ bitbucket.FileClientAPI = &MockFileClient{}
+
+// MockFileClient is a fake implementation of FileClientAPI
+type MockFileClient struct {
+	bitbucket.FileClientAPI
+
+	MockBrowseDirectory func(ctx context.Context, repo bitbucket.Repo, path string, at string) (result []bitbucket.File, err error)
+	MockCommitFile      func(ctx context.Context, repo bitbucket.Repo, file bitbucket.FileCommit) (result bitbucket.Commit, err error)
+	MockGetRawFile      func(ctx context.Context, repo bitbucket.Repo, path string, at string) (content []byte, err error)
+}
+
+// BrowseDirectory calls the mock
+func (c *MockFileClient) BrowseDirectory(ctx context.Context, repo bitbucket.Repo, path string, at string) (result []bitbucket.File, err error) {
+	return c.MockBrowseDirectory(ctx, repo, path, at)
+}
+
+// CommitFile calls the mock
+func (c *MockFileClient) CommitFile(ctx context.Context, repo bitbucket.Repo, file bitbucket.FileCommit) (result bitbucket.Commit, err error) {
+	return c.MockCommitFile(ctx, repo, file)
+}
+
+// GetRawFile calls the mock
+func (c *MockFileClient) GetRawFile(ctx context.Context, repo bitbucket.Repo, path string, at string) (content []byte, err error) {
+	return c.MockGetRawFile(ctx, repo, path, at)
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+var _ bitbucket.LicenseClientAPI = &MockLicenseClient{}
+
+// MockLicenseClient is a fake implementation of LicenseClientAPI
+type MockLicenseClient struct {
+	MockGetLicense func(ctx context.Context) (result bitbucket.License, err error)
+}
+
+// GetLicense calls the mock
+func (c *MockLicenseClient) GetLicense(ctx context.Context) (result bitbucket.License, err error) {
+	return c.MockGetLicense(ctx)
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+var _ bitbucket.MergeConfigClientAPI = &MockMergeConfigClient{}
+
+// MockMergeConfigClient is a fake implementation of MergeConfigClientAPI
+type MockMergeConfigClient struct {
+	MockGetMergeConfig    func(ctx context.Context, repo bitbucket.Repo) (result bitbucket.MergeConfig, err error)
+	MockSetMergeConfig    func(ctx context.Context, repo bitbucket.Repo, config bitbucket.MergeConfig) (result bitbucket.MergeConfig, err error)
+	MockDeleteMergeConfig func(ctx context.Context, repo bitbucket.Repo) (err error)
+}
+
+// GetMergeConfig calls the mock
+func (c *MockMergeConfigClient) GetMergeConfig(ctx context.Context, repo bitbucket.Repo) (result bitbucket.MergeConfig, err error) {
+	return c.MockGetMergeConfig(ctx, repo)
+}
+
+// SetMergeConfig calls the mock
+func (c *MockMergeConfigClient) SetMergeConfig(ctx context.Context, repo bitbucket.Repo, config bitbucket.MergeConfig) (result bitbucket.MergeConfig, err error) {
+	return c.MockSetMergeConfig(ctx, repo, config)
+}
+
+// DeleteMergeConfig calls the mock
+func (c *MockMergeConfigClient) DeleteMergeConfig(ctx context.Context, repo bitbucket.Repo) (err error) {
+	return c.MockDeleteMergeConfig(ctx, repo)
+}
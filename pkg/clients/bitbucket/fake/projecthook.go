@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+var _ bitbucket.ProjectHookClientAPI = &MockProjectHookClient{}
+
+// MockProjectHookClient is a fake implementation of ProjectHookClientAPI
+type MockProjectHookClient struct {
+	MockGetProjectHook         func(ctx context.Context, projectKey string, hookKey string) (result bitbucket.ProjectHook, err error)
+	MockEnableProjectHook      func(ctx context.Context, projectKey string, hookKey string, settings bitbucket.HookSettings) (result bitbucket.ProjectHook, err error)
+	MockDisableProjectHook     func(ctx context.Context, projectKey string, hookKey string) (err error)
+	MockGetProjectHookSettings func(ctx context.Context, projectKey string, hookKey string) (result bitbucket.HookSettings, err error)
+	MockSetProjectHookSettings func(ctx context.Context, projectKey string, hookKey string, settings bitbucket.HookSettings) (err error)
+}
+
+// GetProjectHook calls the mock
+func (c *MockProjectHookClient) GetProjectHook(ctx context.Context, projectKey string, hookKey string) (result bitbucket.ProjectHook, err error) {
+	return c.MockGetProjectHook(ctx, projectKey, hookKey)
+}
+
+// EnableProjectHook calls the mock
+func (c *MockProjectHookClient) EnableProjectHook(ctx context.Context, projectKey string, hookKey string, settings bitbucket.HookSettings) (result bitbucket.ProjectHook, err error) {
+	return c.MockEnableProjectHook(ctx, projectKey, hookKey, settings)
+}
+
+// DisableProjectHook calls the mock
+func (c *MockProjectHookClient) DisableProjectHook(ctx context.Context, projectKey string, hookKey string) (err error) {
+	return c.MockDisableProjectHook(ctx, projectKey, hookKey)
+}
+
+// GetProjectHookSettings calls the mock
+func (c *MockProjectHookClient) GetProjectHookSettings(ctx context.Context, projectKey string, hookKey string) (result bitbucket.HookSettings, err error) {
+	return c.MockGetProjectHookSettings(ctx, projectKey, hookKey)
+}
+
+// SetProjectHookSettings calls the mock
+func (c *MockProjectHookClient) SetProjectHookSettings(ctx context.Context, projectKey string, hookKey string, settings bitbucket.HookSettings) (err error) {
+	return c.MockSetProjectHookSettings(ctx, projectKey, hookKey, settings)
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+var _ bitbucket.RepositoryClientAPI = &MockRepositoryClient{}
+
+// MockRepositoryClient is a fake implementation of RepositoryClientAPI
+type MockRepositoryClient struct {
+	MockGetRepository    func(ctx context.Context, repo bitbucket.Repo) (result bitbucket.Repository, err error)
+	MockListRepositories func(ctx context.Context, projectKey string) (result []bitbucket.Repository, err error)
+}
+
+// GetRepository calls the mock
+func (c *MockRepositoryClient) GetRepository(ctx context.Context, repo bitbucket.Repo) (result bitbucket.Repository, err error) {
+	return c.MockGetRepository(ctx, repo)
+}
+
+// ListRepositories calls the mock
+func (c *MockRepositoryClient) ListRepositories(ctx context.Context, projectKey string) (result []bitbucket.Repository, err error) {
+	return c.MockListRepositories(ctx, projectKey)
+}
+
+var _ bitbucket.PermissionClientAPI = &MockPermissionClient{}
+
+// MockPermissionClient is a fake implementation of PermissionClientAPI
+type MockPermissionClient struct {
+	MockCheckRepositoryAdmin func(ctx context.Context, repo bitbucket.Repo) (err error)
+}
+
+// CheckRepositoryAdmin calls the mock
+func (c *MockPermissionClient) CheckRepositoryAdmin(ctx context.Context, repo bitbucket.Repo) (err error) {
+	return c.MockCheckRepositoryAdmin(ctx, repo)
+}
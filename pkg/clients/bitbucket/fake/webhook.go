@@ -14,12 +14,14 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
+// Package fake contains mock implementations of the bitbucket client APIs
+// for tests.
 package fake
 
 import (
 	"context"
 
-	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
 )
 
 var _ bitbucket.WebhookClientAPI = &MockWebhookClient{}
@@ -31,7 +33,12 @@ type MockWebhookClient struct {
 	MockCreateWebhook func(ctx context.Context, repo bitbucket.Repo, hook bitbucket.Webhook) (result bitbucket.Webhook, err error)
 	MockDeleteWebhook func(ctx context.Context, repo bitbucket.Repo, id int) (err error)
 	MockGetWebhook    func(ctx context.Context, repo bitbucket.Repo, id int) (result bitbucket.Webhook, err error)
+	MockListWebhooks  func(ctx context.Context, repo bitbucket.Repo, filter bitbucket.WebhookFilter) (result []bitbucket.Webhook, err error)
 	MockUpdateWebhook func(ctx context.Context, repo bitbucket.Repo, id int, hook bitbucket.Webhook) (result bitbucket.Webhook, err error)
+
+	MockGetWebhookStatistics func(ctx context.Context, repo bitbucket.Repo, id int) (result bitbucket.WebhookStatistics, err error)
+	MockGetRepository        func(ctx context.Context, repo bitbucket.Repo) (result bitbucket.Repository, err error)
+	MockListRepositories     func(ctx context.Context, projectKey string) (result []bitbucket.Repository, err error)
 }
 
 // CreateWebhook calls the mock
@@ -49,7 +56,35 @@ func (c *MockWebhookClient) GetWebhook(ctx context.Context, repo bitbucket.Repo,
 	return c.MockGetWebhook(ctx, repo, id)
 }
 
+// GetWebhookStatistics calls the mock, or returns no deliveries if it is
+// not set
+func (c *MockWebhookClient) GetWebhookStatistics(ctx context.Context, repo bitbucket.Repo, id int) (result bitbucket.WebhookStatistics, err error) {
+	if c.MockGetWebhookStatistics == nil {
+		return bitbucket.WebhookStatistics{}, nil
+	}
+	return c.MockGetWebhookStatistics(ctx, repo, id)
+}
+
+// ListWebhooks calls the mock
+func (c *MockWebhookClient) ListWebhooks(ctx context.Context, repo bitbucket.Repo, filter bitbucket.WebhookFilter) (result []bitbucket.Webhook, err error) {
+	return c.MockListWebhooks(ctx, repo, filter)
+}
+
 // UpdateWebhook calls the mock
 func (c *MockWebhookClient) UpdateWebhook(ctx context.Context, repo bitbucket.Repo, id int, hook bitbucket.Webhook) (result bitbucket.Webhook, err error) {
 	return c.MockUpdateWebhook(ctx, repo, id, hook)
 }
+
+// GetRepository calls the mock, or returns an empty repository if it is not
+// set
+func (c *MockWebhookClient) GetRepository(ctx context.Context, repo bitbucket.Repo) (result bitbucket.Repository, err error) {
+	if c.MockGetRepository == nil {
+		return bitbucket.Repository{}, nil
+	}
+	return c.MockGetRepository(ctx, repo)
+}
+
+// ListRepositories calls the mock
+func (c *MockWebhookClient) ListRepositories(ctx context.Context, projectKey string) (result []bitbucket.Repository, err error) {
+	return c.MockListRepositories(ctx, projectKey)
+}
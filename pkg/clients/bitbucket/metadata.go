@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ResponseMetadata describes a response of the bitbucket server
+type ResponseMetadata struct {
+	// RequestID is the id the server assigned to the request (X-AREQUESTID),
+	// which the Bitbucket admins can look up in the server logs
+	RequestID string
+	// StatusCode of the response, 0 if no response was received
+	StatusCode int
+	// Latency until the response headers were received
+	Latency time.Duration
+	// RateLimit is set when the server sent rate limiting headers
+	RateLimit *RateLimit
+	// RetryAfter is the delay the server asked for before retrying
+	RetryAfter time.Duration
+}
+
+// RateLimit is the rate limiting state reported by the server
+type RateLimit struct {
+	// Limit is the maximum number of tokens of the bucket
+	Limit int
+	// Remaining tokens available to the user
+	Remaining int
+}
+
+// MetadataRecorder collects the metadata of the responses to requests made
+// with a context returned by WithMetadataRecorder
+type MetadataRecorder struct {
+	mu       sync.Mutex
+	last     ResponseMetadata
+	requests int
+}
+
+// Record the metadata of a response
+func (r *MetadataRecorder) Record(md ResponseMetadata) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.last = md
+	r.requests++
+}
+
+// Last returns the metadata of the last response and whether any response
+// was recorded
+func (r *MetadataRecorder) Last() (ResponseMetadata, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.last, r.requests > 0
+}
+
+// Requests returns the number of recorded responses
+func (r *MetadataRecorder) Requests() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.requests
+}
+
+type metadataRecorderKey struct{}
+
+// WithMetadataRecorder returns a context in which clients record the
+// metadata of responses to the returned recorder
+func WithMetadataRecorder(ctx context.Context) (context.Context, *MetadataRecorder) {
+	r := &MetadataRecorder{}
+	return context.WithValue(ctx, metadataRecorderKey{}, r), r
+}
+
+// MetadataRecorderFrom returns the recorder of the context, nil if there is
+// none
+func MetadataRecorderFrom(ctx context.Context) *MetadataRecorder {
+	r, _ := ctx.Value(metadataRecorderKey{}).(*MetadataRecorder)
+	return r
+}
@@ -24,33 +24,25 @@ import (
 	"net/http"
 	"net/url"
 
-	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
 )
 
-// ListAccessKeys returns all access keys for the given repository
-func (c *Client) ListAccessKeys(ctx context.Context, repo bitbucket.Repo) ([]bitbucket.AccessKey, error) {
+// ListAccessKeys returns all access keys for the given repository which match
+// the filter
+func (c *Client) ListAccessKeys(ctx context.Context, repo bitbucket.Repo, filter bitbucket.AccessKeyFilter) ([]bitbucket.AccessKey, error) {
 	url := c.BaseURL + fmt.Sprintf("/rest/keys/1.0/projects/%s/repos/%s/ssh",
 		url.PathEscape(repo.ProjectKey), url.PathEscape(repo.Repo))
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	query := filterQuery("filter", filter.Text, "permission", filter.Permission)
+
+	ret := []bitbucket.AccessKey{}
+	newKey := func() interface{} { return &KeyDescription{} }
+	err := c.getPages(ctx, url, query, newKey, func(v interface{}) error {
+		ret = append(ret, accessKey(*v.(*KeyDescription)))
+		return nil
+	})
 	if err != nil {
-		return nil, err
-	}
-
-	var payload GetKeysPayload
-	if err := c.sendRequest(req, &payload); err != nil {
-		return nil, fmt.Errorf("ListAccessKeys(%+v): %w", repo, err)
-	}
-
-	ret := make([]bitbucket.AccessKey, 0, len(payload.Values))
-	for _, key := range payload.Values {
-		ret = append(ret, bitbucket.AccessKey{
-			Key:        key.Key.Text,
-			ID:         key.Key.ID,
-			Label:      key.Key.Label,
-			Permission: key.Permission,
-		})
+		return nil, fmt.Errorf("ListAccessKeys(%+v, %+v): %w", repo, filter, err)
 	}
-
 	return ret, nil
 }
 
@@ -69,12 +61,7 @@ func (c *Client) GetAccessKey(ctx context.Context, repo bitbucket.Repo, id int)
 		return bitbucket.AccessKey{}, fmt.Errorf("GetAccessKey(%+v, %d): %w", repo, id, err)
 	}
 
-	return bitbucket.AccessKey{
-		Key:        payload.Key.Text,
-		ID:         payload.Key.ID,
-		Label:      payload.Key.Label,
-		Permission: payload.Permission,
-	}, nil
+	return accessKey(payload), nil
 }
 
 // CreateAccessKey on a repository by providing the public key
@@ -104,12 +91,23 @@ func (c *Client) CreateAccessKey(ctx context.Context, repo bitbucket.Repo, key b
 	if err := c.sendRequest(req, &response); err != nil {
 		return bitbucket.AccessKey{}, err
 	}
-	return bitbucket.AccessKey{
-		ID:         response.Key.ID,
-		Key:        response.Key.Text,
-		Label:      response.Key.Label,
-		Permission: response.Permission,
-	}, nil
+	return accessKey(response), nil
+}
+
+// accessKey returns the access key of the payload, with its repository if
+// the payload contains one.
+func accessKey(payload KeyDescription) bitbucket.AccessKey {
+	key := bitbucket.AccessKey{
+		ID:         payload.Key.ID,
+		Key:        payload.Key.Text,
+		Label:      payload.Key.Label,
+		Permission: payload.Permission,
+	}
+	if payload.Repository.ID != 0 {
+		repo := repository(payload.Repository)
+		key.Repository = &repo
+	}
+	return key
 }
 
 // UpdateAccessKeyPermission enables mutation of permissions on a accesskey by providing the id of the access key.
@@ -181,8 +179,23 @@ type KeyInfo struct {
 // RepositoryInfo contains information about the repository
 type RepositoryInfo struct {
 	Name    string `json:"name"`
+	Slug    string `json:"slug"`
 	ID      int    `json:"id"`
 	Project ProjectInfo
+	Links   RepositoryLinks `json:"links"`
+}
+
+// RepositoryLinks are the links of a repository
+type RepositoryLinks struct {
+	// Clone are the URLs to clone the repository, named by protocol, e.g.
+	// http or ssh
+	Clone []NamedLink `json:"clone"`
+}
+
+// NamedLink is a link with a name
+type NamedLink struct {
+	Href string `json:"href"`
+	Name string `json:"name"`
 }
 
 // ProjectInfo contains information on the project
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"net/http"
+)
+
+// Authenticator adds credentials to a request
+type Authenticator interface {
+	Authenticate(req *http.Request)
+}
+
+// BearerAuth sends the token as bearer token, which is how Bitbucket
+// expects personal and HTTP access tokens
+type BearerAuth struct {
+	Token string
+}
+
+// Authenticate implements Authenticator
+func (a BearerAuth) Authenticate(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+}
+
+// BasicAuth sends the token as the password of HTTP basic auth, for
+// gateways in front of Bitbucket which only accept basic auth
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// Authenticate implements Authenticator
+func (a BasicAuth) Authenticate(req *http.Request) {
+	req.SetBasicAuth(a.Username, a.Password)
+}
+
+// HeaderAuth sends the token as is in a custom header
+type HeaderAuth struct {
+	Header string
+	Token  string
+}
+
+// Authenticate implements Authenticator
+func (a HeaderAuth) Authenticate(req *http.Request) {
+	req.Header.Set(a.Header, a.Token)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+// The benchmarks answer requests without a network, so that they measure
+// the cost of the client itself.
+
+func benchClient(body string) *Client {
+	return &Client{
+		BaseURL:    "http://bitbucket.example.com",
+		HTTPClient: &http.Client{Transport: responder{status: http.StatusOK, body: []byte(body)}},
+		Token:      "token",
+	}
+}
+
+const benchWebhook = `{"id":1,"name":"ci","createdDate":1618300000000,"updatedDate":1618300000000,` +
+	`"events":["repo:refs_changed","repo:modified"],"configuration":{"secret":"s3cr3t"},` +
+	`"url":"https://ci.example.com/hook","active":true,"sslVerificationRequired":true}`
+
+func BenchmarkGetWebhook(b *testing.B) {
+	c := benchClient(benchWebhook)
+	repo := bitbucket.Repo{ProjectKey: "PRJ", Repo: "repo"}
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.GetWebhook(ctx, repo, 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCreateWebhook(b *testing.B) {
+	c := benchClient(benchWebhook)
+	repo := bitbucket.Repo{ProjectKey: "PRJ", Repo: "repo"}
+	hook := bitbucket.Webhook{Name: "ci", Events: []string{"repo:refs_changed"}, URL: "https://ci.example.com/hook"}
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.CreateWebhook(ctx, repo, hook); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkListRepositories(b *testing.B) {
+	repos := make([]string, pageLimit)
+	for i := range repos {
+		repos[i] = fmt.Sprintf(`{"slug":"repo-%d","id":%d,"name":"Repo %d","scmId":"git","state":"AVAILABLE","forkable":true,`+
+			`"project":{"key":"PRJ","id":1,"name":"Project"},"public":false,`+
+			`"links":{"clone":[{"href":"ssh://git@bitbucket.example.com:7999/prj/repo-%d.git","name":"ssh"}]}}`, i, i, i, i)
+	}
+	c := benchClient(`{"size":100,"limit":100,"isLastPage":true,"start":0,"values":[` + strings.Join(repos, ",") + `]}`)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.ListRepositories(ctx, "PRJ"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,630 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rest implements the bitbucket client APIs against the REST API of
+// Bitbucket Server and Bitbucket Data Center.
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+// Client defines the API client
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Token      string
+	// Auth adds the credentials to requests. The Token is sent as bearer
+	// token when it is nil.
+	Auth Authenticator
+	// Timeout of a single request. It is applied on top of the deadline of
+	// the request context, zero means no additional timeout.
+	Timeout time.Duration
+	// PageConcurrency is the number of pages of a list requested at once
+	// after the first one. Pages are requested one after another if it is
+	// less than 2.
+	PageConcurrency int
+	// UnknownFields decides whether fields of responses which the client
+	// doesn't know are ignored, logged or rejected.
+	UnknownFields UnknownFields
+	// Log receives the structured logs of the client, e.g. the warnings
+	// about unknown fields. Nothing is logged if nil.
+	Log logging.Logger
+}
+
+// NormalizeBaseURL returns the base URL without surrounding spaces, duplicate
+// slashes and trailing slashes in its path, so that the paths of the API can be
+// appended to it, e.g. https://bitbucket.example.com/bitbucket for
+// https://bitbucket.example.com//bitbucket/.
+func NormalizeBaseURL(baseURL string) string {
+	baseURL = strings.TrimSpace(baseURL)
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Opaque != "" {
+		return strings.TrimRight(baseURL, "/")
+	}
+	if u.Path != "" {
+		u.Path = strings.TrimSuffix(path.Clean(u.Path), "/")
+		u.RawPath = ""
+	}
+	return u.String()
+}
+
+const (
+	// maxErrorBodySize limits how much of an error response is read
+	maxErrorBodySize = 64 * 1024
+	// maxErrorSnippetLength limits how much of a body which is not a
+	// bitbucket error is included in the error message
+	maxErrorSnippetLength = 256
+)
+
+type errorResponse struct {
+	Errors []struct {
+		Context       *string `json:"context"`
+		Message       string  `json:"message"`
+		ExceptionName *string `json:"exceptionName"`
+	} `json:"errors"`
+
+	code int
+	// requestID the server assigned to the request, which the Bitbucket
+	// admins can look up in the server logs
+	requestID string
+	// body is a snippet of the response when it did not contain
+	// bitbucket errors, e.g. an HTML error page of a proxy
+	body string
+}
+
+// newErrorResponse reads a bounded amount of the body of a failed request.
+// Bodies which are not bitbucket error JSON are kept as a short snippet.
+func newErrorResponse(res *http.Response) errorResponse {
+	errRes := errorResponse{code: res.StatusCode, requestID: res.Header.Get(headerRequestID)}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+	if err != nil {
+		errRes.body = fmt.Sprintf("cannot read response body: %v", err)
+		return errRes
+	}
+	if err := json.Unmarshal(body, &errRes); err != nil || len(errRes.Errors) == 0 {
+		errRes.Errors = nil
+		errRes.body = snippet(body)
+	}
+	return errRes
+}
+
+// snippet collapses whitespace and truncates the body for use in messages
+func snippet(body []byte) string {
+	s := strings.Join(strings.Fields(string(body)), " ")
+	if r := []rune(s); len(r) > maxErrorSnippetLength {
+		s = string(r[:maxErrorSnippetLength]) + "..."
+	}
+	return s
+}
+
+func (e errorResponse) Error() string {
+	status := fmt.Sprintf("HTTP status %v", e.code)
+	if e.requestID != "" {
+		status += fmt.Sprintf(", request ID %s", e.requestID)
+	}
+
+	if len(e.Errors) > 0 {
+		msgs := make([]string, 0, len(e.Errors))
+		for _, err := range e.Errors {
+			msg := err.Message
+			if err.ExceptionName != nil && *err.ExceptionName != "" {
+				msg += fmt.Sprintf(" (%s)", *err.ExceptionName)
+			}
+			msgs = append(msgs, msg)
+		}
+		return fmt.Sprintf("%s: %s", status, strings.Join(msgs, "; "))
+	}
+	if e.body != "" {
+		return fmt.Sprintf("%s: %s", status, e.body)
+	}
+	return status
+}
+
+// Is lets errors.Is match a response against the bitbucket errors of its
+// status code while keeping the messages returned by the server.
+func (e errorResponse) Is(target error) bool {
+	switch target {
+	case bitbucket.ErrNotFound:
+		return e.code == http.StatusNotFound
+	case bitbucket.ErrConflict:
+		return e.code == http.StatusConflict
+	case bitbucket.ErrUnauthorized:
+		return e.code == http.StatusUnauthorized
+	case bitbucket.ErrForbidden:
+		return e.code == http.StatusForbidden
+	case bitbucket.ErrRateLimited:
+		return e.code == http.StatusTooManyRequests
+	case bitbucket.ErrServer:
+		return e.code >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// unreachableError is returned when no response was received
+type unreachableError struct {
+	err error
+}
+
+func (e unreachableError) Error() string {
+	return e.err.Error()
+}
+
+func (e unreachableError) Unwrap() error {
+	return e.err
+}
+
+// Is lets errors.Is match the error against bitbucket.ErrUnreachable.
+func (e unreachableError) Is(target error) bool {
+	return target == bitbucket.ErrUnreachable
+}
+
+// notJSONError is returned for successful responses whose body is not JSON,
+// e.g. the login page of a single sign-on proxy in front of the server.
+type notJSONError struct {
+	code        int
+	contentType string
+	// body is a snippet of the response, if it was read
+	body string
+}
+
+func (e notJSONError) Error() string {
+	msg := fmt.Sprintf("HTTP status %v: response is not JSON", e.code)
+	if e.contentType != "" {
+		msg += fmt.Sprintf(" but %s", e.contentType)
+	}
+	if e.body != "" {
+		msg += ": " + e.body
+	}
+	return msg
+}
+
+// IsNotFound is a 404 error
+func IsNotFound(err error) bool {
+	var errResp errorResponse
+	if errors.As(err, &errResp) {
+		return errResp.code == http.StatusNotFound
+	}
+	return false
+}
+
+// NotFoundError is 404
+func NotFoundError() error {
+	return errorResponse{code: http.StatusNotFound}
+}
+
+func (c *Client) authenticator() Authenticator {
+	if c.Auth != nil {
+		return c.Auth
+	}
+	return BearerAuth{Token: c.Token}
+}
+
+// A streamDecoder decodes a response from the stream of its body itself,
+// e.g. to avoid buffering large responses
+type streamDecoder interface {
+	decodeStream(d *json.Decoder) error
+}
+
+func (c *Client) sendRequest(req *http.Request, v interface{}) error {
+	if c.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), c.Timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	if _, ok := req.Header["Content-Type"]; !ok {
+		req.Header["Content-Type"] = jsonMediaType
+	}
+	if _, ok := req.Header["Accept"]; !ok {
+		req.Header["Accept"] = jsonMediaType
+	}
+	c.authenticator().Authenticate(req)
+
+	start := time.Now()
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		if req.Context().Err() != nil {
+			return err
+		}
+		if r := bitbucket.MetadataRecorderFrom(req.Context()); r != nil {
+			r.Record(bitbucket.ResponseMetadata{Latency: time.Since(start)})
+		}
+		return unreachableError{err: err}
+	}
+	defer res.Body.Close() // nolint
+
+	if r := bitbucket.MetadataRecorderFrom(req.Context()); r != nil {
+		r.Record(responseMetadata(res, time.Since(start)))
+	}
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
+		return newErrorResponse(res)
+	}
+
+	if raw, ok := v.(*[]byte); ok {
+		*raw, err = io.ReadAll(res.Body)
+		return err
+	}
+
+	if s, ok := v.(streamDecoder); ok {
+		if err := s.decodeStream(json.NewDecoder(res.Body)); err != nil {
+			if ct := res.Header.Get("Content-Type"); !isJSON(ct) {
+				return notJSONError{code: res.StatusCode, contentType: ct}
+			}
+			return err
+		}
+		return nil
+	}
+
+	if v == nil {
+		return nil
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if _, err := buf.ReadFrom(res.Body); err != nil {
+		return err
+	}
+	body := bytes.TrimSpace(buf.Bytes())
+	if len(body) == 0 {
+		// e.g. 204 No Content, v keeps its zero value
+		return nil
+	}
+	if !json.Valid(body) {
+		return notJSONError{code: res.StatusCode, contentType: res.Header.Get("Content-Type"), body: snippet(body)}
+	}
+	if err := c.checkFields(body, v); err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// isJSON returns true if the Content-Type is JSON or not set.
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err != nil || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// jsonMediaType is the value of the Content-Type and Accept headers of
+// requests. It is shared by all requests to save an allocation per header,
+// so it must not be modified.
+var jsonMediaType = []string{"application/json; charset=utf-8"}
+
+// maxPooledBuffer is the capacity up to which buffers are returned to the
+// pool, so that a single large response doesn't stay in memory
+const maxPooledBuffer = 64 * 1024
+
+var buffers = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	buf := buffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns the buffer to the pool unless it grew too large.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBuffer {
+		buffers.Put(buf)
+	}
+}
+
+// Ping checks that the server can be reached and accepts the credentials of
+// the client
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/rest/api/1.0/projects?limit=1", nil)
+	if err != nil {
+		return err
+	}
+	return c.sendRequest(req, nil)
+}
+
+// ServerVersion returns the version of the server, e.g. 7.21.0
+func (c *Client) ServerVersion(ctx context.Context) (string, error) {
+	info, err := c.GetServerInfo(ctx)
+	return info.Version, err
+}
+
+// GetServerInfo returns the version, build number and product name of the
+// server
+func (c *Client) GetServerInfo(ctx context.Context) (bitbucket.ServerInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/rest/api/1.0/application-properties", nil)
+	if err != nil {
+		return bitbucket.ServerInfo{}, err
+	}
+	var props applicationProperties
+	if err := c.sendRequest(req, &props); err != nil {
+		return bitbucket.ServerInfo{}, err
+	}
+	return bitbucket.ServerInfo{
+		Version:     props.Version,
+		BuildNumber: props.BuildNumber,
+		DisplayName: props.DisplayName,
+	}, nil
+}
+
+// applicationProperties describe the server
+type applicationProperties struct {
+	Version     string `json:"version"`
+	BuildNumber string `json:"buildNumber"`
+	DisplayName string `json:"displayName"`
+}
+
+// Headers sent by Bitbucket with responses
+const (
+	headerRequestID          = "X-AREQUESTID"
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRetryAfter         = "Retry-After"
+)
+
+func responseMetadata(res *http.Response, latency time.Duration) bitbucket.ResponseMetadata {
+	md := bitbucket.ResponseMetadata{
+		RequestID:  res.Header.Get(headerRequestID),
+		StatusCode: res.StatusCode,
+		Latency:    latency,
+		RetryAfter: retryAfter(res.Header.Get(headerRetryAfter)),
+	}
+
+	limit, errLimit := strconv.Atoi(res.Header.Get(headerRateLimitLimit))
+	remaining, errRemaining := strconv.Atoi(res.Header.Get(headerRateLimitRemaining))
+	if errLimit == nil && errRemaining == nil {
+		md.RateLimit = &bitbucket.RateLimit{Limit: limit, Remaining: remaining}
+	}
+	return md
+}
+
+// retryAfter parses the Retry-After header, which is either a number of
+// seconds or an HTTP date
+func retryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// Pagination defines response pagination
+type Pagination struct {
+	Size          int  `json:"size"`
+	Limit         int  `json:"limit"`
+	IsLastPage    bool `json:"isLastPage"`
+	Start         int  `json:"start"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+// filterQuery returns the query of the filter parameters which are set.
+func filterQuery(params ...string) url.Values {
+	q := url.Values{}
+	for i := 0; i+1 < len(params); i += 2 {
+		if params[i+1] != "" {
+			q.Set(params[i], params[i+1])
+		}
+	}
+	return q
+}
+
+// pageLimit is the number of values requested per page of paged APIs
+const pageLimit = 100
+
+// getPages gets all pages of a paged API with the query, which may be nil.
+// The values of the pages are decoded one at a time as they are read from
+// the response, each into a new value of newValue, and passed to add in
+// order. After the first page it requests up to PageConcurrency pages at
+// once, assuming that every page advances as far as the first one did.
+func (c *Client) getPages(ctx context.Context, u string, query url.Values, newValue func() interface{}, add func(v interface{}) error) error {
+	start, batch, step := 0, 1, 0
+	for {
+		results := c.getPageBatch(ctx, u, query, newValue, start, batch, step)
+		next := start
+		for i, r := range results {
+			at := start + i*step
+			if r.err != nil {
+				return r.err
+			}
+			for _, v := range r.page.values {
+				if err := add(v); err != nil {
+					return err
+				}
+			}
+			if r.page.IsLastPage {
+				return nil
+			}
+			// Guard against servers or proxies which don't advance the
+			// page, which would loop forever
+			if r.page.NextPageStart <= at {
+				return fmt.Errorf("invalid next page start %d after %d", r.page.NextPageStart, at)
+			}
+			next = r.page.NextPageStart
+			// The following pages of the batch were requested at the
+			// wrong starts if this one advanced differently
+			if next-at != step {
+				step = next - at
+				break
+			}
+		}
+		start = next
+		if c.PageConcurrency > 1 {
+			batch = c.PageConcurrency
+		}
+	}
+}
+
+// page of a paged API
+type page struct {
+	Pagination
+	// newValue returns a pointer to decode a value into
+	newValue func() interface{}
+	// check checks the fields of each value, if not nil
+	check  func(raw []byte, v interface{}) error
+	values []interface{}
+}
+
+// decodeStream decodes the page from the stream of the response. Unlike
+// decoding it at once, the values are not buffered as raw JSON before they
+// are decoded.
+func (p *page) decodeStream(d *json.Decoder) error {
+	if err := expectDelim(d, '{'); err != nil {
+		return err
+	}
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t {
+		case "values":
+			err = p.decodeValues(d)
+		case "isLastPage":
+			err = d.Decode(&p.IsLastPage)
+		case "nextPageStart":
+			err = d.Decode(&p.NextPageStart)
+		case "start":
+			err = d.Decode(&p.Start)
+		case "limit":
+			err = d.Decode(&p.Limit)
+		case "size":
+			err = d.Decode(&p.Size)
+		default:
+			var skip json.RawMessage
+			err = d.Decode(&skip)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(d, '}')
+}
+
+// decodeValues decodes the array of values of the page, which may be null.
+func (p *page) decodeValues(d *json.Decoder) error {
+	t, err := d.Token()
+	if err != nil || t == nil {
+		return err
+	}
+	if t != json.Delim('[') {
+		return fmt.Errorf("invalid values of page: %v", t)
+	}
+	for d.More() {
+		v := p.newValue()
+		if err := p.decodeValue(d, v); err != nil {
+			return err
+		}
+		p.values = append(p.values, v)
+	}
+	return expectDelim(d, ']')
+}
+
+// decodeValue decodes the next value of the stream into v, checking its
+// fields first if the page has a check.
+func (p *page) decodeValue(d *json.Decoder, v interface{}) error {
+	if p.check == nil {
+		return d.Decode(v)
+	}
+	var raw json.RawMessage
+	if err := d.Decode(&raw); err != nil {
+		return err
+	}
+	if err := p.check(raw, v); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// expectDelim reads the delimiter from the stream.
+func expectDelim(d *json.Decoder, delim json.Delim) error {
+	t, err := d.Token()
+	if err != nil {
+		return err
+	}
+	if t != delim {
+		return fmt.Errorf("expected %v in JSON, got %v", delim, t)
+	}
+	return nil
+}
+
+type pageResult struct {
+	page page
+	err  error
+}
+
+// getPageBatch gets n pages starting at start, step apart, concurrently and
+// returns them in order.
+func (c *Client) getPageBatch(ctx context.Context, u string, query url.Values, newValue func() interface{}, start, n, step int) []pageResult {
+	results := make([]pageResult, n)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i].page, results[i].err = c.getPage(ctx, u, query, newValue, start+i*step)
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// getPage gets the page of a paged API at start.
+func (c *Client) getPage(ctx context.Context, u string, query url.Values, newValue func() interface{}, start int) (page, error) {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set("start", strconv.Itoa(start))
+	q.Set("limit", strconv.Itoa(pageLimit))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u+"?"+q.Encode(), nil)
+	if err != nil {
+		return page{}, err
+	}
+	p := page{newValue: newValue}
+	if c.UnknownFields != IgnoreUnknownFields {
+		p.check = c.checkFields
+	}
+	if err := c.sendRequest(req, &p); err != nil {
+		return page{}, err
+	}
+	return p, nil
+}
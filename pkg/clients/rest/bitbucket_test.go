@@ -0,0 +1,593 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+func TestSendRequestErrors(t *testing.T) {
+	cases := map[string]struct {
+		status int
+		body   string
+		want   error
+	}{
+		"NotFound": {
+			status: http.StatusNotFound,
+			want:   bitbucket.ErrNotFound,
+		},
+		"Conflict": {
+			status: http.StatusConflict,
+			body:   `{"errors":[{"message":"The repository has been updated since you last loaded it"}]}`,
+			want:   bitbucket.ErrConflict,
+		},
+		"Unauthorized": {
+			status: http.StatusUnauthorized,
+			want:   bitbucket.ErrUnauthorized,
+		},
+		"Forbidden": {
+			status: http.StatusForbidden,
+			body:   `{"errors":[{"message":"You are not permitted to access this resource"}]}`,
+			want:   bitbucket.ErrForbidden,
+		},
+		"RateLimited": {
+			status: http.StatusTooManyRequests,
+			want:   bitbucket.ErrRateLimited,
+		},
+		"ServerError": {
+			status: http.StatusBadGateway,
+			body:   "<html>Bad Gateway</html>",
+			want:   bitbucket.ErrServer,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body)) // nolint:errcheck
+			}))
+			defer srv.Close()
+
+			c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client()}
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodPut, srv.URL, nil)
+			err := c.sendRequest(req, nil)
+			if !errors.Is(err, tc.want) {
+				t.Errorf("sendRequest(...): want %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestSendRequestTimeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client(), Timeout: 10 * time.Millisecond}
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+	if err := c.sendRequest(req, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("sendRequest(...): want %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestSendRequestUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Close()
+
+	ctx, rec := bitbucket.WithMetadataRecorder(context.Background())
+	c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client()}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err := c.sendRequest(req, nil); !errors.Is(err, bitbucket.ErrUnreachable) {
+		t.Errorf("sendRequest(...): want %v, got %v", bitbucket.ErrUnreachable, err)
+	}
+	md, ok := rec.Last()
+	if !ok || md.StatusCode != 0 {
+		t.Errorf("sendRequest(...): want metadata without status code, got %+v", md)
+	}
+}
+
+func TestPing(t *testing.T) {
+	cases := map[string]struct {
+		status int
+		want   error
+	}{
+		"Success":      {status: http.StatusOK},
+		"Unauthorized": {status: http.StatusUnauthorized, want: bitbucket.ErrUnauthorized},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer token" {
+					t.Errorf("Ping(...): want the credentials to be sent")
+				}
+				w.WriteHeader(tc.status)
+				w.Write([]byte(`{"values":[]}`)) // nolint:errcheck
+			}))
+			defer srv.Close()
+
+			c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client(), Token: "token"}
+			if err := c.Ping(context.Background()); !errors.Is(err, tc.want) {
+				t.Errorf("Ping(...): want %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestServerVersion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/1.0/application-properties" {
+			t.Errorf("ServerVersion(...): unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"version":"7.21.0","buildNumber":"7021000","displayName":"Bitbucket"}`)) // nolint:errcheck
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client(), Token: "token"}
+	got, err := c.ServerVersion(context.Background())
+	if err != nil || got != "7.21.0" {
+		t.Errorf("ServerVersion(...): want 7.21.0, got %q, %v", got, err)
+	}
+}
+
+func TestNormalizeBaseURL(t *testing.T) {
+	cases := map[string]struct {
+		baseURL string
+		want    string
+	}{
+		"Host":               {baseURL: "https://bitbucket.example.com", want: "https://bitbucket.example.com"},
+		"TrailingSlash":      {baseURL: "https://bitbucket.example.com/", want: "https://bitbucket.example.com"},
+		"TrailingSlashes":    {baseURL: "https://bitbucket.example.com//", want: "https://bitbucket.example.com"},
+		"ContextPath":        {baseURL: "https://example.com/bitbucket/", want: "https://example.com/bitbucket"},
+		"DuplicateSlashes":   {baseURL: "https://example.com//scm//bitbucket", want: "https://example.com/scm/bitbucket"},
+		"Port":               {baseURL: " http://127.0.0.1:7990/ ", want: "http://127.0.0.1:7990"},
+		"EscapedContextPath": {baseURL: "https://example.com/my%20bitbucket/", want: "https://example.com/my%20bitbucket"},
+		"Invalid":            {baseURL: "https://example.com:port/", want: "https://example.com:port"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, NormalizeBaseURL(tc.baseURL)); diff != "" {
+				t.Errorf("NormalizeBaseURL(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBaseURLPathJoining(t *testing.T) {
+	cases := map[string]struct {
+		suffix string
+		want   string
+	}{
+		"Root":                     {suffix: "/", want: "/rest/api/1.0/application-properties"},
+		"ContextPath":              {suffix: "/bitbucket", want: "/bitbucket/rest/api/1.0/application-properties"},
+		"ContextPathTrailingSlash": {suffix: "/bitbucket//", want: "/bitbucket/rest/api/1.0/application-properties"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if diff := cmp.Diff(tc.want, r.URL.Path); diff != "" {
+					t.Errorf("ServerVersion(...): -want path, +got path:\n%s", diff)
+				}
+				w.Write([]byte(`{"version":"7.21.0"}`)) // nolint:errcheck
+			}))
+			defer srv.Close()
+
+			c := &Client{BaseURL: NormalizeBaseURL(srv.URL + tc.suffix), HTTPClient: srv.Client(), Token: "token"}
+			if _, err := c.ServerVersion(context.Background()); err != nil {
+				t.Errorf("ServerVersion(...): %v", err)
+			}
+		})
+	}
+}
+
+func TestSendRequestErrorMessage(t *testing.T) {
+	cases := map[string]struct {
+		status    int
+		requestID string
+		body      string
+		want      string
+	}{
+		"BitbucketErrors": {
+			status: http.StatusBadRequest,
+			body:   `{"errors":[{"context":null,"message":"bad key","exceptionName":null}]}`,
+			want:   "HTTP status 400: bad key",
+		},
+		"BitbucketErrorsWithRequestID": {
+			status:    http.StatusConflict,
+			requestID: "@1A2B3Cx123x456x0",
+			body:      `{"errors":[{"message":"duplicate key","exceptionName":"com.atlassian.bitbucket.ssh.DuplicateAccessKeyException"},{"message":"other"}]}`,
+			want:      "HTTP status 409, request ID @1A2B3Cx123x456x0: duplicate key (com.atlassian.bitbucket.ssh.DuplicateAccessKeyException); other",
+		},
+		"NotFound": {
+			status:    http.StatusNotFound,
+			requestID: "@1A2B3Cx123x456x1",
+			body:      `{"errors":[{"message":"Repository TEST/test does not exist.","exceptionName":"com.atlassian.bitbucket.repository.NoSuchRepositoryException"}]}`,
+			want:      "HTTP status 404, request ID @1A2B3Cx123x456x1: Repository TEST/test does not exist. (com.atlassian.bitbucket.repository.NoSuchRepositoryException)",
+		},
+		"HTMLErrorPage": {
+			status: http.StatusBadGateway,
+			body:   "<html>\n  <body>\n    <h1>502 Bad Gateway</h1>\n  </body>\n</html>\n",
+			want:   "HTTP status 502: <html> <body> <h1>502 Bad Gateway</h1> </body> </html>",
+		},
+		"TruncatedSnippet": {
+			status: http.StatusInternalServerError,
+			body:   strings.Repeat("x", 1000),
+			want:   "HTTP status 500: " + strings.Repeat("x", maxErrorSnippetLength) + "...",
+		},
+		"EmptyBody": {
+			status: http.StatusServiceUnavailable,
+			want:   "HTTP status 503",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.requestID != "" {
+					w.Header().Set(headerRequestID, tc.requestID)
+				}
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body)) // nolint:errcheck
+			}))
+			defer srv.Close()
+
+			c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client()}
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+			err := c.sendRequest(req, nil)
+			if err == nil {
+				t.Fatal("sendRequest(...): want error, got nil")
+			}
+			if diff := cmp.Diff(tc.want, err.Error()); diff != "" {
+				t.Errorf("sendRequest(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSendRequestBody(t *testing.T) {
+	type result struct {
+		Key string `json:"key"`
+	}
+
+	cases := map[string]struct {
+		status      int
+		contentType string
+		body        string
+		v           func() interface{}
+		want        interface{}
+		err         string
+	}{
+		"NoContent": {
+			status: http.StatusNoContent,
+			v:      func() interface{} { return &result{} },
+			want:   &result{},
+		},
+		"EmptyBody": {
+			status: http.StatusOK,
+			body:   " \n",
+			v:      func() interface{} { return &result{} },
+			want:   &result{},
+		},
+		"JSON": {
+			status:      http.StatusOK,
+			contentType: "application/json;charset=UTF-8",
+			body:        `{"key":"PRJ"}`,
+			v:           func() interface{} { return &result{} },
+			want:        &result{Key: "PRJ"},
+		},
+		"NothingToDecode": {
+			status:      http.StatusOK,
+			contentType: "text/html",
+			body:        "<html>ok</html>",
+			v:           func() interface{} { return nil },
+		},
+		"HTML": {
+			status:      http.StatusOK,
+			contentType: "text/html; charset=utf-8",
+			body:        "<html>\n  <body>Log in</body>\n</html>\n",
+			v:           func() interface{} { return &result{} },
+			err:         "HTTP status 200: response is not JSON but text/html; charset=utf-8: <html> <body>Log in</body> </html>",
+		},
+		"HTMLPage": {
+			status:      http.StatusOK,
+			contentType: "text/html",
+			body:        "<html>Log in</html>",
+			v:           func() interface{} { return &page{newValue: func() interface{} { return &result{} }} },
+			err:         "HTTP status 200: response is not JSON but text/html",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.contentType != "" {
+					w.Header().Set("Content-Type", tc.contentType)
+				}
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body)) // nolint:errcheck
+			}))
+			defer srv.Close()
+
+			c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client()}
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+			v := tc.v()
+			err := c.sendRequest(req, v)
+			if tc.err != "" {
+				if err == nil {
+					t.Fatal("sendRequest(...): want error, got nil")
+				}
+				if diff := cmp.Diff(tc.err, err.Error()); diff != "" {
+					t.Errorf("sendRequest(...): -want error, +got error:\n%s", diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("sendRequest(...): %v", err)
+			}
+			if tc.want != nil {
+				if diff := cmp.Diff(tc.want, v); diff != "" {
+					t.Errorf("sendRequest(...): -want, +got:\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestSendRequestAuth(t *testing.T) {
+	cases := map[string]struct {
+		c      Client
+		header string
+		want   string
+	}{
+		"DefaultBearer": {
+			c:      Client{Token: "secret"},
+			header: "Authorization",
+			want:   "Bearer secret",
+		},
+		"Basic": {
+			c:      Client{Auth: BasicAuth{Username: "bot", Password: "secret"}},
+			header: "Authorization",
+			want:   "Basic Ym90OnNlY3JldA==",
+		},
+		"Header": {
+			c:      Client{Auth: HeaderAuth{Header: "X-Gateway-Token", Token: "secret"}},
+			header: "X-Gateway-Token",
+			want:   "secret",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if diff := cmp.Diff(tc.want, r.Header.Get(tc.header)); diff != "" {
+					t.Errorf("sendRequest(...): -want, +got\n%s", diff)
+				}
+			}))
+			defer srv.Close()
+
+			tc.c.BaseURL = srv.URL
+			tc.c.HTTPClient = srv.Client()
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+			if err := tc.c.sendRequest(req, nil); err != nil {
+				t.Errorf("sendRequest(...): %v", err)
+			}
+		})
+	}
+}
+
+func TestSendRequestMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerRequestID, "@1ABC2x1234x5678x0")
+		w.Header().Set(headerRateLimitLimit, "60")
+		w.Header().Set(headerRateLimitRemaining, "0")
+		w.Header().Set(headerRetryAfter, "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	ctx, rec := bitbucket.WithMetadataRecorder(context.Background())
+	c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client()}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err := c.sendRequest(req, nil); err == nil {
+		t.Fatal("sendRequest(...): want error, got nil")
+	}
+
+	md, ok := rec.Last()
+	if !ok {
+		t.Fatal("Last(): want recorded metadata")
+	}
+	want := bitbucket.ResponseMetadata{
+		RequestID:  "@1ABC2x1234x5678x0",
+		StatusCode: http.StatusTooManyRequests,
+		RateLimit:  &bitbucket.RateLimit{Limit: 60, Remaining: 0},
+		RetryAfter: 30 * time.Second,
+	}
+	if diff := cmp.Diff(want, md, cmpopts.IgnoreFields(bitbucket.ResponseMetadata{}, "Latency")); diff != "" {
+		t.Errorf("sendRequest(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestGetPages(t *testing.T) {
+	cases := map[string]struct {
+		concurrency int
+		// serverLimit is the page size of the server, which ignores
+		// larger requested limits
+		serverLimit int
+		total       int
+		// requests is the number of requests, which includes those past
+		// the last page when pages are requested at once
+		requests int
+	}{
+		"OneAfterAnother": {
+			serverLimit: 100,
+			total:       250,
+			requests:    3,
+		},
+		"Concurrent": {
+			concurrency: 4,
+			serverLimit: 100,
+			total:       650,
+			requests:    9,
+		},
+		"ConcurrentSmallerServerLimit": {
+			concurrency: 3,
+			serverLimit: 25,
+			total:       110,
+			requests:    7,
+		},
+		"ConcurrentSinglePage": {
+			concurrency: 4,
+			serverLimit: 100,
+			total:       10,
+			requests:    1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			requests := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				requests++
+				mu.Unlock()
+				start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+				end := start + tc.serverLimit
+				if end > tc.total {
+					end = tc.total
+				}
+				values := []int{}
+				for i := start; i < end; i++ {
+					values = append(values, i)
+				}
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"start":         start,
+					"limit":         tc.serverLimit,
+					"size":          len(values),
+					"isLastPage":    end >= tc.total,
+					"nextPageStart": end,
+					"values":        values,
+				})
+			}))
+			defer srv.Close()
+
+			c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client(), PageConcurrency: tc.concurrency}
+			var got []int
+			newInt := func() interface{} { return new(int) }
+			err := c.getPages(context.Background(), srv.URL, nil, newInt, func(v interface{}) error {
+				got = append(got, *v.(*int))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("getPages(...): %v", err)
+			}
+			want := make([]int, tc.total)
+			for i := range want {
+				want[i] = i
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("getPages(...): -want, +got:\n%s", diff)
+			}
+			if requests != tc.requests {
+				t.Errorf("getPages(...): want %d requests, got %d", tc.requests, requests)
+			}
+		})
+	}
+}
+
+func TestPageDecodeStream(t *testing.T) {
+	type want struct {
+		page   Pagination
+		values []interface{}
+		err    bool
+	}
+
+	cases := map[string]struct {
+		body string
+		want want
+	}{
+		"Values": {
+			body: `{"size":2,"limit":2,"isLastPage":false,"start":0,"nextPageStart":2,"values":[{"key":"A","name":"a"},{"key":"B","name":"b","links":{"self":[{"href":"x"}]}}]}`,
+			want: want{
+				page:   Pagination{Size: 2, Limit: 2, NextPageStart: 2},
+				values: []interface{}{&projectInfo{Key: "A", Name: "a"}, &projectInfo{Key: "B", Name: "b"}},
+			},
+		},
+		"UnknownFields": {
+			body: `{"values":[],"extra":{"nested":[1,{"values":[2]}]},"isLastPage":true}`,
+			want: want{page: Pagination{IsLastPage: true}},
+		},
+		"NullValues": {
+			body: `{"isLastPage":true,"values":null}`,
+			want: want{page: Pagination{IsLastPage: true}},
+		},
+		"NotAnObject": {
+			body: `[]`,
+			want: want{err: true},
+		},
+		"InvalidValues": {
+			body: `{"values":{"key":"A"}}`,
+			want: want{err: true},
+		},
+		"Truncated": {
+			body: `{"values":[{"key":"A"}`,
+			want: want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := page{newValue: func() interface{} { return &projectInfo{} }}
+			err := p.decodeStream(json.NewDecoder(strings.NewReader(tc.body)))
+			if (err != nil) != tc.want.err {
+				t.Fatalf("decodeStream(...): want error %t, got %v", tc.want.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.page, p.Pagination); diff != "" {
+				t.Errorf("decodeStream(...): -want pagination, +got pagination:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.values, p.values); diff != "" {
+				t.Errorf("decodeStream(...): -want values, +got values:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+const (
+	// DefaultCircuitBreakerThreshold is the number of consecutive failures
+	// after which the circuit breaker opens
+	DefaultCircuitBreakerThreshold = 5
+	// DefaultCircuitBreakerCooldown is how long an open circuit breaker
+	// fails fast before letting a probe request through
+	DefaultCircuitBreakerCooldown = 30 * time.Second
+
+	// circuitBreakerIdleTimeout is how long the circuit breaker of a base
+	// URL is kept after it was last handed to a client. Its cooldown has
+	// long passed by then, so a new breaker behaves the same.
+	circuitBreakerIdleTimeout = time.Hour
+)
+
+// CircuitBreaker tracks consecutive failures against a single Bitbucket
+// server. Once the threshold is reached the breaker opens and requests fail
+// with bitbucket.ErrCircuitOpen until the cooldown has passed and a single
+// probe request succeeds.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker creates a closed circuit breaker
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// sharedBreaker is a circuit breaker of a base URL and when it was last
+// handed to a client.
+type sharedBreaker struct {
+	breaker  *CircuitBreaker
+	lastUsed time.Time
+}
+
+var breakers = struct {
+	sync.Mutex
+	m   map[string]*sharedBreaker
+	now func() time.Time
+}{m: map[string]*sharedBreaker{}, now: time.Now}
+
+// CircuitBreakerFor returns the circuit breaker shared by all clients of the
+// given base URL. Clients are created on every reconcile, so the breaker
+// state has to outlive them. Breakers which were not handed to any client
+// for an hour are forgotten, e.g. those of removed ProviderConfigs.
+func CircuitBreakerFor(baseURL string) *CircuitBreaker {
+	breakers.Lock()
+	defer breakers.Unlock()
+
+	now := breakers.now()
+	for url, sb := range breakers.m {
+		if now.Sub(sb.lastUsed) > circuitBreakerIdleTimeout {
+			delete(breakers.m, url)
+		}
+	}
+
+	sb, ok := breakers.m[baseURL]
+	if !ok {
+		sb = &sharedBreaker{breaker: NewCircuitBreaker(DefaultCircuitBreakerThreshold, DefaultCircuitBreakerCooldown)}
+		breakers.m[baseURL] = sb
+	}
+	sb.lastUsed = now
+	return sb.breaker
+}
+
+// allow returns bitbucket.ErrCircuitOpen if the request must not be sent.
+// When the cooldown of an open breaker has passed a single request is let
+// through as a probe.
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return nil
+	}
+	if b.probing || b.now().Sub(b.openedAt) < b.cooldown {
+		return bitbucket.ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+func (b *CircuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	b.failures = 0
+}
+
+func (b *CircuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = b.now()
+	}
+}
+
+// release ends a probe without counting it either way, used when the caller
+// gave up on the request.
+func (b *CircuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// NewCircuitBreakerTransport returns a RoundTripper which fails fast while
+// the given circuit breaker is open. Connection errors and 5xx responses
+// count as failures.
+func NewCircuitBreakerTransport(next http.RoundTripper, b *CircuitBreaker) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &circuitBreakerTransport{next: next, breaker: b}
+}
+
+type circuitBreakerTransport struct {
+	next    http.RoundTripper
+	breaker *CircuitBreaker
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *circuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.breaker.allow(); err != nil {
+		return nil, err
+	}
+
+	res, err := t.next.RoundTrip(req)
+	switch {
+	case req.Context().Err() != nil:
+		t.breaker.release()
+	case err != nil || res.StatusCode >= http.StatusInternalServerError:
+		t.breaker.failure()
+	default:
+		t.breaker.success()
+	}
+	return res, err
+}
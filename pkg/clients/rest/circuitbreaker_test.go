@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+func TestCircuitBreakerTransport(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	b := NewCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+	c := &http.Client{Transport: NewCircuitBreakerTransport(http.DefaultTransport, b)}
+
+	get := func() error {
+		res, err := c.Get(srv.URL)
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	}
+
+	// Two failures open the breaker
+	for i := 0; i < 2; i++ {
+		if err := get(); err != nil {
+			t.Fatalf("Get(...): unexpected error: %v", err)
+		}
+	}
+	if err := get(); !errors.Is(err, bitbucket.ErrCircuitOpen) {
+		t.Errorf("Get(...): want ErrCircuitOpen, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Get(...): want 2 calls to the server while open, got %d", calls)
+	}
+
+	// A failing probe after the cooldown keeps it open
+	now = now.Add(time.Minute)
+	if err := get(); err != nil {
+		t.Fatalf("Get(...): unexpected error on probe: %v", err)
+	}
+	if err := get(); !errors.Is(err, bitbucket.ErrCircuitOpen) {
+		t.Errorf("Get(...): want ErrCircuitOpen after failed probe, got %v", err)
+	}
+
+	// A successful probe closes it
+	now = now.Add(time.Minute)
+	status = http.StatusOK
+	for i := 0; i < 3; i++ {
+		if err := get(); err != nil {
+			t.Errorf("Get(...): unexpected error after recovery: %v", err)
+		}
+	}
+	if calls != 6 {
+		t.Errorf("Get(...): want 6 calls to the server, got %d", calls)
+	}
+}
+
+func TestCircuitBreakerFor(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	defer func(m map[string]*sharedBreaker, fn func() time.Time) {
+		breakers.m, breakers.now = m, fn
+	}(breakers.m, breakers.now)
+	breakers.m = map[string]*sharedBreaker{}
+	breakers.now = func() time.Time { return now }
+
+	a := CircuitBreakerFor("https://a.example.com")
+	if CircuitBreakerFor("https://a.example.com") != a {
+		t.Errorf("CircuitBreakerFor(...): want the same breaker for the same base URL")
+	}
+	if CircuitBreakerFor("https://b.example.com") == a {
+		t.Errorf("CircuitBreakerFor(...): want another breaker for another base URL")
+	}
+
+	now = now.Add(45 * time.Minute)
+	CircuitBreakerFor("https://b.example.com")
+	now = now.Add(45 * time.Minute)
+	CircuitBreakerFor("https://b.example.com")
+	if _, ok := breakers.m["https://a.example.com"]; ok {
+		t.Errorf("CircuitBreakerFor(...): want the idle breaker forgotten")
+	}
+	if _, ok := breakers.m["https://b.example.com"]; !ok {
+		t.Errorf("CircuitBreakerFor(...): want the breaker in use kept")
+	}
+	if CircuitBreakerFor("https://a.example.com") == a {
+		t.Errorf("CircuitBreakerFor(...): want a new breaker after the idle one was forgotten")
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+)
+
+const redacted = "<redacted>"
+
+// sensitive are the parts of the names of headers and JSON fields whose
+// values are redacted from the log
+var sensitive = []string{"auth", "cookie", "token", "secret", "password", "api-key", "apikey"}
+
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range sensitive {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewDebugTransport returns a RoundTripper which logs all requests and
+// responses. Credentials and secrets in headers and JSON bodies are
+// redacted, other bodies are only logged by their size.
+func NewDebugTransport(next http.RoundTripper, log logging.Logger) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &debugTransport{next: next, log: log}
+}
+
+type debugTransport struct {
+	next http.RoundTripper
+	log  logging.Logger
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := peekRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	t.log.Info("HTTP request", "method", req.Method, "url", req.URL.String(), "headers", redactHeaders(req.Header), "body", redactBody(body))
+
+	start := time.Now()
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.log.Info("HTTP request failed", "method", req.Method, "url", req.URL.String(), "duration", time.Since(start).String(), "error", err.Error())
+		return nil, err
+	}
+
+	body, err = io.ReadAll(res.Body)
+	res.Body.Close() // nolint:errcheck
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	t.log.Info("HTTP response", "method", req.Method, "url", req.URL.String(), "status", res.StatusCode, "duration", time.Since(start).String(), "headers", redactHeaders(res.Header), "body", redactBody(body))
+	return res, nil
+}
+
+// peekRequestBody returns the body of the request and replaces it by a copy
+// so it can still be sent.
+func peekRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close() // nolint:errcheck
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func redactHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		if isSensitive(name) {
+			out[name] = redacted
+			continue
+		}
+		out[name] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+// redactBody returns a JSON body with the values of sensitive fields
+// redacted. Other bodies, e.g. uploaded files, are only described by their
+// size.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return fmt.Sprintf("<%d bytes>", len(body))
+	}
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(redactJSON(v)); err != nil {
+		return fmt.Sprintf("<%d bytes>", len(body))
+	}
+	return strings.TrimSpace(out.String())
+}
+
+func redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if isSensitive(k) {
+				v[k] = redacted
+				continue
+			}
+			v[k] = redactJSON(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = redactJSON(e)
+		}
+	}
+	return v
+}
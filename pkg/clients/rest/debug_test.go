@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+)
+
+// captureLogger records all key/value pairs logged at info level
+type captureLogger struct {
+	logr.Logger
+	lines *[]string
+}
+
+func (l captureLogger) Info(msg string, keysAndValues ...interface{}) {
+	*l.lines = append(*l.lines, fmt.Sprint(append([]interface{}{msg}, keysAndValues...)...))
+}
+
+func (l captureLogger) Enabled() bool { return true }
+
+func TestDebugTransport(t *testing.T) {
+	const (
+		token  = "secret-token"
+		secret = "webhook-secret"
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), secret) {
+			t.Errorf("RoundTrip(...): want the request body to be sent unchanged, got %s", body)
+		}
+		w.Header().Set("Set-Cookie", "session=abc")
+		fmt.Fprintf(w, `{"id":1,"configuration":{"secret":%q}}`, secret)
+	}))
+	defer srv.Close()
+
+	var lines []string
+	c := &Client{
+		BaseURL: srv.URL,
+		Token:   token,
+		HTTPClient: &http.Client{
+			Transport: NewDebugTransport(srv.Client().Transport, logging.NewLogrLogger(captureLogger{lines: &lines})),
+		},
+	}
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(fmt.Sprintf(`{"name":"hook","configuration":{"secret":%q}}`, secret)))
+	var res map[string]interface{}
+	if err := c.sendRequest(req, &res); err != nil {
+		t.Fatalf("sendRequest(...): %v", err)
+	}
+	if res["configuration"].(map[string]interface{})["secret"] != secret {
+		t.Errorf("sendRequest(...): want the response body to be returned unchanged, got %v", res)
+	}
+
+	if len(lines) != 2 {
+		t.Fatalf("RoundTrip(...): want a request and a response logged, got %v", lines)
+	}
+	for _, l := range lines {
+		for _, s := range []string{token, secret, "session=abc"} {
+			if strings.Contains(l, s) {
+				t.Errorf("RoundTrip(...): log contains %q: %s", s, l)
+			}
+		}
+		if !strings.Contains(l, redacted) {
+			t.Errorf("RoundTrip(...): want redacted values in log: %s", l)
+		}
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	cases := map[string]struct {
+		body string
+		want string
+	}{
+		"Empty":   {},
+		"NotJSON": {body: "binary file", want: "<11 bytes>"},
+		"Nested": {
+			body: `{"values":[{"password":"p","name":"n"}],"key":"ssh-rsa AAA"}`,
+			want: `{"key":"ssh-rsa AAA","values":[{"name":"n","password":"<redacted>"}]}`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := redactBody([]byte(tc.body)); got != tc.want {
+				t.Errorf("redactBody(...): want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
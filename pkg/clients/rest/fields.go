@@ -0,0 +1,179 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+// UnknownFields decides what the client does with fields of responses which
+// it neither decodes nor deliberately ignores. They hint at a change of the
+// API of Bitbucket, which may make the client decode responses subtly wrong.
+type UnknownFields int
+
+// What to do with unknown fields
+const (
+	// IgnoreUnknownFields decodes responses without checking their fields
+	IgnoreUnknownFields UnknownFields = iota
+	// WarnUnknownFields logs each unknown field once and decodes the
+	// response anyway
+	WarnUnknownFields
+	// RejectUnknownFields fails requests with responses with unknown
+	// fields, e.g. in tests
+	RejectUnknownFields
+)
+
+// ignoredFields are the fields of responses which Bitbucket sends but the
+// client doesn't need, by the type the responses are decoded into
+var ignoredFields = map[reflect.Type][]string{
+	reflect.TypeOf(applicationProperties{}):    {"buildDate"},
+	reflect.TypeOf(projectInfo{}):              {"id", "description", "public", "type", "links", "owner", "avatar", "avatarUrl"},
+	reflect.TypeOf(ProjectInfo{}):              {"id", "name", "description", "public", "type", "links", "owner", "avatar", "avatarUrl"},
+	reflect.TypeOf(RepositoryInfo{}):           {"description", "hierarchyId", "scmId", "state", "statusMessage", "forkable", "public", "archived", "origin", "defaultBranch"},
+	reflect.TypeOf(RepositoryLinks{}):          {"self"},
+	reflect.TypeOf(KeyInfo{}):                  {"algorithmType", "bitLength", "createdDate", "expiryDays", "fingerprint", "lastAuthenticated"},
+	reflect.TypeOf(bitbucket.Webhook{}):        {"scopeType", "sslVerificationRequired", "statistics", "credentials"},
+	reflect.TypeOf(webhookStatisticsPayload{}): {"lastSuccess", "lastFailure", "lastError"},
+	reflect.TypeOf(pullRequestSettings{}):      {"requiredAllApprovers", "requiredAllTasksComplete", "requiredApprovers", "requiredApproversDeprecated", "requiredSuccessfulBuilds", "requiredSuccessfulBuildsDeprecated", "needsWork"},
+	reflect.TypeOf(mergeConfigPayload{}):       {"commitMessageTemplate", "commitSummaries"},
+	reflect.TypeOf(mergeStrategyPayload{}):     {"name", "description", "flag", "links"},
+	reflect.TypeOf(licensePayload{}):           {"creationDate", "purchaseDate", "numberOfDaysBeforeExpiry", "numberOfDaysBeforeMaintenanceExpiry", "gracePeriodEndDate", "numberOfDaysBeforeGracePeriodExpiry", "serverId", "supportEntitlementNumber", "license"},
+	reflect.TypeOf(licenseStatusPayload{}):     {"serverId"},
+	reflect.TypeOf(hookPayload{}):              {"scope"},
+	reflect.TypeOf(hookDetailsPayload{}):       {"description", "configFormKey", "supportedScopes"},
+	reflect.TypeOf(BrowsePayload{}):            {"path", "revision"},
+	reflect.TypeOf(BrowseChild{}):              {"node"},
+	reflect.TypeOf(BrowseChild{}.Path):         {"components", "parent", "name", "extension"},
+	reflect.TypeOf(bitbucket.Commit{}):         {"author", "authorTimestamp", "committer", "committerTimestamp", "parents", "properties"},
+}
+
+// checkFields returns an error naming the unknown fields of the JSON value
+// if there are any. In WarnUnknownFields mode it logs them instead, once per
+// field and type.
+func (c *Client) checkFields(raw []byte, v interface{}) error {
+	if c.UnknownFields == IgnoreUnknownFields {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		// Decoding the response reports the error
+		return nil
+	}
+	t := reflect.TypeOf(v)
+	var unknown []string
+	collectUnknown(value, t, "", &unknown)
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	if c.UnknownFields == RejectUnknownFields {
+		return fmt.Errorf("unknown fields of %s: %s", typeName(t), strings.Join(unknown, ", "))
+	}
+	for _, f := range unknown {
+		if _, seen := warned.LoadOrStore(typeName(t)+" "+f, true); !seen && c.Log != nil {
+			c.Log.Info("Response has an unknown field, the API of Bitbucket may have changed", "type", typeName(t), "field", f)
+		}
+	}
+	return nil
+}
+
+// warned are the unknown fields which were logged already
+var warned sync.Map
+
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.String()
+}
+
+// collectUnknown appends the paths of the fields of the value which are
+// neither decoded into the type nor ignored to unknown.
+func collectUnknown(value interface{}, t reflect.Type, path string, unknown *[]string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+		return
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if t.Kind() != reflect.Struct {
+			return
+		}
+		fields := jsonFields(t)
+		ignored := map[string]bool{}
+		for _, f := range ignoredFields[t] {
+			ignored[strings.ToLower(f)] = true
+		}
+		for k, fv := range v {
+			ft, ok := fields[strings.ToLower(k)]
+			switch {
+			case ok:
+				collectUnknown(fv, ft, path+k+".", unknown)
+			case !ignored[strings.ToLower(k)]:
+				*unknown = append(*unknown, path+k)
+			}
+		}
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return
+		}
+		for _, e := range v {
+			collectUnknown(e, t.Elem(), path, unknown)
+		}
+	}
+}
+
+// jsonFields returns the types of the fields of the struct type by their
+// JSON names in lower case, as encoding/json matches them case insensitively.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for n, et := range jsonFields(ft) {
+				fields[n] = et
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = f.Type
+	}
+	return fields
+}
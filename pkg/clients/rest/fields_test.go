@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+func TestCheckFields(t *testing.T) {
+	cases := map[string]struct {
+		raw  string
+		v    interface{}
+		want string
+	}{
+		"Known": {
+			raw: `{"id":1,"name":"ci","configuration":{"secret":"s"},"events":["repo:modified"],"url":"https://ci.example.com"}`,
+			v:   &bitbucket.Webhook{},
+		},
+		"Ignored": {
+			raw: `{"id":1,"scopeType":"repository","configuration":{"createdBy":"bb"},"sslVerificationRequired":true}`,
+			v:   &bitbucket.Webhook{},
+		},
+		"CaseInsensitive": {
+			raw: `{"Version":"7.21.0"}`,
+			v:   &applicationProperties{},
+		},
+		"Unknown": {
+			raw:  `{"id":1,"secretHeader":"X-Hub"}`,
+			v:    &bitbucket.Webhook{},
+			want: "unknown fields of bitbucket.Webhook: secretHeader",
+		},
+		"ExtraConfiguration": {
+			raw: `{"id":1,"configuration":{"secret":"s","algorithm":"sha256"}}`,
+			v:   &bitbucket.Webhook{},
+		},
+		"UnknownInSlice": {
+			raw:  `{"links":{"clone":[{"href":"ssh://x","name":"ssh","protocol":"ssh"}]}}`,
+			v:    &RepositoryInfo{},
+			want: "unknown fields of rest.RepositoryInfo: links.clone.protocol",
+		},
+		"Embedded": {
+			raw: `{"children":{"size":1,"limit":1,"start":0,"isLastPage":true,"values":[]}}`,
+			v:   &BrowsePayload{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{UnknownFields: RejectUnknownFields}
+			err := c.checkFields([]byte(tc.raw), tc.v)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("checkFields(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
+
+// recorder records the messages logged
+type recorder struct {
+	logging.Logger
+	messages *[]string
+}
+
+func (r recorder) Info(msg string, keysAndValues ...interface{}) {
+	*r.messages = append(*r.messages, msg)
+}
+
+func TestUnknownFieldsModes(t *testing.T) {
+	body := `{"size":2,"limit":100,"isLastPage":true,"start":0,"values":[` +
+		`{"id":1,"name":"one","priority":1},{"id":2,"name":"two","priority":2}]}`
+	want := []bitbucket.Webhook{{ID: 1, Name: "one"}, {ID: 2, Name: "two"}}
+	repo := bitbucket.Repo{ProjectKey: "PRJ", Repo: "modes"}
+
+	for _, mode := range []UnknownFields{IgnoreUnknownFields, WarnUnknownFields, RejectUnknownFields} {
+		var messages []string
+		c := &Client{
+			BaseURL:       "http://bitbucket.example.com",
+			HTTPClient:    &http.Client{Transport: responder{status: http.StatusOK, body: []byte(body)}},
+			UnknownFields: mode,
+			Log:           recorder{Logger: logging.NewNopLogger(), messages: &messages},
+		}
+		got, err := c.ListWebhooks(context.Background(), repo, bitbucket.WebhookFilter{})
+
+		switch mode {
+		case RejectUnknownFields:
+			if err == nil || !strings.Contains(err.Error(), "unknown fields of bitbucket.Webhook: priority") {
+				t.Errorf("ListWebhooks(...): want unknown field error, got %v", err)
+			}
+			continue
+		case WarnUnknownFields:
+			// Each field is logged once per type
+			if len(messages) != 1 {
+				t.Errorf("ListWebhooks(...): want 1 warning, got %v", messages)
+			}
+		default:
+			if len(messages) != 0 {
+				t.Errorf("ListWebhooks(...): want no warnings, got %v", messages)
+			}
+		}
+		if err != nil {
+			t.Fatalf("ListWebhooks(...): %v", err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("ListWebhooks(...): -want, +got:\n%s", diff)
+		}
+	}
+}
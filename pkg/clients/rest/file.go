@@ -0,0 +1,159 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+// GetRawFile returns the content of the file at the given commit, branch or
+// tag. The default branch is used when at is empty.
+func (c *Client) GetRawFile(ctx context.Context, repo bitbucket.Repo, filePath string, at string) ([]byte, error) {
+	u := c.BaseURL + fmt.Sprintf("/rest/api/1.0/projects/%s/repos/%s/raw/%s",
+		url.PathEscape(repo.ProjectKey), url.PathEscape(repo.Repo), escapeFilePath(filePath))
+	if at != "" {
+		u += "?" + url.Values{"at": {at}}.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "*/*")
+
+	var content []byte
+	if err := c.sendRequest(req, &content); err != nil {
+		return nil, fmt.Errorf("GetRawFile(%+v, %s): %w", repo, filePath, err)
+	}
+	return content, nil
+}
+
+// BrowseDirectory lists the children of the directory at the given commit,
+// branch or tag. The default branch is used when at is empty.
+func (c *Client) BrowseDirectory(ctx context.Context, repo bitbucket.Repo, dirPath string, at string) ([]bitbucket.File, error) {
+	base := c.BaseURL + fmt.Sprintf("/rest/api/1.0/projects/%s/repos/%s/browse/%s",
+		url.PathEscape(repo.ProjectKey), url.PathEscape(repo.Repo), escapeFilePath(dirPath))
+
+	var ret []bitbucket.File
+	start := 0
+	for {
+		query := url.Values{"start": {strconv.Itoa(start)}}
+		if at != "" {
+			query.Set("at", at)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var payload BrowsePayload
+		if err := c.sendRequest(req, &payload); err != nil {
+			return nil, fmt.Errorf("BrowseDirectory(%+v, %s): %w", repo, dirPath, err)
+		}
+
+		for _, child := range payload.Children.Values {
+			ret = append(ret, bitbucket.File{
+				Path:      path.Join(strings.Trim(dirPath, "/"), child.Path.ToString),
+				Type:      child.Type,
+				Size:      child.Size,
+				ContentID: child.ContentID,
+			})
+		}
+
+		if payload.Children.IsLastPage || len(payload.Children.Values) == 0 {
+			return ret, nil
+		}
+		// Guard against servers or proxies which don't advance the page,
+		// which would loop forever
+		if payload.Children.NextPageStart <= start {
+			return nil, fmt.Errorf("BrowseDirectory(%+v, %s): invalid next page start %d after %d", repo, dirPath, payload.Children.NextPageStart, start)
+		}
+		start = payload.Children.NextPageStart
+	}
+}
+
+// CommitFile creates or updates a single file by committing it to a branch
+func (c *Client) CommitFile(ctx context.Context, repo bitbucket.Repo, file bitbucket.FileCommit) (bitbucket.Commit, error) {
+	u := c.BaseURL + fmt.Sprintf("/rest/api/1.0/projects/%s/repos/%s/browse/%s",
+		url.PathEscape(repo.ProjectKey), url.PathEscape(repo.Repo), escapeFilePath(file.Path))
+
+	fields := map[string]string{}
+	for name, value := range map[string]string{
+		"branch":         file.Branch,
+		"message":        file.Message,
+		"sourceCommitId": file.SourceCommitID,
+		"sourceBranch":   file.SourceBranch,
+	} {
+		if value != "" {
+			fields[name] = value
+		}
+	}
+
+	req, err := NewMultipartRequest(ctx, http.MethodPut, u, fields, MultipartFile{
+		Field:    "content",
+		Filename: path.Base(file.Path),
+		Content:  bytes.NewReader(file.Content),
+	})
+	if err != nil {
+		return bitbucket.Commit{}, err
+	}
+
+	var response bitbucket.Commit
+	if err := c.sendRequest(req, &response); err != nil {
+		return bitbucket.Commit{}, fmt.Errorf("CommitFile(%+v, %s): %w", repo, file.Path, err)
+	}
+	return response, nil
+}
+
+// escapeFilePath escapes every segment of a path in a repository
+func escapeFilePath(p string) string {
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// BrowsePayload is the returned object when browsing a directory
+type BrowsePayload struct {
+	// Children of the directory
+	Children struct {
+		// Pagination is defined by the bitbucket server api
+		Pagination `json:",inline"`
+		// Values is defined by the bitbucket server api
+		Values []BrowseChild `json:"values"`
+	} `json:"children"`
+}
+
+// BrowseChild describes a file or directory in a directory listing
+type BrowseChild struct {
+	Path struct {
+		// ToString is the path relative to the browsed directory
+		ToString string `json:"toString"`
+	} `json:"path"`
+	Type      string `json:"type"`
+	Size      int64  `json:"size"`
+	ContentID string `json:"contentId"`
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+var fileRepo = bitbucket.Repo{ProjectKey: "PRJ", Repo: "my repo"}
+
+func TestGetRawFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if diff := cmp.Diff("/rest/api/1.0/projects/PRJ/repos/my%20repo/raw/dir/a%20file.txt?at=refs%2Fheads%2Fmain", r.URL.RequestURI()); diff != "" {
+			t.Errorf("GetRawFile(...): -want, +got\n%s", diff)
+		}
+		fmt.Fprint(w, "content\n")
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client()}
+	got, err := c.GetRawFile(context.Background(), fileRepo, "/dir/a file.txt", "refs/heads/main")
+	if err != nil {
+		t.Fatalf("GetRawFile(...): %v", err)
+	}
+	if diff := cmp.Diff("content\n", string(got)); diff != "" {
+		t.Errorf("GetRawFile(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestBrowseDirectory(t *testing.T) {
+	pages := map[string]string{
+		"0": `{"children":{"isLastPage":false,"nextPageStart":1,"values":[{"path":{"toString":"a.txt"},"type":"FILE","size":3,"contentId":"abc"}]}}`,
+		"1": `{"children":{"isLastPage":true,"values":[{"path":{"toString":"sub"},"type":"DIRECTORY"}]}}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if diff := cmp.Diff("/rest/api/1.0/projects/PRJ/repos/my%20repo/browse/dir", r.URL.EscapedPath()); diff != "" {
+			t.Errorf("BrowseDirectory(...): -want, +got\n%s", diff)
+		}
+		fmt.Fprint(w, pages[r.URL.Query().Get("start")])
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client()}
+	got, err := c.BrowseDirectory(context.Background(), fileRepo, "dir", "")
+	if err != nil {
+		t.Fatalf("BrowseDirectory(...): %v", err)
+	}
+	want := []bitbucket.File{
+		{Path: "dir/a.txt", Type: "FILE", Size: 3, ContentID: "abc"},
+		{Path: "dir/sub", Type: "DIRECTORY"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("BrowseDirectory(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestCommitFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if diff := cmp.Diff(http.MethodPut, r.Method); diff != "" {
+			t.Errorf("CommitFile(...): -want, +got\n%s", diff)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("ParseMultipartForm(...): %v", err)
+		}
+		want := map[string][]string{"branch": {"main"}, "message": {"update"}, "sourceCommitId": {"abc"}}
+		if diff := cmp.Diff(want, r.MultipartForm.Value); diff != "" {
+			t.Errorf("CommitFile(...): -want, +got\n%s", diff)
+		}
+		f, _ := r.MultipartForm.File["content"][0].Open()
+		content, _ := io.ReadAll(f)
+		if diff := cmp.Diff("new", string(content)); diff != "" {
+			t.Errorf("CommitFile(...): -want, +got\n%s", diff)
+		}
+		fmt.Fprint(w, `{"id":"def0123","displayId":"def","message":"update"}`)
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client()}
+	got, err := c.CommitFile(context.Background(), fileRepo, bitbucket.FileCommit{
+		Path:           "README.md",
+		Content:        []byte("new"),
+		Branch:         "main",
+		Message:        "update",
+		SourceCommitID: "abc",
+	})
+	if err != nil {
+		t.Fatalf("CommitFile(...): %v", err)
+	}
+	if diff := cmp.Diff(bitbucket.Commit{ID: "def0123", DisplayID: "def", Message: "update"}, got); diff != "" {
+		t.Errorf("CommitFile(...): -want, +got\n%s", diff)
+	}
+}
@@ -0,0 +1,107 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+func fuzzClient(status int, body []byte) *Client {
+	return &Client{BaseURL: "http://bitbucket.example.com", HTTPClient: &http.Client{Transport: responder{status: status, body: body}}}
+}
+
+// FuzzErrorResponse checks that the error of a failed request matches
+// bitbucket.ErrNotFound exactly for a 404 status, whatever the body, and
+// that its message stays bounded for bodies which are not bitbucket errors.
+func FuzzErrorResponse(f *testing.F) {
+	for _, seed := range []struct {
+		status int
+		body   string
+	}{
+		{http.StatusNotFound, `{"errors":[{"context":null,"message":"Repository PRJ/repo does not exist.","exceptionName":"com.atlassian.bitbucket.repository.NoSuchRepositoryException"}]}`},
+		{http.StatusNotFound, `{"errors":[{"message":"truncated`},
+		{http.StatusBadGateway, `<html><body><h1>502 Bad Gateway</h1></body></html>`},
+		{http.StatusBadRequest, `{"errors":null}`},
+		{http.StatusConflict, `{"errors":[{"message":"404 Not Found"}]}`},
+		{http.StatusInternalServerError, ""},
+		{http.StatusServiceUnavailable, "\xff\xfe not utf-8"},
+	} {
+		f.Add(seed.status, []byte(seed.body))
+	}
+
+	f.Fuzz(func(t *testing.T, status int, body []byte) {
+		if status < http.StatusBadRequest || status > 599 {
+			t.Skip()
+		}
+		err := fuzzClient(status, body).Ping(context.Background())
+		if err == nil {
+			t.Fatal("Ping(...): want error, got nil")
+		}
+		if got, want := errors.Is(err, bitbucket.ErrNotFound), status == http.StatusNotFound; got != want {
+			t.Errorf("errors.Is(%q, ErrNotFound): want %t, got %t", err, want, got)
+		}
+		var errRes errorResponse
+		if !errors.As(err, &errRes) {
+			t.Fatalf("Ping(...): want errorResponse, got %T", err)
+		}
+		if !strings.HasPrefix(err.Error(), "HTTP status") {
+			t.Errorf("Error(): want status prefix, got %q", err.Error())
+		}
+		if n := utf8.RuneCountInString(errRes.body); n > maxErrorSnippetLength+len("...") {
+			t.Errorf("body snippet: want at most %d runes, got %d", maxErrorSnippetLength+len("..."), n)
+		}
+	})
+}
+
+// FuzzListPayload checks that decoding list payloads of successful requests
+// neither panics nor loops and never reports the list as not found.
+func FuzzListPayload(f *testing.F) {
+	for _, seed := range []string{
+		`{"size":1,"limit":25,"isLastPage":true,"start":0,"values":[{"key":{"id":1,"text":"ssh-rsa AAAA","label":"ci"},"permission":"REPO_READ"}]}`,
+		`{"children":{"isLastPage":false,"start":0,"nextPageStart":1,"values":[{"path":{"toString":"a"},"type":"FILE"}]}}`,
+		`{"children":{"isLastPage":false,"nextPageStart":0,"values":[{}]}}`,
+		`{"values":[{"key":`,
+		`{"values":null}`,
+		`{"errors":[{"message":"not found"}]}`,
+		`<html>`,
+		``,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		c := fuzzClient(http.StatusOK, body)
+		repo := bitbucket.Repo{ProjectKey: "PRJ", Repo: "repo"}
+
+		if _, err := c.ListAccessKeys(context.Background(), repo, bitbucket.AccessKeyFilter{}); errors.Is(err, bitbucket.ErrNotFound) {
+			t.Errorf("ListAccessKeys(...): want no ErrNotFound for status 200, got %v", err)
+		}
+		if _, err := c.BrowseDirectory(context.Background(), repo, "dir", ""); errors.Is(err, bitbucket.ErrNotFound) {
+			t.Errorf("BrowseDirectory(...): want no ErrNotFound for status 200, got %v", err)
+		}
+	})
+}
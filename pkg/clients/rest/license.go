@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+// GetLicense returns the license of the server. It doesn't return the license
+// key.
+func (c *Client) GetLicense(ctx context.Context) (bitbucket.License, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/rest/api/1.0/admin/license", nil)
+	if err != nil {
+		return bitbucket.License{}, err
+	}
+
+	var payload licensePayload
+	if err := c.sendRequest(req, &payload); err != nil {
+		return bitbucket.License{}, fmt.Errorf("GetLicense(): %w", err)
+	}
+	return bitbucket.License{
+		MaximumUsers:          payload.MaximumNumberOfUsers,
+		UnlimitedUsers:        payload.UnlimitedNumberOfUsers,
+		CurrentUsers:          payload.Status.CurrentNumberOfUsers,
+		ExpiryDate:            millisToTime(payload.ExpiryDate),
+		MaintenanceExpiryDate: millisToTime(payload.MaintenanceExpiryDate),
+	}, nil
+}
+
+// licensePayload is the license as returned by the API, with dates in
+// milliseconds since the epoch
+type licensePayload struct {
+	ExpiryDate             int64                `json:"expiryDate,omitempty"`
+	MaintenanceExpiryDate  int64                `json:"maintenanceExpiryDate,omitempty"`
+	MaximumNumberOfUsers   int                  `json:"maximumNumberOfUsers"`
+	UnlimitedNumberOfUsers bool                 `json:"unlimitedNumberOfUsers"`
+	Status                 licenseStatusPayload `json:"status"`
+}
+
+// licenseStatusPayload is the usage of the license
+type licenseStatusPayload struct {
+	CurrentNumberOfUsers int `json:"currentNumberOfUsers"`
+}
+
+// millisToTime returns the time of the milliseconds since the epoch, or the
+// zero time for zero.
+func millisToTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ms*int64(time.Millisecond)).UTC()
+}
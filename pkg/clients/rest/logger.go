@@ -0,0 +1,58 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// GetLoggerLevel returns the level of the logger, e.g. INFO
+func (c *Client) GetLoggerLevel(ctx context.Context, name string) (string, error) {
+	url := c.BaseURL + fmt.Sprintf("/rest/api/1.0/logs/logger/%s", url.PathEscape(name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var payload loggerPayload
+	if err := c.sendRequest(req, &payload); err != nil {
+		return "", fmt.Errorf("GetLoggerLevel(%s): %w", name, err)
+	}
+	return payload.LogLevel, nil
+}
+
+// SetLoggerLevel sets the level of the logger, e.g. DEBUG
+func (c *Client) SetLoggerLevel(ctx context.Context, name string, level string) error {
+	url := c.BaseURL + fmt.Sprintf("/rest/api/1.0/logs/logger/%s/%s", url.PathEscape(name), url.PathEscape(level))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := c.sendRequest(req, nil); err != nil {
+		return fmt.Errorf("SetLoggerLevel(%s, %s): %w", name, level, err)
+	}
+	return nil
+}
+
+// loggerPayload is the level of a logger
+type loggerPayload struct {
+	LogLevel string `json:"logLevel"`
+}
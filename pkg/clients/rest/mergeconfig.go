@@ -0,0 +1,131 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+// GetMergeConfig returns the merge strategies of the pull requests of the
+// repository, or of the project if the slug is empty
+func (c *Client) GetMergeConfig(ctx context.Context, repo bitbucket.Repo) (bitbucket.MergeConfig, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.pullRequestSettingsURL(repo), nil)
+	if err != nil {
+		return bitbucket.MergeConfig{}, err
+	}
+
+	var payload pullRequestSettings
+	if err := c.sendRequest(req, &payload); err != nil {
+		return bitbucket.MergeConfig{}, fmt.Errorf("GetMergeConfig(%+v): %w", repo, err)
+	}
+	return mergeConfig(payload.MergeConfig), nil
+}
+
+// SetMergeConfig sets the merge strategies of the pull requests of the
+// repository, or of the project if the slug is empty
+func (c *Client) SetMergeConfig(ctx context.Context, repo bitbucket.Repo, config bitbucket.MergeConfig) (bitbucket.MergeConfig, error) {
+	payload := pullRequestSettings{MergeConfig: mergeConfigPayload{
+		DefaultStrategy: &mergeStrategyPayload{ID: config.DefaultStrategy},
+	}}
+	for _, s := range config.Strategies {
+		payload.MergeConfig.Strategies = append(payload.MergeConfig.Strategies, mergeStrategyPayload{ID: s})
+	}
+	response, err := c.postPullRequestSettings(ctx, repo, payload)
+	if err != nil {
+		return bitbucket.MergeConfig{}, fmt.Errorf("SetMergeConfig(%+v): %w", repo, err)
+	}
+	return mergeConfig(response.MergeConfig), nil
+}
+
+// DeleteMergeConfig sets an empty merge configuration, which removes the one
+// of the repository, or of the project if the slug is empty, so that it is
+// inherited again
+func (c *Client) DeleteMergeConfig(ctx context.Context, repo bitbucket.Repo) error {
+	if _, err := c.postPullRequestSettings(ctx, repo, pullRequestSettings{}); err != nil {
+		return fmt.Errorf("DeleteMergeConfig(%+v): %w", repo, err)
+	}
+	return nil
+}
+
+func (c *Client) postPullRequestSettings(ctx context.Context, repo bitbucket.Repo, payload pullRequestSettings) (pullRequestSettings, error) {
+	marshalledPayload, err := json.Marshal(payload)
+	if err != nil {
+		return pullRequestSettings{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.pullRequestSettingsURL(repo), bytes.NewBuffer(marshalledPayload))
+	if err != nil {
+		return pullRequestSettings{}, err
+	}
+
+	var response pullRequestSettings
+	err = c.sendRequest(req, &response)
+	return response, err
+}
+
+// pullRequestSettingsURL returns the URL of the pull request settings of the
+// repository, or of the git repositories of the project if the slug is empty
+func (c *Client) pullRequestSettingsURL(repo bitbucket.Repo) string {
+	if repo.Repo == "" {
+		return c.BaseURL + fmt.Sprintf("/rest/api/1.0/projects/%s/settings/pull-requests/git",
+			url.PathEscape(repo.ProjectKey))
+	}
+	return c.BaseURL + fmt.Sprintf("/rest/api/1.0/projects/%s/repos/%s/settings/pull-requests",
+		url.PathEscape(repo.ProjectKey), url.PathEscape(repo.Repo))
+}
+
+// pullRequestSettings are the settings of the pull requests of a project or
+// repository, of which the client only needs the merge configuration
+type pullRequestSettings struct {
+	MergeConfig mergeConfigPayload `json:"mergeConfig"`
+}
+
+// mergeConfigPayload is the merge configuration of pull request settings
+type mergeConfigPayload struct {
+	DefaultStrategy *mergeStrategyPayload  `json:"defaultStrategy,omitempty"`
+	Strategies      []mergeStrategyPayload `json:"strategies,omitempty"`
+	Type            string                 `json:"type,omitempty"`
+}
+
+// mergeStrategyPayload is a merge strategy. Only its ID is sent.
+type mergeStrategyPayload struct {
+	ID string `json:"id"`
+	// Enabled is false for strategies which are listed but disabled
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// mergeConfig returns the merge configuration with the enabled strategies of
+// the payload
+func mergeConfig(p mergeConfigPayload) bitbucket.MergeConfig {
+	ret := bitbucket.MergeConfig{Type: p.Type}
+	for _, s := range p.Strategies {
+		if s.Enabled == nil || *s.Enabled {
+			ret.Strategies = append(ret.Strategies, s.ID)
+		}
+	}
+	if p.DefaultStrategy != nil {
+		ret.DefaultStrategy = p.DefaultStrategy.ID
+	}
+	return ret
+}
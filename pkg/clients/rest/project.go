@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+// ListProjects returns all projects the credentials can see which match the
+// filter
+func (c *Client) ListProjects(ctx context.Context, filter bitbucket.ProjectFilter) ([]bitbucket.Project, error) {
+	query := filterQuery("name", filter.Name, "permission", filter.Permission)
+
+	ret := []bitbucket.Project{}
+	newProject := func() interface{} { return &projectInfo{} }
+	err := c.getPages(ctx, c.BaseURL+"/rest/api/1.0/projects", query, newProject, func(v interface{}) error {
+		p := v.(*projectInfo)
+		ret = append(ret, bitbucket.Project{Key: p.Key, Name: p.Name})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ListProjects(%+v): %w", filter, err)
+	}
+	return ret, nil
+}
+
+// projectInfo is a project as listed by the API
+type projectInfo struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+// GetDefaultPermission returns whether all licensed users have the permission
+// on the project
+func (c *Client) GetDefaultPermission(ctx context.Context, projectKey string, permission string) (bool, error) {
+	url := c.BaseURL + fmt.Sprintf("/rest/api/1.0/projects/%s/permissions/%s/all",
+		url.PathEscape(projectKey), url.PathEscape(permission))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+
+	var payload defaultPermission
+	if err := c.sendRequest(req, &payload); err != nil {
+		return false, fmt.Errorf("GetDefaultPermission(%s, %s): %w", projectKey, permission, err)
+	}
+	return payload.Permitted, nil
+}
+
+// SetDefaultPermission grants or revokes the permission on the project for
+// all licensed users
+func (c *Client) SetDefaultPermission(ctx context.Context, projectKey string, permission string, allow bool) error {
+	url := c.BaseURL + fmt.Sprintf("/rest/api/1.0/projects/%s/permissions/%s/all?allow=%s",
+		url.PathEscape(projectKey), url.PathEscape(permission), strconv.FormatBool(allow))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := c.sendRequest(req, nil); err != nil {
+		return fmt.Errorf("SetDefaultPermission(%s, %s, %t): %w", projectKey, permission, allow, err)
+	}
+	return nil
+}
+
+// defaultPermission tells whether all licensed users have a permission
+type defaultPermission struct {
+	Permitted bool `json:"permitted"`
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+// GetProjectHook returns the repository hook with the key at the scope of
+// the project
+func (c *Client) GetProjectHook(ctx context.Context, projectKey string, hookKey string) (bitbucket.ProjectHook, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.projectHookURL(projectKey, hookKey, ""), nil)
+	if err != nil {
+		return bitbucket.ProjectHook{}, err
+	}
+
+	var payload hookPayload
+	if err := c.sendRequest(req, &payload); err != nil {
+		return bitbucket.ProjectHook{}, fmt.Errorf("GetProjectHook(%s, %s): %w", projectKey, hookKey, err)
+	}
+	return payload.projectHook(), nil
+}
+
+// EnableProjectHook enables the repository hook for the project, with the
+// settings unless they are nil
+func (c *Client) EnableProjectHook(ctx context.Context, projectKey string, hookKey string, settings bitbucket.HookSettings) (bitbucket.ProjectHook, error) {
+	var body io.Reader
+	if settings != nil {
+		marshalledPayload, err := json.Marshal(settings)
+		if err != nil {
+			return bitbucket.ProjectHook{}, err
+		}
+		body = bytes.NewBuffer(marshalledPayload)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.projectHookURL(projectKey, hookKey, "/enabled"), body)
+	if err != nil {
+		return bitbucket.ProjectHook{}, err
+	}
+
+	var payload hookPayload
+	if err := c.sendRequest(req, &payload); err != nil {
+		return bitbucket.ProjectHook{}, fmt.Errorf("EnableProjectHook(%s, %s): %w", projectKey, hookKey, err)
+	}
+	return payload.projectHook(), nil
+}
+
+// DisableProjectHook disables the repository hook for the project. Its
+// settings are kept.
+func (c *Client) DisableProjectHook(ctx context.Context, projectKey string, hookKey string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.projectHookURL(projectKey, hookKey, "/enabled"), nil)
+	if err != nil {
+		return err
+	}
+
+	if err := c.sendRequest(req, nil); err != nil {
+		return fmt.Errorf("DisableProjectHook(%s, %s): %w", projectKey, hookKey, err)
+	}
+	return nil
+}
+
+// GetProjectHookSettings returns the settings of the repository hook for the
+// project, nil if it has none
+func (c *Client) GetProjectHookSettings(ctx context.Context, projectKey string, hookKey string) (bitbucket.HookSettings, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.projectHookURL(projectKey, hookKey, "/settings"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings bitbucket.HookSettings
+	if err := c.sendRequest(req, &settings); err != nil {
+		return nil, fmt.Errorf("GetProjectHookSettings(%s, %s): %w", projectKey, hookKey, err)
+	}
+	return settings, nil
+}
+
+// SetProjectHookSettings replaces the settings of the repository hook for
+// the project
+func (c *Client) SetProjectHookSettings(ctx context.Context, projectKey string, hookKey string, settings bitbucket.HookSettings) error {
+	marshalledPayload, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.projectHookURL(projectKey, hookKey, "/settings"), bytes.NewBuffer(marshalledPayload))
+	if err != nil {
+		return err
+	}
+
+	if err := c.sendRequest(req, nil); err != nil {
+		return fmt.Errorf("SetProjectHookSettings(%s, %s): %w", projectKey, hookKey, err)
+	}
+	return nil
+}
+
+func (c *Client) projectHookURL(projectKey string, hookKey string, suffix string) string {
+	return c.BaseURL + fmt.Sprintf("/rest/api/1.0/projects/%s/settings/hooks/%s%s",
+		url.PathEscape(projectKey), url.PathEscape(hookKey), suffix)
+}
+
+// hookPayload is a repository hook as returned by the API
+type hookPayload struct {
+	Details    hookDetailsPayload `json:"details"`
+	Enabled    bool               `json:"enabled"`
+	Configured bool               `json:"configured"`
+}
+
+// hookDetailsPayload describes the plugin of a repository hook
+type hookDetailsPayload struct {
+	Key     string `json:"key"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Version string `json:"version"`
+}
+
+func (p hookPayload) projectHook() bitbucket.ProjectHook {
+	return bitbucket.ProjectHook{
+		Key:        p.Details.Key,
+		Name:       p.Details.Name,
+		Type:       p.Details.Type,
+		Version:    p.Details.Version,
+		Enabled:    p.Enabled,
+		Configured: p.Configured,
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+// GetRepository returns the repository
+func (c *Client) GetRepository(ctx context.Context, repo bitbucket.Repo) (bitbucket.Repository, error) {
+	url := c.BaseURL + fmt.Sprintf("/rest/api/1.0/projects/%s/repos/%s",
+		url.PathEscape(repo.ProjectKey), url.PathEscape(repo.Repo))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return bitbucket.Repository{}, err
+	}
+
+	var payload RepositoryInfo
+	if err := c.sendRequest(req, &payload); err != nil {
+		return bitbucket.Repository{}, fmt.Errorf("GetRepository(%+v): %w", repo, err)
+	}
+
+	return repository(payload), nil
+}
+
+// ListRepositories returns all repositories of the project
+func (c *Client) ListRepositories(ctx context.Context, projectKey string) ([]bitbucket.Repository, error) {
+	url := c.BaseURL + fmt.Sprintf("/rest/api/1.0/projects/%s/repos", url.PathEscape(projectKey))
+
+	ret := []bitbucket.Repository{}
+	newRepo := func() interface{} { return &RepositoryInfo{} }
+	err := c.getPages(ctx, url, nil, newRepo, func(v interface{}) error {
+		ret = append(ret, repository(*v.(*RepositoryInfo)))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ListRepositories(%s): %w", projectKey, err)
+	}
+	return ret, nil
+}
+
+func repository(payload RepositoryInfo) bitbucket.Repository {
+	clone := make(map[string]string, len(payload.Links.Clone))
+	for _, l := range payload.Links.Clone {
+		clone[l.Name] = l.Href
+	}
+	return bitbucket.Repository{
+		ID:         payload.ID,
+		Slug:       payload.Slug,
+		Name:       payload.Name,
+		ProjectKey: payload.Project.Key,
+		CloneURLs:  clone,
+	}
+}
+
+// CheckRepositoryAdmin lists at most one webhook of the repository, which
+// Bitbucket only allows with the admin permission on the repository
+func (c *Client) CheckRepositoryAdmin(ctx context.Context, repo bitbucket.Repo) error {
+	url := c.BaseURL + fmt.Sprintf("/rest/api/1.0/projects/%s/repos/%s/webhooks?limit=1",
+		url.PathEscape(repo.ProjectKey), url.PathEscape(repo.Repo))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := c.sendRequest(req, nil); err != nil {
+		return fmt.Errorf("CheckRepositoryAdmin(%+v): %w", repo, err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+func TestGetRepository(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if diff := cmp.Diff("/rest/api/1.0/projects/PRJ/repos/my-repo", r.URL.EscapedPath()); diff != "" {
+			t.Errorf("GetRepository(...): -want, +got\n%s", diff)
+		}
+		fmt.Fprint(w, `{"id":7,"slug":"my-repo","name":"My Repo","project":{"key":"PRJ"},"links":{"clone":[`+
+			`{"href":"https://bitbucket.example.com/scm/prj/my-repo.git","name":"http"},`+
+			`{"href":"ssh://git@bitbucket.example.com:7999/prj/my-repo.git","name":"ssh"}]}}`)
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client()}
+	got, err := c.GetRepository(context.Background(), bitbucket.Repo{ProjectKey: "PRJ", Repo: "my-repo"})
+	if err != nil {
+		t.Fatalf("GetRepository(...): %v", err)
+	}
+	want := bitbucket.Repository{
+		ID:         7,
+		Slug:       "my-repo",
+		Name:       "My Repo",
+		ProjectKey: "PRJ",
+		CloneURLs: map[string]string{
+			"http": "https://bitbucket.example.com/scm/prj/my-repo.git",
+			"ssh":  "ssh://git@bitbucket.example.com:7999/prj/my-repo.git",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetRepository(...): -want, +got\n%s", diff)
+	}
+}
+
+func TestCheckRepositoryAdmin(t *testing.T) {
+	cases := map[string]struct {
+		status int
+		want   error
+	}{
+		"Admin": {
+			status: http.StatusOK,
+		},
+		"NoAdmin": {
+			status: http.StatusForbidden,
+			want:   bitbucket.ErrForbidden,
+		},
+		"NotFound": {
+			status: http.StatusNotFound,
+			want:   bitbucket.ErrNotFound,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if diff := cmp.Diff("/rest/api/1.0/projects/PRJ/repos/my-repo/webhooks?limit=1", r.URL.EscapedPath()+"?"+r.URL.RawQuery); diff != "" {
+					t.Errorf("CheckRepositoryAdmin(...): -want, +got\n%s", diff)
+				}
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, `{"size":0,"limit":1,"isLastPage":true,"values":[],"start":0}`)
+			}))
+			defer srv.Close()
+
+			c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client()}
+			err := c.CheckRepositoryAdmin(context.Background(), bitbucket.Repo{ProjectKey: "PRJ", Repo: "my-repo"})
+			if !errors.Is(err, tc.want) {
+				t.Errorf("CheckRepositoryAdmin(...): want error %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
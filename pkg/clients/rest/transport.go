@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const acceptEncoding = "gzip, deflate"
+
+// NewCompressionTransport returns a RoundTripper which asks the server for
+// compressed responses and transparently decompresses gzip and deflate
+// encoded bodies before handing them to the caller.
+func NewCompressionTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &compressionTransport{next: next}
+}
+
+type compressionTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *compressionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var newReader func(io.Reader) (io.ReadCloser, error)
+	switch strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		newReader = func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }
+	case "deflate":
+		newReader = zlib.NewReader
+	default:
+		return res, nil
+	}
+
+	res.Body = &decompressingBody{body: res.Body, newReader: newReader}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+	return res, nil
+}
+
+// decompressingBody defers creating the decompressor until the first read,
+// so empty bodies (e.g. 204 responses) with a Content-Encoding header do not
+// fail on a missing gzip or zlib header.
+type decompressingBody struct {
+	body      io.ReadCloser
+	newReader func(io.Reader) (io.ReadCloser, error)
+	reader    io.ReadCloser
+}
+
+func (b *decompressingBody) Read(p []byte) (int, error) {
+	if b.reader == nil {
+		r, err := b.newReader(b.body)
+		if err != nil {
+			return 0, err
+		}
+		b.reader = r
+	}
+	return b.reader.Read(p)
+}
+
+func (b *decompressingBody) Close() error {
+	if b.reader != nil {
+		b.reader.Close() // nolint:errcheck
+	}
+	return b.body.Close()
+}
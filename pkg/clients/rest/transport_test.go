@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// responder answers every request with the same status and body
+type responder struct {
+	status int
+	body   []byte
+}
+
+func (r responder) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: r.status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader(r.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestCompressionTransport(t *testing.T) {
+	payload := `{"values":[{"id":1}]}`
+
+	gzipped := func() []byte {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write([]byte(payload)) // nolint:errcheck
+		w.Close()                // nolint:errcheck
+		return buf.Bytes()
+	}
+	deflated := func() []byte {
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		w.Write([]byte(payload)) // nolint:errcheck
+		w.Close()                // nolint:errcheck
+		return buf.Bytes()
+	}
+
+	cases := map[string]struct {
+		encoding string
+		body     []byte
+		want     string
+	}{
+		"Gzip": {
+			encoding: "gzip",
+			body:     gzipped(),
+			want:     payload,
+		},
+		"Deflate": {
+			encoding: "deflate",
+			body:     deflated(),
+			want:     payload,
+		},
+		"Identity": {
+			body: []byte(payload),
+			want: payload,
+		},
+		"EmptyGzip": {
+			encoding: "gzip",
+			want:     "",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if diff := cmp.Diff(acceptEncoding, r.Header.Get("Accept-Encoding")); diff != "" {
+					t.Errorf("Accept-Encoding: -want, +got\n%s", diff)
+				}
+				if tc.encoding != "" {
+					w.Header().Set("Content-Encoding", tc.encoding)
+				}
+				w.Write(tc.body) // nolint:errcheck
+			}))
+			defer srv.Close()
+
+			c := &http.Client{Transport: NewCompressionTransport(&http.Transport{DisableCompression: true})}
+			res, err := c.Get(srv.URL)
+			if err != nil {
+				t.Fatalf("Get(...): %v", err)
+			}
+			defer res.Body.Close() // nolint:errcheck
+
+			got, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatalf("ReadAll(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("RoundTrip(...): -want, +got\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+const (
+	contentTypeOctetStream = "application/octet-stream"
+
+	// Bitbucket rejects multipart requests without this header as a
+	// protection against cross site request forgery.
+	headerAtlassianToken = "X-Atlassian-Token"
+)
+
+// MultipartFile is a file part of a multipart/form-data upload
+type MultipartFile struct {
+	// Field is the name of the form field
+	Field string
+	// Filename is sent in the content disposition of the part
+	Filename string
+	// ContentType of the part, defaults to application/octet-stream
+	ContentType string
+	// Content of the file
+	Content io.Reader
+}
+
+// NewMultipartRequest creates a multipart/form-data request with the given
+// plain form fields and files, as used by the endpoint committing a file.
+func NewMultipartRequest(ctx context.Context, method, url string, fields map[string]string, files ...MultipartFile) (*http.Request, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	// Sort the fields to get a stable body
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := w.WriteField(name, fields[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range files {
+		contentType := f.ContentType
+		if contentType == "" {
+			contentType = contentTypeOctetStream
+		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(f.Field), escapeQuotes(f.Filename)))
+		h.Set("Content-Type", contentType)
+		part, err := w.CreatePart(h)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(part, f.Content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set(headerAtlassianToken, "no-check")
+	return req, nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewMultipartRequest(t *testing.T) {
+	req, err := NewMultipartRequest(context.Background(), http.MethodPost, "https://bitbucket.example.com/upload",
+		map[string]string{"name": "script", "type": "PRE"},
+		MultipartFile{Field: "content", Filename: "hook.sh", ContentType: "text/plain", Content: strings.NewReader("#!/bin/sh")})
+	if err != nil {
+		t.Fatalf("NewMultipartRequest(...): %v", err)
+	}
+
+	if diff := cmp.Diff("no-check", req.Header.Get(headerAtlassianToken)); diff != "" {
+		t.Errorf("NewMultipartRequest(...): -want, +got\n%s", diff)
+	}
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatalf("ParseMultipartForm(...): %v", err)
+	}
+	if diff := cmp.Diff(map[string][]string{"name": {"script"}, "type": {"PRE"}}, req.MultipartForm.Value); diff != "" {
+		t.Errorf("NewMultipartRequest(...): -want, +got\n%s", diff)
+	}
+
+	files := req.MultipartForm.File["content"]
+	if len(files) != 1 {
+		t.Fatalf("NewMultipartRequest(...): want one file, got %d", len(files))
+	}
+	if diff := cmp.Diff("hook.sh", files[0].Filename); diff != "" {
+		t.Errorf("NewMultipartRequest(...): -want, +got\n%s", diff)
+	}
+	if diff := cmp.Diff("text/plain", files[0].Header.Get("Content-Type")); diff != "" {
+		t.Errorf("NewMultipartRequest(...): -want, +got\n%s", diff)
+	}
+	f, err := files[0].Open()
+	if err != nil {
+		t.Fatalf("Open(): %v", err)
+	}
+	content, _ := io.ReadAll(f)
+	if diff := cmp.Diff("#!/bin/sh", string(content)); diff != "" {
+		t.Errorf("NewMultipartRequest(...): -want, +got\n%s", diff)
+	}
+}
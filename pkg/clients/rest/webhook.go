@@ -24,7 +24,7 @@ import (
 	"net/http"
 	"net/url"
 
-	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
 )
 
 // GetWebhook gets the web hook
@@ -45,6 +45,42 @@ func (c *Client) GetWebhook(ctx context.Context, repo bitbucket.Repo, id int) (b
 	return payload, nil
 }
 
+// ListWebhooks returns all webhooks of the repository which match the filter
+func (c *Client) ListWebhooks(ctx context.Context, repo bitbucket.Repo, filter bitbucket.WebhookFilter) ([]bitbucket.Webhook, error) {
+	url := c.BaseURL + fmt.Sprintf("/rest/api/1.0/projects/%s/repos/%s/webhooks",
+		url.PathEscape(repo.ProjectKey), url.PathEscape(repo.Repo))
+	query := filterQuery("event", filter.Event)
+
+	ret := []bitbucket.Webhook{}
+	newHook := func() interface{} { return &bitbucket.Webhook{} }
+	err := c.getPages(ctx, url, query, newHook, func(v interface{}) error {
+		ret = append(ret, *v.(*bitbucket.Webhook))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ListWebhooks(%+v, %+v): %w", repo, filter, err)
+	}
+	return ret, nil
+}
+
+// GetWebhookStatistics gets the counts of the recent deliveries of the web
+// hook
+func (c *Client) GetWebhookStatistics(ctx context.Context, repo bitbucket.Repo, id int) (bitbucket.WebhookStatistics, error) {
+	url := c.BaseURL + fmt.Sprintf("/rest/api/1.0/projects/%s/repos/%s/webhooks/%d/statistics",
+		url.PathEscape(repo.ProjectKey), url.PathEscape(repo.Repo), id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return bitbucket.WebhookStatistics{}, err
+	}
+
+	var payload webhookStatisticsPayload
+	if err := c.sendRequest(req, &payload); err != nil {
+		return bitbucket.WebhookStatistics{}, fmt.Errorf("GetWebhookStatistics(%+v, %d): %w", repo, id, err)
+	}
+
+	return payload.Counts, nil
+}
+
 // CreateWebhook creates the web hook
 func (c *Client) CreateWebhook(ctx context.Context, repo bitbucket.Repo, hook bitbucket.Webhook) (bitbucket.Webhook, error) {
 	marshalledPayload, err := json.Marshal(hook)
@@ -100,3 +136,9 @@ func (c *Client) DeleteWebhook(ctx context.Context, repo bitbucket.Repo, id int)
 
 	return c.sendRequest(req, nil)
 }
+
+// webhookStatisticsPayload are the statistics of the recent deliveries of a
+// webhook
+type webhookStatisticsPayload struct {
+	Counts bitbucket.WebhookStatistics `json:"counts"`
+}
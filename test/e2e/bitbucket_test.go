@@ -0,0 +1,237 @@
+//go:build e2e
+// +build e2e
+
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+const (
+	defaultImage    = "atlassian/bitbucket:7.21"
+	defaultUsername = "admin"
+	defaultPassword = "admin"
+
+	// startupTimeout bounds the first start of Bitbucket, which sets up
+	// its database and takes several minutes
+	startupTimeout = 10 * time.Minute
+)
+
+var errNotConfigured = errors.New("neither BITBUCKET_E2E_URL nor BITBUCKET_E2E_LICENSE is set")
+
+// server is the Bitbucket server the tests run against
+type server struct {
+	url      string
+	username string
+	password string
+
+	// container started for the tests, empty for an existing server
+	container string
+	// repos created for the tests
+	repos []bitbucket.Repo
+}
+
+func getenv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// startBitbucket returns the server given by BITBUCKET_E2E_URL or else starts
+// a Bitbucket container with the license in BITBUCKET_E2E_LICENSE, and waits
+// until it is running.
+func startBitbucket() (*server, error) {
+	srv := &server{
+		url:      strings.TrimSuffix(os.Getenv("BITBUCKET_E2E_URL"), "/"),
+		username: getenv("BITBUCKET_E2E_USERNAME", defaultUsername),
+		password: getenv("BITBUCKET_E2E_PASSWORD", defaultPassword),
+	}
+	if srv.url == "" {
+		license := os.Getenv("BITBUCKET_E2E_LICENSE")
+		if license == "" {
+			return nil, errNotConfigured
+		}
+		if err := srv.run(getenv("BITBUCKET_E2E_IMAGE", defaultImage), license); err != nil {
+			return nil, err
+		}
+	}
+	if err := srv.waitRunning(); err != nil {
+		srv.stop()
+		return nil, err
+	}
+	return srv, nil
+}
+
+// run starts a Bitbucket container set up unattended with an admin user.
+func (s *server) run(image, license string) error {
+	port, err := freePort()
+	if err != nil {
+		return err
+	}
+	s.url = fmt.Sprintf("http://127.0.0.1:%d", port)
+	out, err := exec.Command("docker", "run", "--detach", "--rm",
+		"--publish", fmt.Sprintf("127.0.0.1:%d:7990", port),
+		"--env", "SETUP_DISPLAYNAME=e2e",
+		"--env", "SETUP_BASEURL="+s.url,
+		"--env", "SETUP_LICENSE="+license,
+		"--env", "SETUP_SYSADMIN_USERNAME="+s.username,
+		"--env", "SETUP_SYSADMIN_PASSWORD="+s.password,
+		"--env", "SETUP_SYSADMIN_DISPLAYNAME=Admin",
+		"--env", "SETUP_SYSADMIN_EMAILADDRESS=admin@example.com",
+		image).Output()
+	if err != nil {
+		return errors.Wrap(err, "cannot run container")
+	}
+	s.container = strings.TrimSpace(string(out))
+	return nil
+}
+
+// stop removes the container, if any.
+func (s *server) stop() {
+	if s.container == "" {
+		return
+	}
+	if err := exec.Command("docker", "stop", s.container).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot stop container", s.container, err)
+	}
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close() // nolint:errcheck
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// waitRunning waits until the server reports that it is running.
+func (s *server) waitRunning() error {
+	deadline := time.Now().Add(startupTimeout)
+	for {
+		var status struct {
+			State string `json:"state"`
+		}
+		err := s.do(http.MethodGet, "/status", nil, &status)
+		if err == nil && status.State == "RUNNING" {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("server not running after %s, state %q, last error: %v", startupTimeout, status.State, err)
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// ensureRepo creates the project and a repository in it, unless they exist
+// already, e.g. from an earlier run against the same server. The repositories
+// created are deleted by deleteRepos.
+func (s *server) ensureRepo(projectKey, name string) error {
+	err := s.do(http.MethodPost, "/rest/api/1.0/projects", map[string]string{"key": projectKey, "name": projectKey}, nil)
+	if err != nil && !isConflict(err) {
+		return errors.Wrap(err, "cannot create project")
+	}
+	err = s.do(http.MethodPost, "/rest/api/1.0/projects/"+projectKey+"/repos", map[string]string{"name": name, "scmId": "git"}, nil)
+	if err != nil && !isConflict(err) {
+		return errors.Wrap(err, "cannot create repository")
+	}
+	if err == nil {
+		s.repos = append(s.repos, bitbucket.Repo{ProjectKey: projectKey, Repo: bitbucket.RepoSlug(name)})
+	}
+	return nil
+}
+
+// deleteRepos deletes the repositories created by ensureRepo and their
+// projects, which must be empty to be deleted.
+func (s *server) deleteRepos() {
+	for _, r := range s.repos {
+		p := "/rest/api/1.0/projects/" + r.ProjectKey + "/repos/" + r.Repo
+		if err := s.do(http.MethodDelete, p, nil, nil); err != nil {
+			fmt.Fprintln(os.Stderr, "Cannot delete", p, err)
+		}
+	}
+	for _, r := range s.repos {
+		p := "/rest/api/1.0/projects/" + r.ProjectKey
+		if err := s.do(http.MethodDelete, p, nil, nil); err != nil && !isStatus(err, http.StatusNotFound) && !isConflict(err) {
+			fmt.Fprintln(os.Stderr, "Cannot delete", p, err)
+		}
+	}
+}
+
+// statusError is the unexpected status of a response
+type statusError int
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("unexpected status %d", int(e))
+}
+
+func isStatus(err error, status int) bool {
+	var s statusError
+	return errors.As(err, &s) && int(s) == status
+}
+
+func isConflict(err error) bool {
+	return isStatus(err, http.StatusConflict)
+}
+
+// do sends a request as the admin user and decodes the response into out
+// unless it is nil.
+func (s *server) do(method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, s.url+path, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.username, s.password)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	if resp.StatusCode >= http.StatusBadRequest {
+		return statusError(resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,176 @@
+//go:build e2e
+// +build e2e
+
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// Annotations of example manifests which control how they are tested
+const (
+	annotationPrefix = "e2e.bitbucket-server.crossplane.io/"
+	// annotationSkip skips the example, its value is the reason
+	annotationSkip = annotationPrefix + "skip"
+	// annotationTimeout is how long the example may take to become ready,
+	// e.g. 5m. Defaults to timeout.
+	annotationTimeout = annotationPrefix + "timeout"
+	// annotationUpdate is a JSON merge patch of spec.forProvider, which is
+	// applied once the example is ready, e.g. {"publicKey":{"permission":"REPO_READ"}}
+	annotationUpdate = annotationPrefix + "update-parameter"
+)
+
+// examplesDir contains the examples, one directory per API group
+var examplesDir = filepath.Join("..", "..", "examples")
+
+// TestExamples applies every managed resource in the examples, waits until
+// it is ready and synced, updates it if it has an update annotation and
+// deletes it. The project, repository and ProviderConfig an example refers
+// to are created first, so the examples work unchanged.
+func TestExamples(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join(examplesDir, "*", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		objs, err := readManifests(f)
+		if err != nil {
+			t.Fatalf("cannot read %s: %v", f, err)
+		}
+		for _, u := range objs {
+			mg, ok := managed(t, u)
+			if !ok {
+				continue
+			}
+			rel, _ := filepath.Rel(examplesDir, f)
+			t.Run(fmt.Sprintf("%s/%s/%s", rel, u.GetKind(), u.GetName()), func(t *testing.T) {
+				testExample(t, u, mg)
+			})
+		}
+	}
+}
+
+func testExample(t *testing.T, u *unstructured.Unstructured, mg resource.Managed) {
+	ctx := context.Background()
+	if reason, ok := u.GetAnnotations()[annotationSkip]; ok {
+		t.Skip(reason)
+	}
+	wait := timeout
+	if v, ok := u.GetAnnotations()[annotationTimeout]; ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			t.Fatalf("invalid %s annotation: %v", annotationTimeout, err)
+		}
+		wait = d
+	}
+
+	projectKey, _, _ := unstructured.NestedString(u.Object, "spec", "forProvider", "projectKey")
+	repoName, _, _ := unstructured.NestedString(u.Object, "spec", "forProvider", "repoName")
+	if projectKey != "" && repoName != "" {
+		if err := srv.ensureRepo(projectKey, repoName); err != nil {
+			t.Fatalf("cannot create repository %s/%s: %v", projectKey, repoName, err)
+		}
+	}
+	if ref := mg.GetProviderConfigReference(); ref != nil {
+		if err := ensureProviderConfig(ctx, ref.Name); err != nil {
+			t.Fatalf("cannot create ProviderConfig %s: %v", ref.Name, err)
+		}
+	}
+
+	if err := kube.Create(ctx, mg); err != nil {
+		t.Fatalf("cannot create example: %v", err)
+	}
+	defer func() {
+		if err := kube.Delete(ctx, mg); resource.IgnoreNotFound(err) != nil {
+			t.Fatalf("cannot delete example: %v", err)
+		}
+		waitFor(t, "waiting for "+mg.GetName()+" to be deleted", func(ctx context.Context) (bool, error) {
+			err := kube.Get(ctx, types.NamespacedName{Name: mg.GetName()}, mg)
+			return kerrors.IsNotFound(err), err
+		})
+	}()
+	waitReadyWithin(t, mg, wait)
+
+	if patch, ok := u.GetAnnotations()[annotationUpdate]; ok {
+		p := client.RawPatch(types.MergePatchType, []byte(fmt.Sprintf(`{"spec":{"forProvider":%s}}`, patch)))
+		if err := kube.Patch(ctx, mg, p); err != nil {
+			t.Fatalf("cannot update example: %v", err)
+		}
+		waitReadyWithin(t, mg, wait)
+	}
+}
+
+// managed returns the typed managed resource of the object, or false if the
+// object is no managed resource, e.g. a ProviderConfig.
+func managed(t *testing.T, u *unstructured.Unstructured) (resource.Managed, bool) {
+	t.Helper()
+	obj, err := kube.Scheme().New(u.GroupVersionKind())
+	if err != nil {
+		return nil, false
+	}
+	mg, ok := obj.(resource.Managed)
+	if !ok {
+		return nil, false
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, mg); err != nil {
+		t.Fatalf("cannot convert %s %s: %v", u.GetKind(), u.GetName(), err)
+	}
+	return mg, true
+}
+
+// readManifests reads all objects of a multi document YAML file.
+func readManifests(path string) ([]*unstructured.Unstructured, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint:errcheck
+
+	var ret []*unstructured.Unstructured
+	d := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		u := &unstructured.Unstructured{}
+		err := d.Decode(&u.Object)
+		if errors.Is(err, io.EOF) {
+			return ret, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(u.Object) > 0 {
+			ret = append(ret, u)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+//go:build e2e
+// +build e2e
+
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package e2e runs the controllers of the provider against a real Bitbucket
+// Server and a Kubernetes API server started by envtest.
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	kuberest "k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+
+	"github.com/crossplane-contrib/provider-bitbucket-server/apis"
+	apisv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/clock"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/setup"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/throttle"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/features"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/generate"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/rest"
+)
+
+const (
+	// namespace of the credentials and connection secrets
+	namespace = "crossplane-system"
+	// providerConfig is the name of the ProviderConfig of all resources
+	providerConfig = "e2e"
+
+	// timeout of a resource reaching the expected state
+	timeout = 2 * time.Minute
+)
+
+var (
+	// kube is a client of the envtest API server
+	kube client.Client
+	// srv is the Bitbucket server
+	srv *server
+	// bb is a client of the Bitbucket server with the credentials of the
+	// provider, to check the external resources
+	bb *rest.Client
+	// repo all resources are created in
+	repo = bitbucket.Repo{ProjectKey: "E2E", Repo: "e2e-repo"}
+	// repoName is the name of repo, whose slug differs from it
+	repoName = "E2E Repo"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	var err error
+	srv, err = startBitbucket()
+	if errors.Is(err, errNotConfigured) {
+		fmt.Fprintln(os.Stderr, "Skipping e2e tests:", err)
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot start Bitbucket:", err)
+		return 1
+	}
+	defer srv.stop()
+
+	bb = clients.NewClient(clients.Config{
+		BaseURL: srv.url,
+		Auth:    rest.BasicAuth{Username: srv.username, Password: srv.password},
+	})
+	if err := srv.ensureRepo(repo.ProjectKey, repoName); err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot create repository:", err)
+		return 1
+	}
+	defer srv.deleteRepos()
+
+	env := &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "package", "crds")},
+		ErrorIfCRDPathMissing: true,
+	}
+	cfg, err := env.Start()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot start envtest:", err)
+		return 1
+	}
+	defer env.Stop() // nolint:errcheck
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := startProvider(ctx, cfg); err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot start provider:", err)
+		return 1
+	}
+
+	return m.Run()
+}
+
+// startProvider starts the controllers of the provider and creates the
+// ProviderConfig of the Bitbucket server.
+func startProvider(ctx context.Context, cfg *kuberest.Config) error {
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		return err
+	}
+	if err := apis.AddToScheme(s); err != nil {
+		return err
+	}
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: s, MetricsBindAddress: "0"})
+	if err != nil {
+		return err
+	}
+
+	log := logging.NewNopLogger()
+	if os.Getenv("BITBUCKET_E2E_DEBUG") != "" {
+		zl := zap.New(zap.UseDevMode(true))
+		log = logging.NewLogrLogger(zl.WithName("provider-bitbucket-server"))
+	}
+	o := setup.Options{
+		Logger:                  log,
+		GlobalRateLimiter:       ratelimiter.NewDefaultProviderRateLimiter(100),
+		MaxConcurrentReconciles: 1,
+		PollInterval:            5 * time.Second,
+		Timeout:                 time.Minute,
+		WarnUnknownFields:       true,
+		Throttle:                throttle.NewGate(clock.System),
+		Passwords:               generate.RandomPasswords,
+		Keys:                    generate.ED25519Keys,
+		Features:                &features.Flags{},
+	}
+	if err := controller.Setup(mgr, o); err != nil {
+		return err
+	}
+	go mgr.Start(ctx) // nolint:errcheck
+
+	kube, err = client.New(cfg, client.Options{Scheme: s})
+	if err != nil {
+		return err
+	}
+	for _, o := range []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: providerConfig},
+			StringData: map[string]string{"password": srv.password},
+		},
+	} {
+		if err := kube.Create(ctx, o); err != nil {
+			return err
+		}
+	}
+	return ensureProviderConfig(ctx, providerConfig)
+}
+
+// ensureProviderConfig creates a ProviderConfig of the Bitbucket server with
+// the name unless it exists.
+func ensureProviderConfig(ctx context.Context, name string) error {
+	pc := &apisv1alpha1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: apisv1alpha1.ProviderConfigSpec{
+			BaseURL: srv.url,
+			Credentials: apisv1alpha1.ProviderCredentials{
+				Source: xpv1.CredentialsSourceSecret,
+				CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+					SecretRef: &xpv1.SecretKeySelector{
+						SecretReference: xpv1.SecretReference{Namespace: namespace, Name: providerConfig},
+						Key:             "password",
+					},
+				},
+			},
+			Authentication: &apisv1alpha1.Authentication{Scheme: apisv1alpha1.AuthSchemeBasic, Username: srv.username},
+		},
+	}
+	if err := kube.Create(ctx, pc); err != nil && !kerrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
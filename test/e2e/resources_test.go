@@ -0,0 +1,227 @@
+//go:build e2e
+// +build e2e
+
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	accesskeyv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/accesskey/v1alpha1"
+	webhookv1alpha1 "github.com/crossplane-contrib/provider-bitbucket-server/apis/webhook/v1alpha1"
+	"github.com/crossplane-contrib/provider-bitbucket-server/internal/controller/externalname"
+	"github.com/crossplane-contrib/provider-bitbucket-server/pkg/clients/bitbucket"
+)
+
+// waitFor polls until done returns true or the timeout expires.
+func waitFor(t *testing.T, what string, done func(ctx context.Context) (bool, error)) {
+	t.Helper()
+	waitForWithin(t, timeout, what, done)
+}
+
+// waitForWithin polls until done returns true or the duration expires.
+func waitForWithin(t *testing.T, d time.Duration, what string, done func(ctx context.Context) (bool, error)) {
+	t.Helper()
+	ctx := context.Background()
+	var last error
+	err := wait.PollImmediate(time.Second, d, func() (bool, error) {
+		ok, err := done(ctx)
+		last = err
+		return ok, nil
+	})
+	if err != nil {
+		t.Fatalf("%s: %v, last error: %v", what, err, last)
+	}
+}
+
+// waitReady waits until the managed resource is ready and synced.
+func waitReady(t *testing.T, mg resource.Managed) {
+	t.Helper()
+	waitReadyWithin(t, mg, timeout)
+}
+
+// waitReadyWithin waits until the managed resource is ready and synced or
+// the duration expires.
+func waitReadyWithin(t *testing.T, mg resource.Managed, d time.Duration) {
+	t.Helper()
+	waitForWithin(t, d, "waiting for "+mg.GetName()+" to become ready", func(ctx context.Context) (bool, error) {
+		if err := kube.Get(ctx, types.NamespacedName{Name: mg.GetName()}, mg); err != nil {
+			return false, err
+		}
+		synced := mg.GetCondition(xpv1.TypeSynced)
+		if synced.Status != corev1.ConditionTrue {
+			return false, errors.Errorf("not synced: %s", synced.Message)
+		}
+		return mg.GetCondition(xpv1.TypeReady).Status == corev1.ConditionTrue, nil
+	})
+}
+
+// deleteAndWait deletes the managed resource and waits until it is gone
+// from Kubernetes and its external resource from Bitbucket.
+func deleteAndWait(t *testing.T, mg resource.Managed, get func(ctx context.Context) error) {
+	t.Helper()
+	ctx := context.Background()
+	if err := kube.Delete(ctx, mg); err != nil {
+		t.Fatalf("cannot delete %s: %v", mg.GetName(), err)
+	}
+	waitFor(t, "waiting for "+mg.GetName()+" to be deleted", func(ctx context.Context) (bool, error) {
+		if err := kube.Get(ctx, types.NamespacedName{Name: mg.GetName()}, mg); !kerrors.IsNotFound(err) {
+			return false, err
+		}
+		err := get(ctx)
+		return errors.Is(err, bitbucket.ErrNotFound), err
+	})
+}
+
+// update applies mutate to the latest version of the managed resource,
+// which the controllers update concurrently.
+func update(t *testing.T, mg resource.Managed, mutate func()) {
+	t.Helper()
+	ctx := context.Background()
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := kube.Get(ctx, types.NamespacedName{Name: mg.GetName()}, mg); err != nil {
+			return err
+		}
+		mutate()
+		return kube.Update(ctx, mg)
+	})
+	if err != nil {
+		t.Fatalf("cannot update %s: %v", mg.GetName(), err)
+	}
+}
+
+func connectionSecret(ctx context.Context, name string) (*corev1.Secret, error) {
+	s := &corev1.Secret{}
+	err := kube.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, s)
+	return s, err
+}
+
+func TestWebhook(t *testing.T) {
+	ctx := context.Background()
+	cr := &webhookv1alpha1.Webhook{}
+	cr.SetName("e2e-webhook")
+	cr.SetProviderConfigReference(&xpv1.Reference{Name: providerConfig})
+	cr.SetWriteConnectionSecretToReference(&xpv1.SecretReference{Namespace: namespace, Name: "e2e-webhook"})
+	cr.Spec.ForProvider = webhookv1alpha1.WebhookParameters{
+		ProjectKey: repo.ProjectKey,
+		RepoName:   repoName,
+		Webhook: webhookv1alpha1.BitbucketWebhook{
+			// the defaulting admission webhook doesn't run in envtest
+			Name:   "e2e-webhook",
+			URL:    "https://ci.example.com/e2e",
+			Events: []webhookv1alpha1.Event{webhookv1alpha1.EventRepoRefsChanged},
+		},
+	}
+	if err := kube.Create(ctx, cr); err != nil {
+		t.Fatalf("cannot create Webhook: %v", err)
+	}
+
+	waitReady(t, cr)
+	id, err := externalname.ID(cr)
+	if err != nil {
+		t.Fatalf("invalid external name: %v", err)
+	}
+	hook, err := bb.GetWebhook(ctx, repo, id)
+	if err != nil {
+		t.Fatalf("cannot get webhook %d: %v", id, err)
+	}
+	if hook.Name != cr.GetName() || hook.URL != cr.Spec.ForProvider.Webhook.URL {
+		t.Errorf("webhook %d: want name %q and URL %q, got %q and %q", id, cr.GetName(), cr.Spec.ForProvider.Webhook.URL, hook.Name, hook.URL)
+	}
+	s, err := connectionSecret(ctx, "e2e-webhook")
+	if err != nil {
+		t.Fatalf("cannot get connection secret: %v", err)
+	}
+	if len(s.Data[webhookv1alpha1.ConnectionSecretKey]) == 0 {
+		t.Errorf("connection secret has no %s", webhookv1alpha1.ConnectionSecretKey)
+	}
+
+	update(t, cr, func() { cr.Spec.ForProvider.Webhook.URL = "https://ci.example.com/e2e-updated" })
+	waitFor(t, "waiting for the webhook URL to be updated", func(ctx context.Context) (bool, error) {
+		hook, err := bb.GetWebhook(ctx, repo, id)
+		return hook.URL == "https://ci.example.com/e2e-updated", err
+	})
+
+	deleteAndWait(t, cr, func(ctx context.Context) error {
+		_, err := bb.GetWebhook(ctx, repo, id)
+		return err
+	})
+}
+
+func TestAccessKey(t *testing.T) {
+	ctx := context.Background()
+	cr := &accesskeyv1alpha1.AccessKey{}
+	cr.SetName("e2e-accesskey")
+	cr.SetProviderConfigReference(&xpv1.Reference{Name: providerConfig})
+	cr.SetWriteConnectionSecretToReference(&xpv1.SecretReference{Namespace: namespace, Name: "e2e-accesskey"})
+	cr.Spec.ForProvider = accesskeyv1alpha1.AccessKeyParameters{
+		ProjectKey: repo.ProjectKey,
+		RepoName:   repoName,
+		PublicKey: accesskeyv1alpha1.PublicKey{
+			Label:      "e2e",
+			Permission: bitbucket.PermissionRepoRead,
+		},
+	}
+	if err := kube.Create(ctx, cr); err != nil {
+		t.Fatalf("cannot create AccessKey: %v", err)
+	}
+
+	waitReady(t, cr)
+	id, ok, err := externalname.RepoID(meta.GetExternalName(cr), repo.ProjectKey, repo.Repo)
+	if !ok || err != nil {
+		t.Fatalf("invalid external name %q: %v", meta.GetExternalName(cr), err)
+	}
+	key, err := bb.GetAccessKey(ctx, repo, id)
+	if err != nil {
+		t.Fatalf("cannot get access key %d: %v", id, err)
+	}
+	if key.Permission != bitbucket.PermissionRepoRead {
+		t.Errorf("access key %d: want permission %s, got %s", id, bitbucket.PermissionRepoRead, key.Permission)
+	}
+	s, err := connectionSecret(ctx, "e2e-accesskey")
+	if err != nil {
+		t.Fatalf("cannot get connection secret: %v", err)
+	}
+	if len(s.Data[accesskeyv1alpha1.ConnectionPrivateKeyKey]) == 0 {
+		t.Errorf("connection secret has no %s", accesskeyv1alpha1.ConnectionPrivateKeyKey)
+	}
+
+	update(t, cr, func() { cr.Spec.ForProvider.PublicKey.Permission = bitbucket.PermissionRepoWrite })
+	waitFor(t, "waiting for the access key permission to be updated", func(ctx context.Context) (bool, error) {
+		key, err := bb.GetAccessKey(ctx, repo, id)
+		return key.Permission == bitbucket.PermissionRepoWrite, err
+	})
+
+	deleteAndWait(t, cr, func(ctx context.Context) error {
+		_, err := bb.GetAccessKey(ctx, repo, id)
+		return err
+	})
+}